@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Accessibility checks of one rendered deck: alt text, contrast and text size
+
+const (
+	minContrast      = 4.5 // WCAG AA for normal text
+	minContrastLarge = 3.0 // WCAG AA for large text
+	largeTextPx      = 24  // text at least this tall counts as large
+	minTextPx        = 12  // smaller text is reported as tiny
+)
+
+type a11yIssue struct {
+	Kind    string `json:"kind"` // "alt-text", "contrast" or "tiny-text"
+	Slide   int    `json:"slide"`
+	Message string `json:"message"`
+}
+
+// deckAccessibility checks one rendered deck. Contrast is measured against
+// the slide background only; text over shapes is not analysed.
+func deckAccessibility(xmlPath string) ([]a11yIssue, error) {
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var issues []a11yIssue
+	canvasWidth := 792.0 // deck's default canvas
+	slide := 0
+	bg, fg := "white", "black"
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return issues, fmt.Errorf("parse %s: %w", filepath.Base(xmlPath), err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attrs := make(map[string]string, len(start.Attr))
+		for _, attr := range start.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+		switch start.Name.Local {
+		case "canvas":
+			if w, err := strconv.ParseFloat(attrs["width"], 64); err == nil && w > 0 {
+				canvasWidth = w
+			}
+		case "slide":
+			slide++
+			bg, fg = orDefault(attrs["bg"], "white"), orDefault(attrs["fg"], "black")
+		case "image":
+			if strings.TrimSpace(attrs["caption"]) == "" {
+				issues = append(issues, a11yIssue{"alt-text", slide, fmt.Sprintf("image %s has no caption to use as alt text", attrs["name"])})
+			}
+		case "text", "list":
+			sp, err := strconv.ParseFloat(attrs["sp"], 64)
+			if err != nil || sp <= 0 {
+				continue // zero sizes are a property check failure, not an accessibility one
+			}
+			px := sp * canvasWidth / 100
+			if px < minTextPx {
+				issues = append(issues, a11yIssue{"tiny-text", slide, fmt.Sprintf("%s %s is %.0fpx tall (minimum %dpx)", start.Name.Local, quoteSnippet(dec), px, minTextPx)})
+			}
+			color := orDefault(attrs["color"], fg)
+			opacity := 100.0
+			if o, err := strconv.ParseFloat(attrs["opacity"], 64); err == nil {
+				opacity = o
+			}
+			ratio, ok := contrastRatio(color, bg, opacity)
+			threshold := minContrast
+			if px >= largeTextPx {
+				threshold = minContrastLarge
+			}
+			if ok && ratio < threshold {
+				if opacity < 100 {
+					color += fmt.Sprintf(" at %g%% opacity", opacity)
+				}
+				issues = append(issues, a11yIssue{"contrast", slide, fmt.Sprintf("%s on %s has contrast %.1f:1 (minimum %.1f:1)", color, bg, ratio, threshold)})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// quoteSnippet returns the start of an element's text for messages; it
+// consumes the element's character data, which nothing else needs.
+func quoteSnippet(dec *xml.Decoder) string {
+	tok, err := dec.Token()
+	if err != nil {
+		return ""
+	}
+	data, ok := tok.(xml.CharData)
+	text := strings.Join(strings.Fields(string(data)), " ")
+	if !ok || text == "" {
+		return ""
+	}
+	if len(text) > 30 {
+		text = text[:30] + "..."
+	}
+	return strconv.Quote(text)
+}
+
+func orDefault(v, def string) string {
+	if strings.TrimSpace(v) == "" {
+		return def
+	}
+	return v
+}
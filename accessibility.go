@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// Accessibility report for the published gallery: images without alt text,
+// text with too little contrast against the slide background, and text too
+// small to read, computed from each example's rendered deck XML (checks in
+// a11ychecks.go, color contrast in a11ycolor.go)
+
+const accessibilityID = "accessibility"
+
+type a11yExample struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Issues []a11yIssue `json:"issues"`
+}
+
+type a11yReport struct {
+	Examples []a11yExample  `json:"examples"` // only examples with issues
+	Checked  int            `json:"checked"`
+	Counts   map[string]int `json:"counts"`
+}
+
+var accessibilityPage = template.Must(template.New(accessibilityID).Parse(`<!doctype html>
+<html lang="en"><head><meta charset="utf-8"><title>Accessibility report - deck gallery</title>
+<style>
+body { font-family: sans-serif; margin: 1.5em; max-width: 60em; }
+td, th { text-align: left; padding: 0.2em 0.6em; vertical-align: top; }
+</style></head><body>
+<p><a href="index.html">&larr; gallery</a></p>
+<h1>Accessibility report</h1>
+<p>{{.Checked}} examples checked: {{index .Counts "alt-text"}} image(s) without alt text,
+{{index .Counts "contrast"}} low-contrast text(s), {{index .Counts "tiny-text"}} tiny text(s).</p>
+{{range .Examples}}<h2><a href="{{.ID}}.html">{{.Name}}</a></h2>
+<table><tr><th>Slide</th><th>Issue</th><th>Details</th></tr>
+{{range .Issues}}<tr><td>{{.Slide}}</td><td>{{.Kind}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{else}}<p>No issues found.</p>{{end}}
+</body></html>
+`))
+
+// writeAccessibilityReport checks the rendered deck of every gallery entry
+// and writes accessibility.json and accessibility.html into dir.
+func (cfg *config) writeAccessibilityReport(dir string, entries []catalogEntry) (*a11yReport, error) {
+	report := &a11yReport{Examples: []a11yExample{}, Counts: map[string]int{"alt-text": 0, "contrast": 0, "tiny-text": 0}}
+	for _, e := range entries {
+		source, name := cfg.parseExample(e.Name)
+		exampleDir, err := cfg.getExampleDir(source, name)
+		if err != nil {
+			return nil, err
+		}
+		issues, err := deckAccessibility(cfg.getExampleXmlPath(exampleDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // render failed
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name, err)
+		}
+		report.Checked++
+		for _, issue := range issues {
+			report.Counts[issue.Kind]++
+		}
+		if len(issues) > 0 {
+			report.Examples = append(report.Examples, a11yExample{ID: e.ID, Name: e.Name, Issues: issues})
+		}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, accessibilityID+".json"), append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, accessibilityID+".html"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return report, accessibilityPage.Execute(f, report)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Example aliases and favorites: short names for decks used often
+// (alias add demo dubois/plate07, then run demo) and a list of favorite
+// examples that completion offers first. Both are per user, kept in
+// ~/.decktool/aliases.json (or DECKTOOL_ALIASES).
+
+type exampleShortcuts struct {
+	Aliases   map[string]string `json:"aliases"`   // alias -> source/name
+	Favorites []string          `json:"favorites"` // source/name, in the order added
+}
+
+func aliasesPath() (string, error) {
+	if path := os.Getenv("DECKTOOL_ALIASES"); path != "" {
+		return path, nil
+	}
+	return decktoolHomePath("aliases.json")
+}
+
+func loadShortcuts() (exampleShortcuts, error) {
+	shortcuts := exampleShortcuts{Aliases: make(map[string]string)}
+	path, err := aliasesPath()
+	if err != nil {
+		return shortcuts, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return shortcuts, nil
+	}
+	if err != nil {
+		return shortcuts, err
+	}
+	if err := json.Unmarshal(data, &shortcuts); err != nil {
+		return shortcuts, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if shortcuts.Aliases == nil {
+		shortcuts.Aliases = make(map[string]string)
+	}
+	return shortcuts, nil
+}
+
+func (s exampleShortcuts) save() error {
+	path, err := aliasesPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// aliasNames returns the aliases sorted.
+func (s exampleShortcuts) aliasNames() []string {
+	var names []string
+	for name := range s.Aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// knownExample checks that an example exists before an alias or favorite
+// points at it.
+func (cfg *config) knownExample(example string) error {
+	source, name := cfg.parseExample(example)
+	if name == "" {
+		return fmt.Errorf("%q does not name an example (source/name)", example)
+	}
+	dir, err := cfg.getExampleDir(source, name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	groups, err := cfg.examplesBySource()
+	if err != nil {
+		return err
+	}
+	if repo, ok := cfg.repos[source]; ok && !repo.cloned() {
+		return nil // checked once the repo is fetched on first use
+	}
+	if slices.Contains(groups[source], name) {
+		return nil // in an on-demand repo's tree, not checked out yet
+	}
+	return fmt.Errorf("no example %s/%s (decktool examples lists them)", source, name)
+}
+
+// addAlias points name at an example.
+func (cfg *config) addAlias(name, example string) error {
+	if !corpusNameRE.MatchString(name) {
+		return fmt.Errorf("alias %q must be lower case letters, digits, '.', '_' or '-'", name)
+	}
+	if _, ok := cfg.shortcuts.Aliases[example]; ok {
+		return fmt.Errorf("%q is itself an alias; point %s at an example", example, name)
+	}
+	if err := cfg.knownExample(example); err != nil {
+		return err
+	}
+	if slices.Contains(collectExampleNames(cfg.repos["deckviz"].dir), name) {
+		fmt.Printf("⚠ %s is also a deckviz example; the alias takes precedence (deckviz/%s still reaches it)\n", name, name)
+	}
+	cfg.shortcuts.Aliases[name] = cfg.normalizeExampleName(example)
+	return cfg.shortcuts.save()
+}
+
+func (cfg *config) removeAlias(name string) error {
+	if _, ok := cfg.shortcuts.Aliases[name]; !ok {
+		return fmt.Errorf("no alias %q", name)
+	}
+	delete(cfg.shortcuts.Aliases, name)
+	return cfg.shortcuts.save()
+}
+
+// setFavorite adds (or with on false, removes) an example from the
+// favorites; aliases are resolved so the list holds examples.
+func (cfg *config) setFavorite(example string, on bool) error {
+	if on {
+		if err := cfg.knownExample(example); err != nil {
+			return err
+		}
+	}
+	example = cfg.normalizeExampleName(example)
+	i := slices.Index(cfg.shortcuts.Favorites, example)
+	switch {
+	case on && i >= 0:
+		return nil
+	case on:
+		cfg.shortcuts.Favorites = append(cfg.shortcuts.Favorites, example)
+	case i < 0:
+		return fmt.Errorf("%s is not a favorite", example)
+	default:
+		cfg.shortcuts.Favorites = slices.Delete(cfg.shortcuts.Favorites, i, i+1)
+	}
+	return cfg.shortcuts.save()
+}
+
+// resolveAlias expands an alias to its example; anything else is returned
+// unchanged.
+func (cfg *config) resolveAlias(raw string) string {
+	if strings.Contains(raw, "/") {
+		return raw
+	}
+	if target, ok := cfg.shortcuts.Aliases[raw]; ok {
+		return target
+	}
+	return raw
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"sync"
+)
+
+// Baseline approval: a local web page stepping through visual diffs
+
+var approvePage = template.Must(template.New("approve").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>decktool approve</title>
+<style>
+body { font-family: sans-serif; margin: 1.5em; }
+.row { display: flex; gap: 1em; }
+figure { margin: 0; flex: 1; }
+img { width: 100%; border: 1px solid #ccc; }
+button { font-size: 1.1em; padding: .4em 1.2em; margin-right: .5em; }
+</style></head><body>
+{{if .Diff}}
+<h2>{{.Diff.Deck}} slide {{.Diff.Slide}} <small>({{.Index}} of {{.Total}},
+{{if lt .Diff.Pixels 0}}size changed{{else}}{{.Diff.Pixels}} pixels differ{{end}})</small></h2>
+<form method="post" action="/decide">
+<button name="action" value="accept" accesskey="a">Accept new baseline (a)</button>
+<button name="action" value="reject" accesskey="r">Keep old baseline (r)</button>
+</form>
+<div class="row">
+<figure><figcaption>Before (golden)</figcaption><img src="/img/golden"></figure>
+<figure><figcaption>After</figcaption><img src="/img/actual"></figure>
+<figure><figcaption>Diff</figcaption><img src="/img/diff"></figure>
+</div>
+{{else}}
+<h2>All differences reviewed</h2><p>You can close this page.</p>
+{{end}}
+</body></html>`))
+
+// approveDiffs serves the review page until every pending diff is decided.
+func (cfg *config) approveDiffs(ctx context.Context) error {
+	pending, err := cfg.loadPendingDiffs()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Println("No visual differences pending review")
+		return nil
+	}
+
+	var mu sync.Mutex
+	total, accepted := len(pending), 0
+	done := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		data := map[string]any{"Index": total - len(pending) + 1, "Total": total}
+		if len(pending) > 0 {
+			data["Diff"] = pending[0]
+		}
+		approvePage.Execute(w, data)
+	})
+	mux.HandleFunc("/img/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(pending) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		// Only the files of the current diff are ever served
+		paths := map[string]string{"golden": pending[0].Golden, "actual": pending[0].Actual, "diff": pending[0].Diff}
+		path, ok := paths[r.URL.Path[len("/img/"):]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		http.ServeFile(w, r, path)
+	})
+	mux.HandleFunc("/decide", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(pending) > 0 {
+			d := pending[0]
+			if r.FormValue("action") == "accept" {
+				if err := copyFile(d.Actual, d.Golden); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				accepted++
+				fmt.Printf("✓ Accepted %s slide %s\n", d.Deck, d.Slide)
+			} else {
+				fmt.Printf("✗ Rejected %s slide %s\n", d.Deck, d.Slide)
+			}
+			pending = pending[1:]
+			if err := cfg.savePendingDiffs(pending); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if len(pending) == 0 {
+				approvePage.Execute(w, map[string]any{})
+				close(done)
+				return
+			}
+		}
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+
+	url := "http://" + listener.Addr().String() + "/"
+	fmt.Printf("Reviewing %d visual difference(s) at %s (Ctrl-C to stop)\n", total, url)
+	openBrowser(url)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	shutdownErr := server.Shutdown(context.Background())
+	fmt.Printf("%d of %d accepted, %d left for review\n", accepted, total, len(pending))
+	if shutdownErr != nil && !errors.Is(shutdownErr, http.ErrServerClosed) {
+		return shutdownErr
+	}
+	return nil
+}
+
+// openBrowser opens url with the platform's default handler, best effort.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
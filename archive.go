@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Run snapshots: an immutable, content-addressed record of what was tested
+//
+// Files are stored once under .archive/objects/<sha256> and a snapshot is a
+// manifest naming them, itself stored as snapshots/<id>.json where id is the
+// hash of the manifest. Both are written read-only and never rewritten.
+
+type snapshot struct {
+	Created   time.Time         `json:"created"`
+	Label     string            `json:"label,omitempty"`
+	Toolchain map[string]string `json:"toolchain"` // binary name -> sha256
+	Repos     map[string]string `json:"repos"`     // repo name -> HEAD SHA
+	Files     map[string]string `json:"files"`     // path relative to the project -> sha256
+}
+
+// snapshotInputs lists the files a snapshot records: the lockfile, the
+// test report, feature deck outputs and the rendered corpus outputs.
+func (cfg *config) snapshotInputs() ([]string, error) {
+	var files []string
+	for _, path := range []string{lockFileName, cfg.getMetricsPath(), cfg.getPendingDiffsPath(), cfg.getBuildSummaryPath()} {
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	err := filepath.WalkDir(cfg.getFeatureDir(), func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	examples, err := cfg.listExamples()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range examples {
+		dir, err := cfg.getExampleDir(cfg.parseExample(name))
+		if err != nil {
+			return nil, err
+		}
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if !entry.IsDir() && isRenderOutput(entry.Name()) {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// createSnapshot archives the current run and returns the snapshot id.
+func (cfg *config) createSnapshot(ctx context.Context, label string) (string, *snapshot, error) {
+	snap := &snapshot{
+		Created:   time.Now().UTC(),
+		Label:     label,
+		Toolchain: make(map[string]string),
+		Repos:     make(map[string]string),
+		Files:     make(map[string]string),
+	}
+	for _, spec := range cfg.toolchain {
+		path, err := cfg.resolveBinary(spec.name)
+		if err != nil {
+			continue
+		}
+		if snap.Toolchain[spec.name], err = fileSHA256(path); err != nil {
+			return "", nil, err
+		}
+	}
+	for name, repo := range cfg.repos {
+		if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+			continue // not cloned
+		}
+		if sha, err := cfg.gitHead(ctx, repo); err == nil {
+			snap.Repos[name] = sha
+		}
+	}
+	files, err := cfg.snapshotInputs()
+	if err != nil {
+		return "", nil, err
+	}
+	for _, path := range files {
+		sum, err := cfg.storeObject(path)
+		if err != nil {
+			return "", nil, err
+		}
+		snap.Files[relToCwd(path)] = sum
+	}
+	if len(snap.Files) == 0 {
+		return "", nil, errors.New("nothing to archive; run decktool test first")
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(data)
+	id := hex.EncodeToString(sum[:])[:16]
+	if err := writeImmutable(filepath.Join(cfg.getSnapshotDir(), id+".json"), data); err != nil {
+		return "", nil, err
+	}
+	return id, snap, nil
+}
+
+// storeObject copies path into the object store, keyed by its hash.
+func (cfg *config) storeObject(path string) (string, error) {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	dest := cfg.getArchiveObjectPath(sum)
+	if _, err := os.Stat(dest); err == nil {
+		return sum, nil // already archived
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return sum, writeImmutable(dest, data)
+}
+
+// writeImmutable writes a read-only file via a temp file, so a reader never
+// sees a partial object.
+func writeImmutable(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o444); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
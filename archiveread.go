@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Reading the snapshot archive: listing, resolving and diffing snapshots
+
+// listSnapshots returns snapshot ids, oldest first.
+func (cfg *config) listSnapshots() ([]string, map[string]*snapshot, error) {
+	entries, err := os.ReadDir(cfg.getSnapshotDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var ids []string
+	snaps := make(map[string]*snapshot)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		snap, err := cfg.loadSnapshot(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		snaps[id] = snap
+	}
+	sort.Slice(ids, func(i, j int) bool { return snaps[ids[i]].Created.Before(snaps[ids[j]].Created) })
+	return ids, snaps, nil
+}
+
+func (cfg *config) loadSnapshot(id string) (*snapshot, error) {
+	path := filepath.Join(cfg.getSnapshotDir(), id+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); !strings.HasPrefix(hex.EncodeToString(sum[:]), id) {
+		return nil, fmt.Errorf("snapshot %s has been modified", id)
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// resolveSnapshot accepts a unique id prefix.
+func (cfg *config) resolveSnapshot(prefix string) (string, *snapshot, error) {
+	ids, snaps, err := cfg.listSnapshots()
+	if err != nil {
+		return "", nil, err
+	}
+	var matches []string
+	for _, id := range ids {
+		if strings.HasPrefix(id, prefix) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil, fmt.Errorf("no snapshot %q (see decktool archive list)", prefix)
+	case 1:
+		return matches[0], snaps[matches[0]], nil
+	default:
+		return "", nil, fmt.Errorf("snapshot %q is ambiguous: %s", prefix, strings.Join(matches, ", "))
+	}
+}
+
+// diffSnapshots prints what differs between two snapshots and returns the
+// number of differences.
+func diffSnapshots(w io.Writer, a, b *snapshot) int {
+	changes := 0
+	diffMaps := func(kind string, old, new map[string]string, short func(string) string) {
+		keys := make(map[string]bool)
+		for k := range old {
+			keys[k] = true
+		}
+		for k := range new {
+			keys[k] = true
+		}
+		var sorted []string
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			o, inOld := old[k]
+			n, inNew := new[k]
+			switch {
+			case !inOld:
+				fmt.Fprintf(w, "+ %s %s (%s)\n", kind, k, short(n))
+			case !inNew:
+				fmt.Fprintf(w, "- %s %s (%s)\n", kind, k, short(o))
+			case o != n:
+				fmt.Fprintf(w, "≠ %s %s %s → %s\n", kind, k, short(o), short(n))
+			default:
+				continue
+			}
+			changes++
+		}
+	}
+	digest := func(s string) string { return s[:min(12, len(s))] }
+	diffMaps("tool", a.Toolchain, b.Toolchain, digest)
+	diffMaps("repo", a.Repos, b.Repos, shortSHA)
+	diffMaps("file", a.Files, b.Files, digest)
+	return changes
+}
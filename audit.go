@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+)
+
+// Vulnerability audit of the toolchain
+//
+// govulncheck scans every toolchain package (and decktool) in the srcDir
+// workspace, as checked out for dev build. Findings are grouped per OSV
+// entry with the binaries whose code calls the vulnerable symbol; the
+// report (vulnReportFile) is uploaded with releases. Parsing is in
+// auditparse.go, printing in auditreport.go and auditnotes.go.
+
+const vulnReportFile = "vulnerabilities.json"
+
+type vulnReport struct {
+	Generated time.Time            `json:"generated"`
+	Scanner   string               `json:"scanner,omitempty"`
+	Sources   []resourceDescriptor `json:"sources"`
+	Findings  []vulnFinding        `json:"findings"`
+}
+
+// vulnFinding is one OSV entry. Called findings are reachable from the
+// listed binaries; the others are only imported or required.
+type vulnFinding struct {
+	ID           string   `json:"id"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Summary      string   `json:"summary"`
+	Module       string   `json:"module"`
+	Version      string   `json:"version"`
+	FixedVersion string   `json:"fixed_version,omitempty"`
+	Called       bool     `json:"called"`
+	Binaries     []string `json:"binaries,omitempty"`
+}
+
+// ensureGovulncheck returns govulncheck from PATH or GOBIN, installing it
+// into GOBIN when missing.
+func (cfg *config) ensureGovulncheck(ctx context.Context) (string, error) {
+	if path, err := exec.LookPath("govulncheck"); err == nil {
+		return path, nil
+	}
+	path := cfg.getGoBinPath("govulncheck")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	fmt.Println("govulncheck not found, installing via go install...")
+	install := exec.CommandContext(ctx, cfg.goCmd, "install", "golang.org/x/vuln/cmd/govulncheck@latest")
+	cfg.useEnv(install, envGo, "GOBIN="+cfg.goBinDir)
+	install.Stdout = os.Stdout
+	install.Stderr = os.Stderr
+	if err := install.Run(); err != nil {
+		return "", fmt.Errorf("failed to install govulncheck: %w", err)
+	}
+	return path, nil
+}
+
+// auditToolchain runs govulncheck over the toolchain and decktool and
+// writes the report to the attestations directory.
+func (cfg *config) auditToolchain(ctx context.Context) (*vulnReport, string, error) {
+	if _, err := os.Stat(filepath.Join(srcDir, "go.work")); err != nil {
+		return nil, "", fmt.Errorf("no workspace in %s (run dev build first)", srcDir)
+	}
+	govulncheck, err := cfg.ensureGovulncheck(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Packages to scan and the binary each is built into
+	binaries := make(map[string]string)
+	var pkgs []string
+	for _, spec := range cfg.checkedOutSpecs() {
+		binaries[spec.pkg] = spec.name
+		pkgs = append(pkgs, spec.pkg)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		binaries[info.Main.Path] = "decktool"
+		pkgs = append(pkgs, info.Main.Path)
+	}
+	if len(pkgs) == 0 {
+		return nil, "", errors.New("nothing to audit (run dev build first)")
+	}
+
+	fmt.Printf("Scanning %d packages with govulncheck...\n", len(pkgs))
+	cmd := exec.CommandContext(ctx, govulncheck, append([]string{"-json"}, pkgs...)...)
+	cmd.Dir = srcDir // resolve packages through the workspace, as dev build does
+	cfg.useEnv(cmd, envGoWork)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, "", err
+	}
+	report, parseErr := parseGovulncheck(out, binaries)
+	if err := cmd.Wait(); err != nil {
+		return nil, "", fmt.Errorf("govulncheck: %w", err)
+	}
+	if parseErr != nil {
+		return nil, "", fmt.Errorf("parse govulncheck output: %w", parseErr)
+	}
+
+	report.Generated = time.Now().UTC()
+	if report.Sources, err = cfg.sourceDependencies(ctx); err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(filepath.Dir(cfg.getProvenancePath()), vulnReportFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, "", err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	return report, path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Release notes section of the vulnerability report
+
+// vulnNotesSection renders the release notes summary of the report.
+func vulnNotesSection(report *vulnReport) string {
+	var b strings.Builder
+	b.WriteString("\n\n## Vulnerabilities\n\n")
+	affected := report.affected()
+	if len(affected) == 0 {
+		fmt.Fprintf(&b, "govulncheck found no called vulnerabilities. See %s.\n", vulnReportFile)
+		return b.String()
+	}
+	for _, f := range affected {
+		fmt.Fprintf(&b, "- %s in %s@%s affects %s\n", f.ID, f.Module, f.Version, strings.Join(f.Binaries, ", "))
+	}
+	fmt.Fprintf(&b, "\nFull report: %s\n", vulnReportFile)
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Folding govulncheck -json output into a vulnReport
+
+// govulncheck -json messages (only the fields used here)
+type govulnMessage struct {
+	Config *struct {
+		ScannerVersion string `json:"scanner_version"`
+	} `json:"config"`
+	OSV *struct {
+		ID      string   `json:"id"`
+		Aliases []string `json:"aliases"`
+		Summary string   `json:"summary"`
+		Details string   `json:"details"`
+	} `json:"osv"`
+	Finding *struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module   string `json:"module"`
+			Version  string `json:"version"`
+			Package  string `json:"package"`
+			Function string `json:"function"`
+		} `json:"trace"`
+	} `json:"finding"`
+}
+
+// parseGovulncheck folds the -json message stream into one finding per
+// OSV entry; binaries maps scanned packages to binary names.
+func parseGovulncheck(r io.Reader, binaries map[string]string) (*vulnReport, error) {
+	report := &vulnReport{}
+	byID := make(map[string]*vulnFinding)
+	var order []string
+	finding := func(id string) *vulnFinding {
+		if f, ok := byID[id]; ok {
+			return f
+		}
+		byID[id] = &vulnFinding{ID: id}
+		order = append(order, id)
+		return byID[id]
+	}
+	dec := json.NewDecoder(r)
+	for {
+		var msg govulnMessage
+		if err := dec.Decode(&msg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch {
+		case msg.Config != nil:
+			report.Scanner = msg.Config.ScannerVersion
+		case msg.OSV != nil:
+			f := finding(msg.OSV.ID)
+			f.Aliases, f.Summary = msg.OSV.Aliases, msg.OSV.Summary
+			if f.Summary == "" {
+				f.Summary, _, _ = strings.Cut(msg.OSV.Details, "\n")
+			}
+		case msg.Finding != nil && len(msg.Finding.Trace) > 0:
+			f := finding(msg.Finding.OSV)
+			vuln := msg.Finding.Trace[0]
+			f.Module, f.Version = vuln.Module, vuln.Version
+			if msg.Finding.FixedVersion != "" {
+				f.FixedVersion = msg.Finding.FixedVersion
+			}
+			if vuln.Function == "" {
+				continue // imported or required, not called
+			}
+			f.Called = true
+			// The last frame is the entry point in a scanned package
+			entry := msg.Finding.Trace[len(msg.Finding.Trace)-1]
+			if bin, ok := binaries[entry.Package]; ok && !slices.Contains(f.Binaries, bin) {
+				f.Binaries = append(f.Binaries, bin)
+				sort.Strings(f.Binaries)
+			}
+		}
+	}
+	for _, id := range order {
+		// OSV entries are streamed for every module in the graph; keep
+		// only those with findings
+		if f := byID[id]; f.Module != "" {
+			report.Findings = append(report.Findings, *f)
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Called && !report.Findings[j].Called
+	})
+	return report, nil
+}
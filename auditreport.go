@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Printing the vulnerability report (release notes section in auditnotes.go)
+
+func (r *vulnReport) affected() []vulnFinding {
+	var called []vulnFinding
+	for _, f := range r.Findings {
+		if f.Called {
+			called = append(called, f)
+		}
+	}
+	return called
+}
+
+// printVulnReport summarizes the report per affected binary.
+func printVulnReport(report *vulnReport) {
+	affected := report.affected()
+	if len(affected) == 0 {
+		fmt.Printf("✓ No called vulnerabilities (%d imported or required only)\n", len(report.Findings))
+		return
+	}
+	perBinary := make(map[string][]string)
+	for _, f := range affected {
+		fix := "no fix yet"
+		if f.FixedVersion != "" {
+			fix = "fixed in " + f.FixedVersion
+		}
+		fmt.Printf("✗ %s %s@%s (%s): %s\n", f.ID, f.Module, f.Version, fix, f.Summary)
+		for _, bin := range f.Binaries {
+			perBinary[bin] = append(perBinary[bin], f.ID)
+		}
+	}
+	var names []string
+	for name := range perBinary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("\nAffected binaries:")
+	for _, name := range names {
+		fmt.Printf("  %-12s %s\n", name, strings.Join(perBinary[name], ", "))
+	}
+	if others := len(report.Findings) - len(affected); others > 0 {
+		fmt.Printf("%d more imported or required only (not called)\n", others)
+	}
+}
@@ -8,27 +8,23 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
-)
 
-func (cfg *config) resolveBinary(name string) (string, error) {
-	// Check dist/ directory first (our downloaded binaries)
-	distPath := cfg.getBinaryPath(name)
-	if _, err := os.Stat(distPath); err == nil {
-		return distPath, nil
-	}
+	"github.com/joeblew999/deck-test/pkg/toolchain"
+)
 
-	// Fallback to PATH
-	if path, err := exec.LookPath(name); err == nil {
-		return path, nil
-	}
+// tools is where this workspace's deck tools are looked up; see
+// pkg/toolchain for the search order.
+func (cfg *config) tools() toolchain.Toolchain {
+	return toolchain.Toolchain{DistDir: cfg.distDir, GoBinDir: cfg.goBinDir}
+}
 
-	// Finally check goBinDir
-	path := cfg.getGoBinPath(name)
-	if _, err := os.Stat(path); err == nil {
-		return path, nil
+func (cfg *config) resolveBinary(name string) (string, error) {
+	path, err := cfg.tools().Resolve(name)
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("%s not found in %s, PATH, or %s", name, cfg.distDir, cfg.goBinDir)
+	recordToolUse()
+	return path, nil
 }
 
 func (cfg *config) ensureBins(ctx context.Context) error {
@@ -37,21 +33,22 @@ func (cfg *config) ensureBins(ctx context.Context) error {
 }
 
 func (cfg *config) ensureGhCli(ctx context.Context) error {
+	stdout, stderr := toolOutput(ctx)
 	// Check if gh CLI is already installed
 	if _, err := exec.LookPath("gh"); err == nil {
 		return nil // Already installed
 	}
 
 	// Install gh CLI via go install
-	fmt.Println("gh CLI not found, installing via go install...")
+	fmt.Fprintln(stdout, "gh CLI not found, installing via go install...")
 	installCmd := exec.CommandContext(ctx, cfg.goCmd, "install", "github.com/cli/cli/v2/cmd/gh@latest")
-	installCmd.Env = append(os.Environ(), "GOBIN="+cfg.goBinDir)
-	installCmd.Stdout = os.Stdout
-	installCmd.Stderr = os.Stderr
+	cfg.useEnv(installCmd, envGo, "GOBIN="+cfg.goBinDir)
+	installCmd.Stdout = stdout
+	installCmd.Stderr = stderr
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install gh CLI: %w", err)
 	}
-	fmt.Println("✓ gh CLI installed successfully")
+	fmt.Fprintln(stdout, "✓ gh CLI installed successfully")
 
 	// Update PATH to include GOBIN
 	os.Setenv("PATH", cfg.goBinDir+string(os.PathListSeparator)+os.Getenv("PATH"))
@@ -60,31 +57,25 @@ func (cfg *config) ensureGhCli(ctx context.Context) error {
 }
 
 func (cfg *config) downloadReleaseBinaries(ctx context.Context) error {
+	stdout, _ := toolOutput(ctx)
 	// Ensure gh CLI is installed
 	if err := cfg.ensureGhCli(ctx); err != nil {
 		return err
 	}
 
-	// Get latest release info
-	fmt.Println("Checking for latest release...")
-	listCmd := exec.CommandContext(ctx, "gh", "release", "list", "--limit", "1")
-	output, err := listCmd.Output()
-	if err != nil {
-		return fmt.Errorf("failed to list releases: %w", err)
+	// A rollback holds the restored toolchain until released
+	if state := cfg.loadToolchainState(); state.Held && !cfg.forceDownload {
+		fmt.Fprintf(stdout, "⊘ Toolchain held at %s by rollback (decktool rollback --release to resume updates)\n", state.Current)
+		return nil
 	}
 
-	// Parse release tag from output (format: "TITLE\tTYPE\tTAG\tDATE" - tab separated)
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 0 {
-		return fmt.Errorf("no releases found")
-	}
-	// Split by tab to get fields
-	fields := strings.Split(lines[0], "\t")
-	if len(fields) < 3 {
-		return fmt.Errorf("failed to parse release info")
+	// Get latest release info (of the channel followed, if any)
+	fmt.Fprintln(stdout, "Checking for latest release...")
+	releaseTag, err := cfg.selectRelease(ctx)
+	if err != nil {
+		return err
 	}
-	releaseTag := strings.TrimSpace(fields[2]) // TAG is the 3rd field
-	fmt.Printf("Latest release: %s\n", releaseTag)
+	fmt.Fprintf(stdout, "Latest release: %s\n", releaseTag)
 
 	// Get release published/created time (use createdAt since publishedAt may be null for drafts)
 	viewCmd := exec.CommandContext(ctx, "gh", "release", "view", releaseTag, "--json", "createdAt", "-q", ".createdAt")
@@ -95,7 +86,7 @@ func (cfg *config) downloadReleaseBinaries(ctx context.Context) error {
 	timeStr := strings.TrimSpace(string(timeOutput))
 	if timeStr == "" || timeStr == "null" {
 		// If no timestamp available, skip timestamp check and download everything
-		fmt.Println("No release timestamp available, downloading all binaries...")
+		fmt.Fprintln(stdout, "No release timestamp available, downloading all binaries...")
 	}
 
 	var releaseTime time.Time
@@ -111,6 +102,13 @@ func (cfg *config) downloadReleaseBinaries(ctx context.Context) error {
 		return fmt.Errorf("create dist dir: %w", err)
 	}
 
+	// Fetch provenance so every download can be checked against its digest
+	provenance, err := cfg.releaseProvenance(ctx, releaseTag)
+	if err != nil {
+		return err
+	}
+	var unverified []string
+
 	// Download native binaries for current platform
 	downloaded := 0
 	skipped := 0
@@ -120,45 +118,73 @@ func (cfg *config) downloadReleaseBinaries(ctx context.Context) error {
 
 		// Check if local binary exists and compare timestamps (if available)
 		fileInfo, err := os.Stat(destPath)
-		if err == nil && !releaseTime.IsZero() {
+		if err == nil && cfg.forceDownload {
+			fmt.Fprintf(stdout, "⟳ %s is replaced from %s\n", filename, releaseTag)
+		} else if err == nil && !releaseTime.IsZero() {
 			// File exists and we have a release time - check if local is newer
 			localModTime := fileInfo.ModTime()
 			if localModTime.After(releaseTime) {
-				fmt.Printf("✓ %s is up to date (local is newer)\n", filename)
+				fmt.Fprintf(stdout, "✓ %s is up to date (local is newer)\n", filename)
 				skipped++
 				continue
 			}
-			fmt.Printf("⟳ %s needs update (release is newer)\n", filename)
+			fmt.Fprintf(stdout, "⟳ %s needs update (release is newer)\n", filename)
 		} else if err == nil {
 			// File exists but no release time - skip if file exists
-			fmt.Printf("✓ %s already exists (no timestamp to compare)\n", filename)
+			fmt.Fprintf(stdout, "✓ %s already exists (no timestamp to compare)\n", filename)
 			skipped++
 			continue
 		}
 
-		fmt.Printf("Downloading %s...\n", filename)
-		downloadCmd := exec.CommandContext(ctx, "gh", "release", "download", releaseTag, "-p", filename, "-D", cfg.distDir, "--clobber")
-		downloadCmd.Stdout = os.Stdout
-		downloadCmd.Stderr = os.Stderr
-		if err := downloadCmd.Run(); err != nil {
-			fmt.Printf("⚠ Failed to download %s: %v\n", filename, err)
+		verifyErr, err := cfg.installReleaseAsset(ctx, releaseTag, filename, provenance)
+		if verifyErr != nil {
+			fmt.Fprintf(stdout, "✗ %v\n", verifyErr)
+			unverified = append(unverified, filename)
 			continue
 		}
-
-		// Make executable
-		if err := os.Chmod(destPath, 0755); err != nil {
-			fmt.Printf("⚠ Failed to chmod %s: %v\n", filename, err)
+		if err != nil {
+			fmt.Fprintf(stdout, "⚠ Failed to download %s: %v\n", filename, err)
+			continue
 		}
 
 		downloaded++
-		fmt.Printf("✓ Downloaded %s\n", filename)
+		fmt.Fprintf(stdout, "✓ Downloaded %s\n", filename)
 	}
 
 	if downloaded == 0 && skipped > 0 {
-		fmt.Println("All binaries are up to date")
+		fmt.Fprintln(stdout, "All binaries are up to date")
 	} else if downloaded > 0 {
-		fmt.Printf("✓ Downloaded %d binaries (%d up to date)\n", downloaded, skipped)
+		fmt.Fprintf(stdout, "✓ Downloaded %d binaries (%d up to date)\n", downloaded, skipped)
+	}
+	if len(unverified) > 0 {
+		return fmt.Errorf("provenance verification failed for %s", strings.Join(unverified, ", "))
+	}
+	if state := cfg.loadToolchainState(); downloaded > 0 || state.Current != releaseTag {
+		if err := cfg.recordToolchainSet(releaseTag); err != nil {
+			fmt.Fprintf(stdout, "⚠ Toolchain %s not kept for rollback: %v\n", releaseTag, err)
+		}
 	}
 
 	return nil
 }
+
+// latestReleaseTag returns the tag of the most recent GitHub release.
+func latestReleaseTag(ctx context.Context) (string, error) {
+	listCmd := exec.CommandContext(ctx, "gh", "release", "list", "--limit", "1")
+	output, err := listCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	// Parse release tag from output (format: "TITLE\tTYPE\tTAG\tDATE" - tab separated)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) == 0 {
+		return "", fmt.Errorf("no releases found")
+	}
+	// Split by tab to get fields
+	fields := strings.Split(lines[0], "\t")
+	if len(fields) < 3 {
+		return "", fmt.Errorf("failed to parse release info")
+	}
+	return strings.TrimSpace(fields[2]), nil // TAG is the 3rd field
+}
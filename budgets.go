@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Artifact size budget enforcement
+
+// budgetConfig limits artifact sizes, e.g.
+//
+//	"budgets": {"enforce": "fail", "total": "400MB", "artifacts": {"*-wasm.wasm": "25MB"}}
+type budgetConfig struct {
+	Enforce   string            `json:"enforce"`   // "warn" (default) or "fail"
+	Total     string            `json:"total"`     // budget for all artifacts combined
+	Artifacts map[string]string `json:"artifacts"` // artifact filename glob -> budget
+}
+
+func (b budgetConfig) validate() error {
+	switch b.Enforce {
+	case "", "warn", "fail":
+	default:
+		return fmt.Errorf("budgets.enforce must be \"warn\" or \"fail\", got %q", b.Enforce)
+	}
+	if b.Total != "" {
+		if _, err := parseSize(b.Total); err != nil {
+			return fmt.Errorf("budgets.total: %w", err)
+		}
+	}
+	for pattern, raw := range b.Artifacts {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("budgets.artifacts: bad pattern %q: %w", pattern, err)
+		}
+		if _, err := parseSize(raw); err != nil {
+			return fmt.Errorf("budgets.artifacts[%q]: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// checkSizeBudgets reports artifacts over budget; it only returns an error
+// when the budget is enforced with "fail".
+func (cfg *config) checkSizeBudgets(artifacts []string) error {
+	budgets := cfg.file.Budgets
+	if budgets.Total == "" && len(budgets.Artifacts) == 0 {
+		return nil
+	}
+
+	var patterns []string
+	for pattern := range budgets.Artifacts {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var violations []string
+	var total int64
+	for _, path := range artifacts {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+		name := filepath.Base(path)
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(pattern, name); !ok {
+				continue
+			}
+			limit, _ := parseSize(budgets.Artifacts[pattern])
+			if info.Size() > limit {
+				violations = append(violations, fmt.Sprintf("%s is %s (budget %s)", name, formatSize(info.Size()), formatSize(limit)))
+			}
+		}
+	}
+	if budgets.Total != "" {
+		if limit, _ := parseSize(budgets.Total); total > limit {
+			violations = append(violations, fmt.Sprintf("total is %s (budget %s)", formatSize(total), formatSize(limit)))
+		}
+	}
+
+	if len(violations) == 0 {
+		fmt.Println("✓ All artifacts within size budgets")
+		return nil
+	}
+	for _, v := range violations {
+		fmt.Printf("⚠ Size budget exceeded: %s\n", v)
+	}
+	if budgets.Enforce == "fail" {
+		return fmt.Errorf("%d size budget(s) exceeded", len(violations))
+	}
+	return nil
+}
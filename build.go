@@ -7,23 +7,39 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
 // Build-related functions
 
-func (cfg *config) buildBinary(ctx context.Context, spec binSpec, target buildTarget, outputDir string) buildResult {
-	result := buildResult{
+// buildConfig controls path and VCS information embedded in binaries. The
+// defaults keep artifacts path-independent and free of workspace details.
+type buildConfig struct {
+	FullPaths bool `json:"full_paths"` // keep absolute source paths (debugging)
+	BuildVCS  bool `json:"buildvcs"`   // stamp VCS revision info into binaries
+
+	Patches map[string][]string `json:"patches"` // code repo -> patch files applied before building
+}
+
+func (b buildConfig) flags() []string {
+	var flags []string
+	if !b.FullPaths {
+		flags = append(flags, "-trimpath")
+	}
+	return append(flags, fmt.Sprintf("-buildvcs=%t", b.BuildVCS))
+}
+
+func (cfg *config) buildBinary(ctx context.Context, spec binSpec, target buildTarget, outputDir string) (result buildResult) {
+	ctx, span := startSpan(ctx, "build", "build.binary", spec.name, "build.target", string(target))
+	defer func() { span.end(result.err) }()
+
+	result = buildResult{
 		binary: spec.name,
 		target: target,
 	}
 
-	// Check target support
-	if target == targetWASM && !spec.wasmSupport {
-		result.err = fmt.Errorf("WASM not supported (requires %v)", getRequirement(spec))
-		return result
-	}
-	if target == targetWASI && !spec.wasiSupport {
-		result.err = fmt.Errorf("WASI not supported (requires %v)", getRequirement(spec))
+	if err := cfg.checkTargetSupport(ctx, spec, target); err != nil {
+		result.err = err
 		return result
 	}
 
@@ -47,23 +63,27 @@ func (cfg *config) buildBinary(ctx context.Context, spec binSpec, target buildTa
 	// Build from srcDir using go.work
 	fmt.Printf("Building %s for %s...\n", spec.name, target)
 
-	cmd := exec.CommandContext(ctx, cfg.goCmd, "build", "-o", absOutPath, spec.pkg)
-	cmd.Dir = srcDir // Run from workspace directory
+	cmd := cfg.goBuildCommand(ctx, spec, target, absOutPath, nil, cfg.file.Build.flags()...)
 
-	// Set cross-compilation environment
-	goos, goarch := target.buildEnv()
-	env := os.Environ()
-	if goos != "" {
-		env = append(env, "GOOS="+goos)
+	// Capture output per artifact so interleaved builds stay readable
+	if err := os.MkdirAll(cfg.getBuildLogDir(), 0755); err != nil {
+		result.err = fmt.Errorf("mkdir logs: %w", err)
+		return result
 	}
-	if goarch != "" {
-		env = append(env, "GOARCH="+goarch)
+	result.log = cfg.getBuildLogPath(filename)
+	logFile, err := os.Create(result.log)
+	if err != nil {
+		result.err = fmt.Errorf("create log: %w", err)
+		return result
 	}
-	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	defer logFile.Close()
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
 
-	if err := cmd.Run(); err != nil {
+	start := time.Now()
+	err = cmd.Run()
+	result.duration = time.Since(start)
+	if err != nil {
 		result.err = fmt.Errorf("build failed: %w", err)
 		return result
 	}
@@ -72,6 +92,41 @@ func (cfg *config) buildBinary(ctx context.Context, spec binSpec, target buildTa
 	return result
 }
 
+// checkTargetSupport reports whether spec can be built for target on this machine.
+func (cfg *config) checkTargetSupport(ctx context.Context, spec binSpec, target buildTarget) error {
+	if target == targetWASM && !spec.wasmSupport {
+		return fmt.Errorf("WASM not supported (requires %v)", getRequirement(spec))
+	}
+	if target == targetWASI && !spec.wasiSupport {
+		return fmt.Errorf("WASI not supported (requires %v)", getRequirement(spec))
+	}
+	// UI viewers need system libraries for cgo; check before the compiler does
+	if target == targetNative && spec.requiresUI {
+		return cfg.checkUIDeps(ctx, spec)
+	}
+	return nil
+}
+
+// goBuildCommand prepares `go build` for spec, run from the srcDir workspace
+// with env applied on top of the workspace go environment.
+func (cfg *config) goBuildCommand(ctx context.Context, spec binSpec, target buildTarget, outPath string, env []string, flags ...string) *exec.Cmd {
+	args := append([]string{"build"}, flags...)
+	args = append(args, "-o", outPath, spec.pkg)
+	cmd := exec.CommandContext(ctx, cfg.goCmd, args...)
+	cmd.Dir = srcDir // Run from workspace directory
+
+	// Set cross-compilation environment
+	goos, goarch := target.buildEnv()
+	if goos != "" {
+		env = append(env, "GOOS="+goos)
+	}
+	if goarch != "" {
+		env = append(env, "GOARCH="+goarch)
+	}
+	cfg.useEnv(cmd, envGoWork, env...)
+	return cmd
+}
+
 func (cfg *config) buildFilename(name string, target buildTarget) string {
 	switch target {
 	case targetWASM:
@@ -79,14 +134,17 @@ func (cfg *config) buildFilename(name string, target buildTarget) string {
 	case targetWASI:
 		return fmt.Sprintf("%s-wasi.wasm", name)
 	default: // native
-		goos := runtime.GOOS
-		goarch := runtime.GOARCH
-		ext := ""
-		if goos == "windows" {
-			ext = ".exe"
-		}
-		return fmt.Sprintf("%s-%s-%s%s", name, goos, goarch, ext)
+		return platformFilename(name, runtime.GOOS, runtime.GOARCH)
+	}
+}
+
+// platformFilename names a native binary for goos/goarch.
+func platformFilename(name, goos, goarch string) string {
+	ext := ""
+	if goos == "windows" {
+		ext = ".exe"
 	}
+	return fmt.Sprintf("%s-%s-%s%s", name, goos, goarch, ext)
 }
 
 func (cfg *config) buildAll(ctx context.Context, targets []buildTarget, outputDir string) ([]buildResult, error) {
@@ -108,6 +166,6 @@ func getRequirement(spec binSpec) string {
 }
 
 func (cfg *config) getBinaryPath(name string) string {
-	return filepath.Join(cfg.distDir, name+"-"+runtime.GOOS+"-"+runtime.GOARCH)
+	return cfg.tools().BinaryPath(name)
 }
 
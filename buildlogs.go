@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Build log capture and inspection
+
+const logExcerptLines = 8
+
+// logExcerpt returns the last n non-empty lines of a build log.
+func logExcerpt(path string, n int) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return lastLines(string(data), n)
+}
+
+// lastLines returns the last n non-empty lines of text.
+func lastLines(text string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+func (cfg *config) listBuildLogs() []string {
+	entries, err := os.ReadDir(cfg.getBuildLogDir())
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".log"); ok && !entry.IsDir() {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (cfg *config) printBuildLog(artifact string) error {
+	artifact = strings.TrimSuffix(strings.TrimSpace(artifact), ".log")
+	data, err := os.ReadFile(cfg.getBuildLogPath(artifact))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no build log for %q (available: %s)", artifact, strings.Join(cfg.listBuildLogs(), ", "))
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		fmt.Printf("%s built without output\n", artifact)
+		return nil
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Build summary persistence and comparison between runs
+
+const (
+	sizeRegressionPct = 10              // flag artifacts growing by more than this percent
+	timeRegressionPct = 50              // flag builds slowing down by more than this percent
+	minTimeRegression = 2 * time.Second // ignore slowdowns smaller than this
+)
+
+type artifactSummary struct {
+	Artifact   string      `json:"artifact"`
+	Binary     string      `json:"binary"`
+	Target     buildTarget `json:"target"`
+	Status     string      `json:"status"`
+	Size       int64       `json:"size,omitempty"`
+	DurationMS int64       `json:"duration_ms"`
+	Error      string      `json:"error,omitempty"`
+}
+
+type buildSummary struct {
+	Time      time.Time         `json:"time"`
+	Artifacts []artifactSummary `json:"artifacts"`
+	CLI       cliSurface        `json:"cli,omitempty"`     // flags of the native tools
+	Modules   []moduleVersion   `json:"modules,omitempty"` // Go modules built against
+}
+
+func (r buildResult) status() string {
+	switch {
+	case r.err == nil:
+		return "ok"
+	case strings.Contains(r.err.Error(), "not supported"):
+		return "skipped"
+	default:
+		return "failed"
+	}
+}
+
+// printBuildResults prints one line per artifact and returns the failure count.
+func printBuildResults(results []buildResult) int {
+	fmt.Println("\n=== Build Results ===")
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.status()]++
+		switch result.status() {
+		case "ok":
+			fmt.Printf("✓ %s\n", result.path)
+		case "skipped":
+			fmt.Printf("⊘ %s: %v\n", result.binary, result.err)
+		default:
+			fmt.Printf("✗ %s: %v\n", result.binary, result.err)
+			if result.log != "" {
+				for _, line := range logExcerpt(result.log, logExcerptLines) {
+					fmt.Printf("    %s\n", line)
+				}
+				fmt.Printf("    full log: decktool dev dist logs %s\n", filepath.Base(result.path))
+			}
+		}
+	}
+	fmt.Printf("\nTotal: %d succeeded, %d failed, %d skipped\n", counts["ok"], counts["failed"], counts["skipped"])
+	return counts["failed"]
+}
+
+func (cfg *config) summarizeBuild(results []buildResult) buildSummary {
+	summary := buildSummary{Time: time.Now().UTC()}
+	for _, result := range results {
+		entry := artifactSummary{
+			Artifact:   cfg.buildFilename(result.binary, result.target),
+			Binary:     result.binary,
+			Target:     result.target,
+			Status:     result.status(),
+			DurationMS: result.duration.Milliseconds(),
+		}
+		if result.err != nil {
+			entry.Error = result.err.Error()
+		} else if info, err := os.Stat(result.path); err == nil {
+			entry.Size = info.Size()
+		}
+		summary.Artifacts = append(summary.Artifacts, entry)
+	}
+	return summary
+}
+
+// loadBuildSummary returns the previous summary, or nil if none was recorded.
+func (cfg *config) loadBuildSummary() (*buildSummary, error) {
+	data, err := os.ReadFile(cfg.getBuildSummaryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var summary buildSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", cfg.getBuildSummaryPath(), err)
+	}
+	return &summary, nil
+}
+
+func (cfg *config) saveBuildSummary(summary buildSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.getBuildSummaryPath()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.getBuildSummaryPath(), append(data, '\n'), 0644)
+}
+
+// compareBuildSummaries reports new failures and size/time regressions.
+func compareBuildSummaries(prev, cur buildSummary) []string {
+	before := make(map[string]artifactSummary)
+	for _, a := range prev.Artifacts {
+		before[a.Artifact] = a
+	}
+	var findings []string
+	for _, a := range cur.Artifacts {
+		old, ok := before[a.Artifact]
+		if !ok {
+			continue
+		}
+		if a.Status == "failed" && old.Status == "ok" {
+			findings = append(findings, fmt.Sprintf("✗ %s: newly failing", a.Artifact))
+			continue
+		}
+		if a.Status != "ok" || old.Status != "ok" {
+			continue
+		}
+		if old.Size > 0 && a.Size > old.Size*(100+sizeRegressionPct)/100 {
+			findings = append(findings, fmt.Sprintf("▲ %s: size %s -> %s (+%d%%)",
+				a.Artifact, formatSize(old.Size), formatSize(a.Size), (a.Size-old.Size)*100/old.Size))
+		}
+		oldDur := time.Duration(old.DurationMS) * time.Millisecond
+		curDur := time.Duration(a.DurationMS) * time.Millisecond
+		if oldDur > 0 && curDur-oldDur > minTimeRegression && curDur > oldDur*(100+timeRegressionPct)/100 {
+			findings = append(findings, fmt.Sprintf("▲ %s: build time %s -> %s",
+				a.Artifact, oldDur.Round(time.Second/10), curDur.Round(time.Second/10)))
+		}
+	}
+	if prev.CLI != nil && cur.CLI != nil {
+		for _, change := range diffCLISurface(prev.CLI, cur.CLI) {
+			findings = append(findings, "≠ "+change)
+		}
+	}
+	return findings
+}
+
+func (cfg *config) printBuildComparison(prev *buildSummary, cur buildSummary) {
+	fmt.Println("\n=== Compared With Last Build ===")
+	if prev == nil {
+		fmt.Println("No previous build summary recorded")
+		return
+	}
+	fmt.Printf("Previous build: %s\n", prev.Time.Local().Format(time.DateTime))
+	findings := compareBuildSummaries(*prev, cur)
+	if len(findings) == 0 {
+		fmt.Println("✓ No new failures or regressions")
+		return
+	}
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Verification bundles for air-gapped networks
+//
+// A bundle (<tag>-verification.tar.gz) carries everything needed to check
+// separately transferred artifacts offline: the signed provenance, a
+// SHA256SUMS file usable with `sha256sum -c`, and a manifest naming the
+// release, its signer and the expected files (see bundlemanifest.go).
+
+func verificationBundleName(tag string) string {
+	return tag + "-verification.tar.gz"
+}
+
+// exportVerificationBundle writes the verification bundle for tag to out.
+func (cfg *config) exportVerificationBundle(ctx context.Context, tag, out string) error {
+	tmp, err := os.MkdirTemp("", "decktool-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	st, err := downloadProvenance(ctx, tag, tmp)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("release %s has no %s", tag, provenanceFile)
+	}
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkReleaseSigner(ctx, tag, tmp); err != nil {
+		return err
+	}
+
+	manifest, sums, err := newBundleManifest(ctx, tag, tmp, st)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		bundleManifestFile: manifest,
+		bundleChecksumFile: sums,
+	}
+	for _, name := range []string{provenanceFile, signatureFile} {
+		content, err := os.ReadFile(filepath.Join(tmp, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // unsigned release
+		}
+		if err != nil {
+			return err
+		}
+		files[name] = content
+	}
+	return writeTarGz(out, files)
+}
+
+func writeTarGz(path string, files map[string][]byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// extractBundle unpacks the flat bundle archive into dir.
+func extractBundle(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || filepath.Base(hdr.Name) != hdr.Name {
+			return fmt.Errorf("%s: unexpected entry %q", path, hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, 1<<20))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, hdr.Name), data, 0o644); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Verification bundle manifest and checksums
+
+const (
+	bundleManifestFile = "manifest.json"
+	bundleChecksumFile = "SHA256SUMS"
+)
+
+type bundleManifest struct {
+	Tag         string    `json:"tag"`
+	Repo        string    `json:"repo"`
+	Signer      string    `json:"signer"` // key fingerprint, "" if the release is unsigned
+	Builder     string    `json:"builder"`
+	Exported    time.Time `json:"exported"`
+	Artifacts   []string  `json:"artifacts"`
+	Description string    `json:"description"`
+}
+
+// newBundleManifest describes release tag from its provenance st (and the
+// signature downloaded next to it into dir), returning the encoded manifest
+// and the SHA256SUMS file of its artifacts.
+func newBundleManifest(ctx context.Context, tag, dir string, st *provenanceStatement) (manifest, sums []byte, err error) {
+	m := bundleManifest{
+		Tag:         tag,
+		Repo:        releaseRepo(ctx),
+		Builder:     st.Predicate.RunDetails.Builder.ID,
+		Exported:    time.Now().UTC(),
+		Description: "Verify with: decktool release verify --bundle <this file> <artifact dir>, or sha256sum -c SHA256SUMS",
+	}
+	if pub, err := verifyProvenanceSignature(dir); err == nil {
+		m.Signer = keyFingerprint(pub)
+	}
+	var b strings.Builder
+	for _, subject := range st.Subject {
+		m.Artifacts = append(m.Artifacts, subject.Name)
+		fmt.Fprintf(&b, "%s  %s\n", subject.Digest["sha256"], subject.Name)
+	}
+	sort.Strings(m.Artifacts)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(data, '\n'), []byte(b.String()), nil
+}
+
+// loadBundleManifest reads the manifest of a bundle extracted into dir.
+func loadBundleManifest(dir string) (*bundleManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, bundleManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("bundle has no %s", bundleManifestFile)
+	}
+	var m bundleManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", bundleManifestFile, err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Offline verification of artifacts against a verification bundle
+
+// verifyWithBundle checks the artifacts in artifactDir offline against a
+// verification bundle.
+func verifyWithBundle(bundlePath, artifactDir string) error {
+	tmp, err := os.MkdirTemp("", "decktool-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := extractBundle(bundlePath, tmp); err != nil {
+		return err
+	}
+
+	manifest, err := loadBundleManifest(tmp)
+	if err != nil {
+		return err
+	}
+	st, err := loadProvenance(filepath.Join(tmp, provenanceFile))
+	if err != nil {
+		return err
+	}
+	if manifest.Signer != "" {
+		pub, err := verifyProvenanceSignature(tmp)
+		if err != nil {
+			return err
+		}
+		if got := keyFingerprint(pub); got != manifest.Signer {
+			return fmt.Errorf("bundle signed by %s, manifest names %s", got, manifest.Signer)
+		}
+		fmt.Printf("✓ Provenance signature valid (%s)\n", manifest.Signer)
+	} else {
+		fmt.Printf("⚠ %s is unsigned; only checksums are verified\n", manifest.Tag)
+	}
+	fmt.Printf("Release: %s (%s)\n", manifest.Tag, manifest.Repo)
+	st.printSummary()
+
+	var failed, checked int
+	for _, name := range manifest.Artifacts {
+		path := filepath.Join(artifactDir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("⊘ %s (not present)\n", name)
+			continue
+		}
+		checked++
+		if err := st.verifyArtifact(name, path); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d artifacts failed verification", failed, checked)
+	}
+	if checked == 0 {
+		return fmt.Errorf("no artifacts of %s found in %s", manifest.Tag, artifactDir)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Shared cache of release binaries and repo mirrors (DECKTOOL_CACHE)
+//
+// Several users of one build machine point DECKTOOL_CACHE at the same
+// group-writable directory:
+//
+//	releases/<tag>/<file>          downloaded binaries (read-only)
+//	releases/<tag>/<file>.sha256   checksum verified on every read
+//	mirrors/<repo>.git             bare mirrors used as clone references
+//	locks/<key>.lock               advisory locks serialising writers
+//
+// Full-history checkouts in .data/.src borrow objects from the mirrors (see mirrors.go),
+// and binaries in .dist are hard links into releases/, so each is stored
+// once per machine.
+
+const (
+	cacheDirMode  os.FileMode = 0o775 | fs.ModeSetgid // group-writable, new entries inherit the group
+	cacheFileMode os.FileMode = 0o664
+	cacheBinMode  os.FileMode = 0o555 // never written in place; replaced via rename
+)
+
+func (cfg *config) getCacheReleasePath(tag, filename string) string {
+	return filepath.Join(cfg.cacheDir, "releases", tag, filename)
+}
+
+// mkdirShared creates dir (and parents) group-writable regardless of umask.
+func mkdirShared(dir string) error {
+	if err := os.MkdirAll(dir, 0o775); err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0o070 != 0o070 {
+		// Only the owner can fix permissions; others inherit what is there
+		os.Chmod(dir, cacheDirMode)
+	}
+	return nil
+}
+
+// cachedRelease links tag/filename from the shared cache into dest,
+// downloading it into the cache first when missing or corrupt. verify checks
+// a file against the release's provenance: a download failing it never
+// enters the cache, and a cached copy failing it is evicted.
+func (cfg *config) cachedRelease(ctx context.Context, tag, filename, dest string, download, verify func(path string) error) error {
+	stdout, _ := toolOutput(ctx)
+	cached := cfg.getCacheReleasePath(tag, filename)
+	err := cfg.withCacheLock("release-"+tag+"-"+filename, func() error {
+		if _, err := os.Stat(cached); err == nil {
+			err := verifyCached(cached)
+			if err == nil {
+				err = verify(cached)
+			}
+			if err == nil {
+				return nil
+			}
+			fmt.Fprintf(stdout, "✗ Cached %s is corrupt (%v), downloading again\n", filename, err)
+			os.Remove(cached) // evicted even if the new download fails too
+			os.Remove(cached + ".sha256")
+		}
+		dir := filepath.Dir(cached)
+		if err := mkdirShared(dir); err != nil {
+			return err
+		}
+		tmp, err := os.MkdirTemp(dir, ".download-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		if err := download(tmp); err != nil {
+			return err
+		}
+		if err := verify(filepath.Join(tmp, filename)); err != nil {
+			return err
+		}
+		sum, err := fileSHA256(filepath.Join(tmp, filename))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(cached+".sha256", []byte(sum+"\n"), cacheFileMode); err != nil {
+			return err
+		}
+		os.Chmod(cached+".sha256", cacheFileMode)
+		if err := os.Chmod(filepath.Join(tmp, filename), cacheBinMode); err != nil {
+			return err
+		}
+		return os.Rename(filepath.Join(tmp, filename), cached)
+	})
+	if err != nil {
+		return err
+	}
+	return linkOrCopy(cached, dest)
+}
+
+// linkRefused warns, once, that cached binaries are copied instead of linked.
+var linkRefused sync.Once
+
+// linkOrCopy hard-links src to dest, copying when they are on different
+// filesystems or the kernel refuses the link. Linux with
+// fs.protected_hardlinks=1 (the default) refuses links to files of another
+// user that the caller cannot write, which every 0555 binary cached by a
+// colleague is; that fallback costs disk space, so it is reported.
+func linkOrCopy(src, dest string) error {
+	if err := os.Remove(dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	err := os.Link(src, dest)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		linkRefused.Do(func() {
+			fmt.Fprintf(os.Stderr, "⚠ Cannot hard-link from the shared cache (%v); copying binaries instead. Binaries cached by another user only link with the fs.protected_hardlinks sysctl off\n", err)
+		})
+	}
+	if err := copyFile(src, dest); err != nil {
+		return err
+	}
+	return os.Chmod(dest, 0o755)
+}
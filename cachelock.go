@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Advisory locks serialising writers of the shared cache and the mirrors
+// (flock on Unix, see cachelock_unix.go)
+
+// withCacheLock runs fn while holding the named cache lock.
+func (cfg *config) withCacheLock(key string, fn func() error) error {
+	return withLock(filepath.Join(cfg.cacheDir, "locks"), key, fn)
+}
+
+// withLock runs fn while holding the named lock in dir.
+func withLock(dir, key string, fn func() error) error {
+	if err := mkdirShared(dir); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, strings.ReplaceAll(key, "/", "_")+".lock")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, cacheFileMode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	os.Chmod(path, cacheFileMode)
+	if err := lockFileExclusive(f); err != nil {
+		return fmt.Errorf("lock %s: %w", key, err)
+	}
+	defer unlockFile(f)
+	return fn()
+}
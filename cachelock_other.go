@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// Without flock, locks are no-ops: fine for one user's mirrors, but the
+// shared cache (DECKTOOL_CACHE), where several users write at once, is
+// refused on these platforms.
+const fileLocking = false
+
+func lockFileExclusive(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
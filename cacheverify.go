@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// verifyCached checks a cached file against its .sha256 sidecar.
+func verifyCached(path string) error {
+	want, err := os.ReadFile(path + ".sha256")
+	if err != nil {
+		return fmt.Errorf("missing checksum: %w", err)
+	}
+	got, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if got != strings.TrimSpace(string(want)) {
+		return fmt.Errorf("checksum mismatch (have %s, want %s)", got[:12], strings.TrimSpace(string(want)))
+	}
+	return nil
+}
+
+// verifySharedCache checks every cached release binary, removing corrupt
+// ones so the next ensure downloads them again.
+func (cfg *config) verifySharedCache() (corrupt int, err error) {
+	root := filepath.Join(cfg.cacheDir, "releases")
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(path, ".sha256") || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		return cfg.withCacheLock("release-"+strings.ReplaceAll(rel, string(filepath.Separator), "-"), func() error {
+			if err := verifyCached(path); err != nil {
+				corrupt++
+				fmt.Printf("✗ %s: %v (removed)\n", rel, err)
+				os.Remove(path + ".sha256")
+				return os.Remove(path)
+			}
+			fmt.Printf("✓ %s\n", rel)
+			return nil
+		})
+	})
+	return corrupt, err
+}
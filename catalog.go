@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Example catalog export for docs sites and the gallery generator
+
+// constructTags maps decksh commands to the feature they exercise; the tag
+// names match the feature decks.
+var constructTags = map[string]string{
+	"for": "loops", "if": "conditionals", "func": "functions", "def": "functions",
+	"arrow": "arrows", "rarrow": "arrows", "larrow": "arrows", "uarrow": "arrows", "darrow": "arrows",
+	"line": "lines", "hline": "lines", "vline": "lines", "curve": "lines", "arc": "lines",
+	"list": "lists", "blist": "lists", "nlist": "lists", "clist": "lists",
+	"rect": "shapes", "square": "shapes", "circle": "shapes", "ellipse": "shapes", "polygon": "shapes",
+	"text": "text", "ctext": "text", "etext": "text", "textblock": "text", "textfile": "text",
+	"image": "images", "cimage": "images",
+	"dchart": "charts",
+}
+
+// catalogEntry describes one example. ID is derived from source and name
+// only, so links stay valid however listings are ordered.
+type catalogEntry struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Source    string   `json:"source"`
+	Title     string   `json:"title,omitempty"`
+	Tags      []string `json:"tags"`
+	Slides    int      `json:"slides"`
+	Script    string   `json:"script"`
+	DataFiles []string `json:"data_files,omitempty"`
+	Thumbnail string   `json:"thumbnail,omitempty"`
+}
+
+func catalogID(name string) string {
+	return strings.ReplaceAll(name, "/", "-")
+}
+
+// exampleCatalog describes every example, sorted by ID.
+func (cfg *config) exampleCatalog() ([]catalogEntry, error) {
+	examples, err := cfg.listExamples()
+	if err != nil {
+		return nil, err
+	}
+	// IDs flatten the source into the name, so a-b/c and a/b-c would share one
+	if err := checkCollisions(examples, catalogID); err != nil {
+		return nil, err
+	}
+	var entries []catalogEntry
+	for _, name := range examples {
+		source, example := cfg.parseExample(name)
+		dir, err := cfg.getExampleDir(source, example)
+		if err != nil {
+			return nil, err
+		}
+		dsh := cfg.getExampleDshPath(dir, example)
+		if _, err := os.Stat(dsh); err != nil {
+			continue // directory without a deck
+		}
+		entry := catalogEntry{ID: catalogID(name), Name: name, Source: source, Script: relToCwd(dsh)}
+		entry.Title, entry.Tags, entry.Slides, err = scanDeck(dsh)
+		if err != nil {
+			return nil, err
+		}
+		entry.Tags = append([]string{source}, entry.Tags...)
+		entry.DataFiles = deckDataFiles(dir)
+		if pages, _ := filepath.Glob(strings.TrimSuffix(cfg.getExampleXmlPath(dir, example), ".xml") + "-*.png"); len(pages) > 0 {
+			sort.Strings(pages)
+			entry.Thumbnail = relToCwd(pages[0])
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// scanDeck reads the leading comment as the title, the constructs used as
+// tags, and counts slides.
+func scanDeck(path string) (title string, tags []string, slides int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	defer f.Close()
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if comment, ok := strings.CutPrefix(line, "//"); ok {
+			if title == "" && slides == 0 {
+				title = strings.TrimSpace(comment)
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "slide" {
+			slides++
+		}
+		if tag, ok := constructTags[fields[0]]; ok && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return title, tags, slides, scanner.Err()
+}
+
+// deckDataFiles lists the non-deck files next to an example (data, images).
+func deckDataFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir(), strings.HasPrefix(name, "."):
+		case strings.HasSuffix(name, ".dsh"), strings.HasSuffix(name, ".xml"),
+			strings.HasSuffix(name, ".pdf"), strings.HasSuffix(name, ".png"), strings.HasSuffix(name, ".svg"):
+		default:
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func relToCwd(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+func writeCatalog(w io.Writer, entries []catalogEntry, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "yaml":
+		return writeCatalogYAML(w, entries)
+	default:
+		return fmt.Errorf("unknown format %q (want json or yaml)", format)
+	}
+}
+
+// writeCatalogYAML emits the catalog as a YAML sequence; values are quoted
+// so names and titles never need escaping rules beyond JSON strings.
+func writeCatalogYAML(w io.Writer, entries []catalogEntry) error {
+	bw := bufio.NewWriter(w)
+	list := func(key string, values []string) {
+		if len(values) == 0 {
+			fmt.Fprintf(bw, "  %s: []\n", key)
+			return
+		}
+		fmt.Fprintf(bw, "  %s:\n", key)
+		for _, v := range values {
+			fmt.Fprintf(bw, "    - %s\n", strconv.Quote(v))
+		}
+	}
+	for _, e := range entries {
+		fmt.Fprintf(bw, "- id: %s\n", strconv.Quote(e.ID))
+		fmt.Fprintf(bw, "  name: %s\n", strconv.Quote(e.Name))
+		fmt.Fprintf(bw, "  source: %s\n", strconv.Quote(e.Source))
+		fmt.Fprintf(bw, "  title: %s\n", strconv.Quote(e.Title))
+		list("tags", e.Tags)
+		fmt.Fprintf(bw, "  slides: %d\n", e.Slides)
+		fmt.Fprintf(bw, "  script: %s\n", strconv.Quote(e.Script))
+		list("data_files", e.DataFiles)
+		fmt.Fprintf(bw, "  thumbnail: %s\n", strconv.Quote(e.Thumbnail))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Toolchain channels: which kind of release ensure follows
+//
+// The channel is recorded in the lockfile with the release it last resolved
+// to, so a project can track dev builds (or stay on stable) and everyone
+// syncing it gets the same binaries. DECKTOOL_CHANNEL overrides the channel
+// for one shell or CI job.
+
+var toolchainChannels = []string{"stable", "beta", "nightly"}
+
+// toolchainPin is the channel followed and the release it resolved to.
+type toolchainPin struct {
+	Channel string    `json:"channel"`
+	Release string    `json:"release"`
+	Updated time.Time `json:"updated"`
+}
+
+type ghRelease struct {
+	TagName      string `json:"tagName"`
+	IsPrerelease bool   `json:"isPrerelease"`
+	IsDraft      bool   `json:"isDraft"`
+}
+
+func validChannel(channel string) error {
+	for _, c := range toolchainChannels {
+		if c == channel {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown channel %q (want %s)", channel, strings.Join(toolchainChannels, ", "))
+}
+
+// inChannel reports whether a release belongs to a channel: stable is any
+// full release, nightly the timestamped dev-* prereleases dev release
+// creates, beta the other prereleases (e.g. v0.2.0-beta).
+func (r ghRelease) inChannel(channel string) bool {
+	if r.IsDraft {
+		return false
+	}
+	switch channel {
+	case "stable":
+		return !r.IsPrerelease
+	case "nightly":
+		return r.IsPrerelease && strings.HasPrefix(r.TagName, "dev-")
+	case "beta":
+		return r.IsPrerelease && !strings.HasPrefix(r.TagName, "dev-")
+	}
+	return false
+}
+
+// channelReleaseTag returns the newest release in the channel.
+func channelReleaseTag(ctx context.Context, channel string) (string, error) {
+	out, err := exec.CommandContext(ctx, "gh", "release", "list", "--limit", "100",
+		"--json", "tagName,isPrerelease,isDraft").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list releases: %w", err)
+	}
+	var releases []ghRelease // newest first
+	if err := json.Unmarshal(out, &releases); err != nil {
+		return "", fmt.Errorf("parse release list: %w", err)
+	}
+	for _, r := range releases {
+		if r.inChannel(channel) {
+			return r.TagName, nil
+		}
+	}
+	return "", fmt.Errorf("no %s releases found", channel)
+}
+
+// currentChannel is the channel in effect and where it was set ("" when
+// none is, in which case ensure uses the latest release of any kind).
+func currentChannel(lock *lockFile) (channel, source string) {
+	if env := os.Getenv("DECKTOOL_CHANNEL"); env != "" {
+		return env, "DECKTOOL_CHANNEL"
+	}
+	if lock.Toolchain != nil && lock.Toolchain.Channel != "" {
+		return lock.Toolchain.Channel, lockFileName
+	}
+	return "", ""
+}
+
+// selectRelease picks the release ensure downloads from, moving the
+// lockfile pin when the channel has a newer release.
+func (cfg *config) selectRelease(ctx context.Context) (string, error) {
+	stdout, _ := toolOutput(ctx)
+	lock, err := loadLockFile()
+	if err != nil {
+		return "", err
+	}
+	channel, source := currentChannel(lock)
+	if channel == "" {
+		return latestReleaseTag(ctx)
+	}
+	if err := validChannel(channel); err != nil {
+		return "", fmt.Errorf("%s: %w", source, err)
+	}
+	tag, err := channelReleaseTag(ctx, channel)
+	if err != nil {
+		return "", err
+	}
+	pin := lock.Toolchain
+	if source != lockFileName || (pin.Channel == channel && pin.Release == tag) {
+		return tag, nil
+	}
+	if pin.Release != "" {
+		fmt.Fprintf(stdout, "▲ %s channel: %s → %s\n", channel, pin.Release, tag)
+	}
+	lock.Toolchain = &toolchainPin{Channel: channel, Release: tag, Updated: time.Now().UTC()}
+	return tag, lock.save()
+}
+
+// switchChannel records a new channel in the lockfile and re-fetches the
+// binaries from its newest release, even where local copies are newer (as
+// they are when moving to an older channel).
+func (cfg *config) switchChannel(ctx context.Context, channel string) error {
+	if err := validChannel(channel); err != nil {
+		return err
+	}
+	if env := os.Getenv("DECKTOOL_CHANNEL"); env != "" && env != channel {
+		fmt.Printf("⚠ DECKTOOL_CHANNEL=%s overrides %s in this shell\n", env, lockFileName)
+	}
+	lock, err := loadLockFile()
+	if err != nil {
+		return err
+	}
+	previous := ""
+	if lock.Toolchain != nil {
+		previous = lock.Toolchain.Channel
+	}
+	lock.Toolchain = &toolchainPin{Channel: channel, Updated: time.Now().UTC()}
+	if err := lock.save(); err != nil {
+		return err
+	}
+	if previous != "" && previous != channel {
+		fmt.Printf("Switched from %s to %s\n", previous, channel)
+	}
+	cfg.forceDownload = true
+	return cfg.ensureBins(ctx)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Property checks: invariants every rendered deck should satisfy, catching
+// generator bugs that produce wrong output without failing. Violations are
+// warnings for run and failures for test.
+
+// propertyChecks names the available checks.
+var propertyChecks = map[string]string{
+	"bounds":    "elements positioned outside the 0-100% canvas",
+	"text-size": "text and lists with zero or negative size",
+	"images":    "images whose file does not exist",
+	"finite":    "NaN or infinite coordinates and sizes",
+}
+
+// checksConfig selects and tunes the property checks.
+type checksConfig struct {
+	Disable []string            `json:"disable"` // checks not run
+	Margin  float64             `json:"margin"`  // percent beyond the canvas "bounds" tolerates
+	Skip    map[string][]string `json:"skip"`    // check -> deck patterns (path.Match) it ignores
+}
+
+func (c checksConfig) validate() error {
+	for _, name := range c.Disable {
+		if _, ok := propertyChecks[name]; !ok {
+			return fmt.Errorf("checks.disable: unknown check %q", name)
+		}
+	}
+	if c.Margin < 0 {
+		return errors.New("checks.margin must not be negative")
+	}
+	for name, patterns := range c.Skip {
+		if _, ok := propertyChecks[name]; !ok {
+			return fmt.Errorf("checks.skip: unknown check %q", name)
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("checks.skip.%s: bad pattern %q", name, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// enabled reports whether check applies to deck.
+func (c checksConfig) enabled(check, deck string) bool {
+	return ruleApplies(c.Disable, c.Skip, check, deck)
+}
+
+// propertyViolation is one element breaking an invariant.
+type propertyViolation struct {
+	Check   string
+	Slide   int
+	Element string
+	Message string
+}
+
+func (v propertyViolation) String() string {
+	return fmt.Sprintf("[%s] slide %d <%s>: %s", v.Check, v.Slide, v.Element, v.Message)
+}
+
+// Attributes holding percent coordinates and sizes in deck markup.
+var (
+	positionAttrs = []string{"xp", "yp", "xp1", "yp1", "xp2", "yp2", "xp3", "yp3"}
+	sizeAttrs     = []string{"wp", "hp", "sp", "lp"}
+	polygonAttrs  = []string{"xc", "yc"}
+)
+
+// checkDeckProperties runs the enabled checks over a rendered deck. Relative
+// image names resolve against the XML's directory.
+func (cfg *config) checkDeckProperties(deck, xmlPath string) ([]propertyViolation, error) {
+	checks := cfg.file.Checks
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var violations []propertyViolation
+	slide := 0
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return violations, fmt.Errorf("parse %s: %w", filepath.Base(xmlPath), err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		el := start.Name.Local
+		if el == "slide" {
+			slide++
+			continue
+		}
+		report := func(check, format string, args ...any) {
+			if checks.enabled(check, deck) {
+				violations = append(violations, propertyViolation{check, slide, el, fmt.Sprintf(format, args...)})
+			}
+		}
+		attrs := make(map[string]string, len(start.Attr))
+		for _, attr := range start.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+
+		checkValue := func(name, raw string) {
+			v, err := strconv.ParseFloat(raw, 64)
+			switch {
+			case err != nil:
+			case math.IsNaN(v) || math.IsInf(v, 0):
+				report("finite", "%s=%s", name, raw)
+			case !slices.Contains(sizeAttrs, name) && (v < -checks.Margin || v > 100+checks.Margin):
+				report("bounds", "%s=%s outside the canvas", name, raw)
+			}
+		}
+		for _, name := range slices.Concat(positionAttrs, sizeAttrs) {
+			if raw, ok := attrs[name]; ok {
+				checkValue(name, raw)
+			}
+		}
+		for _, name := range polygonAttrs {
+			for _, raw := range strings.Fields(attrs[name]) {
+				checkValue(name, raw)
+			}
+		}
+
+		if el == "text" || el == "list" {
+			if sp, err := strconv.ParseFloat(attrs["sp"], 64); err == nil && sp <= 0 {
+				report("text-size", "sp=%s", attrs["sp"])
+			}
+		}
+		if el == "image" {
+			name := attrs["name"]
+			if name != "" && !strings.Contains(name, "://") {
+				if !filepath.IsAbs(name) {
+					name = filepath.Join(filepath.Dir(xmlPath), name)
+				}
+				if _, err := os.Stat(name); err != nil {
+					report("images", "%s not found", attrs["name"])
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// propertyError summarizes a deck's violations as a test failure.
+func (cfg *config) propertyError(deck, xmlPath string) error {
+	violations, err := cfg.checkDeckProperties(deck, xmlPath)
+	if err != nil {
+		return fmt.Errorf("property checks: %w", err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d property violation(s): %s", len(violations), summarizeViolations(violations, 3, "; "))
+}
+
+// warnPropertyViolations prints a deck's violations as warnings.
+func (cfg *config) warnPropertyViolations(deck, xmlPath string) {
+	violations, err := cfg.checkDeckProperties(deck, xmlPath)
+	if err != nil {
+		fmt.Printf("⚠ %s: property checks: %v\n", deck, err)
+		return
+	}
+	if len(violations) > 0 {
+		fmt.Printf("⚠ %s: %d property violation(s)\n  %s\n", deck, len(violations), summarizeViolations(violations, 10, "\n  "))
+	}
+}
+
+func summarizeViolations(violations []propertyViolation, limit int, sep string) string {
+	var parts []string
+	for _, v := range violations[:min(limit, len(violations))] {
+		parts = append(parts, v.String())
+	}
+	if len(violations) > limit {
+		parts = append(parts, fmt.Sprintf("... and %d more", len(violations)-limit))
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CLI surface of the toolchain: the flags each tool accepts, compared
+// between builds and releases to flag upstream CLI changes
+
+const cliSurfaceFile = "cli-surface.json"
+
+// cliSurface maps a tool name to its sorted flag names.
+type cliSurface map[string][]string
+
+// flagLine matches Go flag package usage lines such as "  -outdir string".
+var flagLine = regexp.MustCompile(`^\s+-{1,2}([A-Za-z0-9][\w.-]*)`)
+
+// captureCLISurface runs each native binary in dist with -h and records its flags.
+func (cfg *config) captureCLISurface(ctx context.Context) cliSurface {
+	surface := make(cliSurface)
+	for _, spec := range cfg.toolchain {
+		path := cfg.getBinaryPath(spec.name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		// -h exits non-zero with the flag package; the usage text is what matters
+		out, _ := exec.CommandContext(runCtx, path, "-h").CombinedOutput()
+		cancel()
+		flags := parseFlags(string(out))
+		if flags == nil {
+			continue
+		}
+		surface[spec.name] = flags
+	}
+	return surface
+}
+
+func parseFlags(usage string) []string {
+	seen := make(map[string]bool)
+	var flags []string
+	for _, line := range strings.Split(usage, "\n") {
+		m := flagLine.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		flags = append(flags, "-"+m[1])
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// diffCLISurface lists tools and flags added or removed between old and cur.
+func diffCLISurface(old, cur cliSurface) []string {
+	var tools []string
+	for tool := range cur {
+		tools = append(tools, tool)
+	}
+	for tool := range old {
+		if _, ok := cur[tool]; !ok {
+			tools = append(tools, tool)
+		}
+	}
+	sort.Strings(tools)
+
+	var changes []string
+	for _, tool := range tools {
+		before, hadBefore := old[tool]
+		after, hasAfter := cur[tool]
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("%s: new tool (%s)", tool, strings.Join(after, " ")))
+			continue
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf("%s: removed", tool))
+			continue
+		}
+		var added, removed []string
+		for _, f := range after {
+			if !slices.Contains(before, f) {
+				added = append(added, f)
+			}
+		}
+		for _, f := range before {
+			if !slices.Contains(after, f) {
+				removed = append(removed, f)
+			}
+		}
+		if len(added) > 0 {
+			changes = append(changes, fmt.Sprintf("%s: new flags %s", tool, strings.Join(added, " ")))
+		}
+		if len(removed) > 0 {
+			changes = append(changes, fmt.Sprintf("%s: removed flags %s", tool, strings.Join(removed, " ")))
+		}
+	}
+	return changes
+}
+
+// writeCLISurface stores surface for upload with a release and returns its path.
+func (cfg *config) writeCLISurface(surface cliSurface) (string, error) {
+	data, err := json.MarshalIndent(surface, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := cfg.getCLISurfacePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// releasedCLISurface downloads the CLI surface published with tag. Releases
+// made before surfaces were recorded yield os.ErrNotExist.
+func releasedCLISurface(ctx context.Context, tag string) (cliSurface, error) {
+	tmp, err := os.MkdirTemp("", "decktool-cli-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	cmd := exec.CommandContext(ctx, "gh", "release", "download", tag, "-p", cliSurfaceFile, "-D", tmp)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if strings.Contains(string(out), "no assets match") {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("download %s: %s", cliSurfaceFile, strings.TrimSpace(string(out)))
+	}
+	data, err := os.ReadFile(filepath.Join(tmp, cliSurfaceFile))
+	if err != nil {
+		return nil, err
+	}
+	var surface cliSurface
+	if err := json.Unmarshal(data, &surface); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", cliSurfaceFile, err)
+	}
+	return surface, nil
+}
+
+// cliChangesSection renders the "CLI surface changes" release notes section
+// comparing cur with the latest published release.
+func cliChangesSection(ctx context.Context, cur cliSurface) string {
+	prevTag, err := latestReleaseTag(ctx)
+	if err != nil {
+		return ""
+	}
+	prev, err := releasedCLISurface(ctx, prevTag)
+	if errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	if err != nil {
+		fmt.Printf("⚠ Cannot compare CLI surface with %s: %v\n", prevTag, err)
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\n## CLI surface changes since %s\n\n", prevTag)
+	changes := diffCLISurface(prev, cur)
+	if len(changes) == 0 {
+		b.WriteString("No flags added or removed.\n")
+	}
+	for _, change := range changes {
+		fmt.Fprintf(&b, "- %s\n", change)
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Clone tuning: partial clone filters per repo and a download bandwidth
+// cap for git.
+
+type cloneConfig struct {
+	Bandwidth string            `json:"bandwidth"` // download cap for git, e.g. 500k or 2m bytes/s (needs trickle)
+	Filters   map[string]string `json:"filters"`   // partial clone filter per repo, e.g. blob:none; "" for a full clone
+}
+
+var cloneFilterRE = regexp.MustCompile(`^(blob:none|blob:limit=\d+[kmg]?|tree:\d+|sparse:oid=\S+)$`)
+
+func (c cloneConfig) validate() error {
+	if c.Bandwidth != "" {
+		if _, err := parseRate(c.Bandwidth); err != nil {
+			return fmt.Errorf("clone.bandwidth: %w", err)
+		}
+	}
+	for name, filter := range c.Filters {
+		if filter != "" && !cloneFilterRE.MatchString(filter) {
+			return fmt.Errorf("clone.filters.%s: %q is not a git filter spec (blob:none, blob:limit=<n>[kmg], tree:<depth>)", name, filter)
+		}
+	}
+	return nil
+}
+
+// parseRate parses a byte rate with an optional k, m or g suffix.
+func parseRate(s string) (int64, error) {
+	mult := int64(1)
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		mult = 1 << 10
+	case "m":
+		mult = 1 << 20
+	case "g":
+		mult = 1 << 30
+	}
+	if mult > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a rate like 500k or 2m", s)
+	}
+	return n * mult, nil
+}
+
+// applyCloneFilters sets the configured filters on the repos; a
+// <NAME>_FILTER variable still wins.
+func (cfg *config) applyCloneFilters() error {
+	for name, filter := range cfg.file.Clone.Filters {
+		repo, ok := cfg.repos[name]
+		if !ok {
+			return fmt.Errorf("%s: clone.filters: unknown repo %q", configFile, name)
+		}
+		if _, set := os.LookupEnv(strings.ToUpper(name) + "_FILTER"); set {
+			continue
+		}
+		repo.filterRaw = ""
+		if filter != "" {
+			repo.filterRaw = "--filter=" + filter
+		}
+	}
+	return nil
+}
+
+var warnNoTrickle sync.Once
+
+// gitCommand builds a git invocation, throttled by trickle when a
+// bandwidth cap is configured. git has no download limit of its own, so
+// without trickle the cap is reported once and ignored.
+func (cfg *config) gitCommand(ctx context.Context, args ...string) *exec.Cmd {
+	if cfg.file.Clone.Bandwidth == "" {
+		return exec.CommandContext(ctx, cfg.gitCmd, args...)
+	}
+	rate, _ := parseRate(cfg.file.Clone.Bandwidth) // validated on load
+	trickle, err := exec.LookPath("trickle")
+	if err != nil {
+		warnNoTrickle.Do(func() {
+			stdout, _ := toolOutput(ctx)
+			fmt.Fprintf(stdout, "⚠ clone.bandwidth is set but trickle is not installed; git downloads are not capped\n")
+		})
+		return exec.CommandContext(ctx, cfg.gitCmd, args...)
+	}
+	kbps := strconv.FormatInt(max(rate>>10, 1), 10)
+	return exec.CommandContext(ctx, trickle, append([]string{"-s", "-d", kbps, cfg.gitCmd}, args...)...)
+}
@@ -0,0 +1,54 @@
+package main
+
+import "sort"
+
+// Contrast of deck text against its slide background, for the palette
+
+// contrastPair is a text color used on a slide background.
+type contrastPair struct {
+	Text, Background string
+	Ratio, Minimum   float64
+	Slides           []int
+}
+
+// contrastCheck collects the text/background pairs of a deck below WCAG AA.
+type contrastCheck struct {
+	pairs map[string]*contrastPair // text hex + background hex -> pair
+}
+
+// check records text in color on bg at slide when its contrast is too low;
+// heightPx decides whether it counts as large text.
+func (c *contrastCheck) check(color, bg string, heightPx float64, slide int) {
+	ratio, ok := contrastRatio(color, bg, 100)
+	if !ok {
+		return
+	}
+	minimum := minContrast
+	if heightPx >= largeTextPx {
+		minimum = minContrastLarge
+	}
+	if ratio >= minimum {
+		return
+	}
+	fgRGB, _ := parseDeckColor(color)
+	bgRGB, _ := parseDeckColor(bg)
+	key := paletteColor{RGB: fgRGB}.hex() + paletteColor{RGB: bgRGB}.hex()
+	if c.pairs[key] == nil {
+		c.pairs[key] = &contrastPair{Text: color, Background: bg, Ratio: ratio, Minimum: minimum}
+	}
+	p := c.pairs[key]
+	p.Minimum = max(p.Minimum, minimum)
+	if len(p.Slides) == 0 || p.Slides[len(p.Slides)-1] != slide {
+		p.Slides = append(p.Slides, slide)
+	}
+}
+
+// lowest returns the pairs found, lowest contrast first.
+func (c *contrastCheck) lowest() []contrastPair {
+	var pairs []contrastPair
+	for _, p := range c.pairs {
+		pairs = append(pairs, *p)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Ratio < pairs[j].Ratio })
+	return pairs
+}
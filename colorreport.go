@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Printing a deck palette and writing it as a decksh include
+
+// swatch draws an approximate color block with a 24-bit ANSI background,
+// or nothing when color output is off.
+func swatch(rgb [3]float64, enabled bool) string {
+	if !enabled {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm    \x1b[0m ", int(rgb[0]), int(rgb[1]), int(rgb[2]))
+}
+
+// terminalColors reports whether stdout is a terminal that wants color.
+func terminalColors() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func printPalette(example string, palette *deckPalette, color bool) {
+	fmt.Printf("=== Palette of %s (%d colors) ===\n", example, len(palette.Colors))
+	for _, c := range palette.Colors {
+		name := c.Spelling
+		if name != c.hex() {
+			name += " (" + c.hex() + ")"
+		}
+		fmt.Printf("  %s%-28s %4d use(s)  %s\n", swatch(c.RGB, color), name, c.Uses, c.roles())
+	}
+	if len(palette.Unparsed) > 0 {
+		fmt.Printf("  not resolved: %s\n", strings.Join(palette.Unparsed, ", "))
+	}
+	if len(palette.Pairs) == 0 {
+		fmt.Println("\n✓ All text meets WCAG AA contrast against its slide background")
+		return
+	}
+	fmt.Printf("\nLow-contrast text/background pairs (%d):\n", len(palette.Pairs))
+	for _, p := range palette.Pairs {
+		fg, _ := parseDeckColor(p.Text)
+		bg, _ := parseDeckColor(p.Background)
+		fmt.Printf("  ⚠ %s%s on %s%s: %.1f:1 (minimum %.1f:1), slide(s) %s\n",
+			swatch(fg, color), p.Text, swatch(bg, color), p.Background, p.Ratio, p.Minimum, joinInts(p.Slides))
+	}
+}
+
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writePaletteInclude writes the palette as decksh variable definitions,
+// most used first, for other decks to include.
+func writePaletteInclude(w io.Writer, example string, palette *deckPalette) error {
+	fmt.Fprintf(w, "// Palette of %s, generated by decktool colors\n", example)
+	for i, c := range palette.Colors {
+		if _, err := fmt.Fprintf(w, "// %s: %s\ncolor%d=%q\n", c.Spelling, c.roles(), i+1, c.hex()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
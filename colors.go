@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Deck color palettes: the colors a rendered deck uses, their roles, and
+// text/background pairs with too little contrast (checked in
+// colorcontrast.go, printed by colorreport.go)
+
+// colorAttrs maps deck markup color attributes to the role they play.
+var colorAttrs = map[string]string{
+	"bg": "background", "fg": "text", "color": "fill", "gradcolor1": "gradient", "gradcolor2": "gradient",
+}
+
+// paletteColor is one distinct color (by RGB value) in a deck.
+type paletteColor struct {
+	Spelling string // the deck's most used spelling of it
+	RGB      [3]float64
+	Uses     int
+	Roles    map[string]int // role -> uses
+}
+
+// roles lists what the color is used for.
+func (c paletteColor) roles() string {
+	var roles []string
+	for role := range c.Roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, ", ")
+}
+
+func (c paletteColor) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", int(c.RGB[0]), int(c.RGB[1]), int(c.RGB[2]))
+}
+
+type deckPalette struct {
+	Colors   []paletteColor // most used first
+	Pairs    []contrastPair // low-contrast pairs only
+	Unparsed []string       // colors that could not be resolved (gradients, hsv, ...)
+}
+
+// extractPalette collects the colors of a rendered deck by role (the color
+// of text and lists counts as text, of other elements as fill) and the
+// text/background pairs below WCAG AA.
+func extractPalette(xmlPath string) (*deckPalette, error) {
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byHex := make(map[string]*paletteColor)
+	spellings := make(map[string]map[string]int) // hex -> spelling -> uses
+	contrast := contrastCheck{pairs: make(map[string]*contrastPair)}
+	unparsed := make(map[string]bool)
+	add := func(value, role string) {
+		value = strings.TrimSpace(value)
+		rgb, ok := parseDeckColor(value)
+		if !ok {
+			if value != "" {
+				unparsed[value] = true
+			}
+			return
+		}
+		c := paletteColor{RGB: rgb}
+		key := c.hex()
+		if byHex[key] == nil {
+			byHex[key] = &paletteColor{RGB: rgb, Roles: make(map[string]int)}
+			spellings[key] = make(map[string]int)
+		}
+		byHex[key].Uses++
+		byHex[key].Roles[role]++
+		spellings[key][value]++
+	}
+
+	canvasWidth := 792.0
+	slide := 0
+	bg, fg := "white", "black"
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", xmlPath, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		el := start.Name.Local
+		attrs := make(map[string]string, len(start.Attr))
+		for _, attr := range start.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+		switch el {
+		case "canvas":
+			fmt.Sscan(attrs["width"], &canvasWidth)
+		case "slide":
+			slide++
+			bg, fg = orDefault(attrs["bg"], "white"), orDefault(attrs["fg"], "black")
+		}
+		for attr, role := range colorAttrs {
+			if v, ok := attrs[attr]; ok {
+				if attr == "color" && (el == "text" || el == "list") {
+					role = "text"
+				}
+				add(v, role)
+			}
+		}
+		if el == "text" || el == "list" {
+			var sp float64
+			fmt.Sscan(attrs["sp"], &sp)
+			contrast.check(orDefault(attrs["color"], fg), bg, sp*canvasWidth/100, slide)
+		}
+	}
+
+	palette := &deckPalette{}
+	for key, c := range byHex {
+		c.Spelling = mostUsed(spellings[key])
+		palette.Colors = append(palette.Colors, *c)
+	}
+	sort.Slice(palette.Colors, func(i, j int) bool {
+		a, b := palette.Colors[i], palette.Colors[j]
+		if a.Uses != b.Uses {
+			return a.Uses > b.Uses
+		}
+		return a.hex() < b.hex()
+	})
+	palette.Pairs = contrast.lowest()
+	for v := range unparsed {
+		palette.Unparsed = append(palette.Unparsed, v)
+	}
+	sort.Strings(palette.Unparsed)
+	return palette, nil
+}
+
+// mostUsed picks the most frequent spelling, preferring color names on ties.
+func mostUsed(counts map[string]int) string {
+	best := ""
+	rank := func(s string) (int, bool) {
+		_, named := namedColors[strings.ToLower(s)]
+		return counts[s], named
+	}
+	for s := range counts {
+		n, named := rank(s)
+		bn, bnamed := rank(best)
+		if best == "" || n > bn || (n == bn && named && !bnamed) || (n == bn && named == bnamed && s < best) {
+			best = s
+		}
+	}
+	return best
+}
@@ -2,9 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-	"sort"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +12,9 @@ func newRootCommand(cfg *config) *cobra.Command {
 		Short:         "Helper CLI for deck examples",
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		Long: `Render, view and publish decksh decks with a managed toolchain.
+
+Building and releasing the toolchain itself is under "decktool dev".`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return cfg.finalize()
 		},
@@ -22,128 +22,143 @@ func newRootCommand(cfg *config) *cobra.Command {
 
 	// Note: Repo-specific flags removed for simplicity
 	// Use environment variables instead (DECKVIZ_DIR, DECKFONTS_DIR, etc.)
+	root.PersistentFlags().BoolVar(&cfg.printEnv, "print-env", false, "print what each started tool, go command, hook or shell gets in its environment (to stderr)")
+
+	// Help lists deck commands apart from workspace upkeep and toolchain
+	// development (see devmode.go)
+	groups := []struct {
+		id, title string
+		cmds      []*cobra.Command
+	}{
+		{"decks", "Deck commands:", []*cobra.Command{
+			newRunCommand(cfg),
+			newViewCommand(cfg),
+			newLintCommand(cfg),
+			newExamplesCommand(cfg),
+			newAliasCommand(cfg),
+			newFavoriteCommand(cfg),
+			newSnippetsCommand(cfg),
+			newDataCommand(cfg),
+			newColorsCommand(cfg),
+			newRehearseCommand(cfg),
+			newGalleryCommand(cfg),
+			newEmbedCommand(cfg),
+			newOGImageCommand(cfg),
+			newHandoutCommand(cfg),
+			newScheduleCommand(cfg),
+			newServeCommand(cfg),
+			newJobsCommand(cfg),
+			newArchiveCommand(cfg),
+			newHistoryCommand(cfg),
+			newRegistryCommand(cfg),
+		}},
+		{"workspace", "Workspace commands:", []*cobra.Command{
+			newEnsureCommand(cfg),
+			newChannelCommand(cfg),
+			newRollbackCommand(cfg),
+			newReleaseCommand(cfg),
+			newWhichCommand(cfg),
+			newShellCommand(cfg),
+			newSetupCommand(cfg),
+			newCompletionCommand(root),
+			newReposCommand(cfg),
+			newStateCommand(cfg),
+			newCacheCommand(cfg),
+			newRestoreCommand(cfg),
+		}},
+		{"dev", "Toolchain development commands:", []*cobra.Command{
+			newDevCommand(cfg),
+			newTestCommand(cfg),
+			newCorpusCommand(cfg),
+		}},
+	}
+	for _, g := range groups {
+		root.AddGroup(&cobra.Group{ID: g.id, Title: g.title})
+		for _, cmd := range g.cmds {
+			cmd.GroupID = g.id
+			root.AddCommand(cmd)
+		}
+	}
+	root.SetHelpCommandGroupID("workspace")
 
-	root.AddCommand(newEnsureCommand(cfg))
-	root.AddCommand(newExamplesCommand(cfg))
-	root.AddCommand(newRunCommand(cfg))
-	root.AddCommand(newViewCommand(cfg))
-	root.AddCommand(newCompletionCommand(root))
-	root.AddCommand(newSetupCommand(cfg))
-	root.AddCommand(newDevBuildCommand(cfg))
-	root.AddCommand(newDevReleaseCommand(cfg))
-	root.AddCommand(newDevCleanCommand(cfg))
+	// Former top-level names of the dev commands
+	root.AddCommand(movedToDev(newDevBuildCommand(cfg), "dev-build"))
+	root.AddCommand(movedToDev(newDevReleaseCommand(cfg), "dev-release"))
+	root.AddCommand(movedToDev(newDevCleanCommand(cfg), "dev-clean"))
+	root.AddCommand(movedToDev(newDistCommand(cfg), "dist"))
+	root.AddCommand(movedToDev(newAuditCommand(cfg), "audit"))
+	root.AddCommand(movedToDev(newLicensesCommand(cfg), "licenses"))
+	root.AddCommand(movedToDev(newFuzzCommand(cfg), "fuzz"))
+	root.AddCommand(movedToDev(newWorktreeCommand(cfg), "worktree"))
 
 	return root
 }
 
 func newEnsureCommand(cfg *config) *cobra.Command {
-	return &cobra.Command{
+	var all bool
+	var porcelainFormat string
+	cmd := &cobra.Command{
 		Use:   "ensure",
 		Short: "Install Go binaries and sync repositories",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Context()
-			if err := cfg.ensureBins(ctx); err != nil {
-				return err
-			}
-			if err := cfg.ensureRepos(ctx); err != nil {
-				return err
-			}
-			fmt.Println("Tooling and repositories are up to date.")
-			return nil
-		},
-	}
-}
+		Long: `Download the latest release binaries and sync the example repositories.
 
-func newExamplesCommand(cfg *config) *cobra.Command {
-	return &cobra.Command{
-		Use:   "examples",
-		Short: "List available examples",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := cfg.ensureRepos(cmd.Context()); err != nil {
-				return err
-			}
-			examples, err := cfg.listExamples()
-			if err != nil {
-				return err
-			}
-			for _, ex := range examples {
-				fmt.Println(ex)
-			}
-			return nil
-		},
-		ValidArgsFunction: cfg.exampleCompletion,
-	}
-}
+Downloads are checked against the release's signed provenance. The signing
+key is pinned on first use (~/.decktool/trust.json); a release signed by a
+different key is refused unless --accept-new-signer is given.
+
+A clone with uncommitted changes or local commits is not updated: --stash
+keeps them (git stash, and a decktool-backup/<time> branch for commits) and
+--force discards them.
 
-func newRunCommand(cfg *config) *cobra.Command {
-	return &cobra.Command{
-		Use:               "run [example]...",
-		Short:             "Lint and render one or more examples",
-		Args:              cobra.MinimumNArgs(1),
-		ValidArgsFunction: cfg.exampleCompletion,
+Example repos (deckviz, dubois) are not cloned until an example from them is
+first used, so a first run only fetches the fonts; --all fetches them now.
+
+--porcelain lists each tool and repo as a tool/missing or repo record (see
+decktool which --help for the format).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := cfg.ensureBins(cmd.Context()); err != nil {
+			ctx := cmd.Context()
+			p, err := startPorcelain(porcelainFormat)
+			if err != nil {
 				return err
 			}
-			if err := cfg.ensureRepos(cmd.Context()); err != nil {
+			if err := cfg.ensureBins(ctx); err != nil {
 				return err
 			}
-			results, err := cfg.runExamples(cmd.Context(), args)
-			if err != nil {
+			if err := cfg.syncDataRepos(ctx, func(*repoConfig) bool { return all }); err != nil {
 				return err
 			}
-			var keys []string
-			for k := range results {
-				keys = append(keys, k)
+			for _, name := range cfg.unfetchedRepos() {
+				fmt.Printf("⊘ %s not fetched yet; it is cloned when one of its examples is first used (ensure --all fetches it now)\n", name)
 			}
-			sort.Strings(keys)
-			for _, name := range keys {
-				fmt.Printf("%s -> %s\n", name, results[name])
+			fmt.Println("Tooling and repositories are up to date.")
+			if p != nil {
+				for _, spec := range cfg.toolchain {
+					cfg.porcelainTool(p, spec.name)
+				}
+				cfg.porcelainRepos(ctx, p)
 			}
 			return nil
 		},
 	}
+	addPorcelainFlag(cmd, &porcelainFormat)
+	cmd.Flags().BoolVar(&cfg.acceptNewSigner, "accept-new-signer", false, "pin a changed release signing key (after an announced key rotation)")
+	cmd.Flags().BoolVar(&all, "all", false, "also clone the example repos not fetched yet")
+	addDirtyFlags(cmd, cfg)
+	return cmd
 }
 
-func newViewCommand(cfg *config) *cobra.Command {
-	return &cobra.Command{
-		Use:               "view [example]",
-		Short:             "Render and open an example in ebdeck",
-		Args:              cobra.ExactArgs(1),
-		ValidArgsFunction: cfg.exampleCompletion,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := cfg.ensureBins(cmd.Context()); err != nil {
-				return err
-			}
-			if err := cfg.ensureRepos(cmd.Context()); err != nil {
-				return err
-			}
-			results, err := cfg.runExamples(cmd.Context(), args)
-			if err != nil {
-				return err
-			}
-			xmlPath, ok := results[cfg.normalizeExampleName(args[0])]
-			if !ok {
-				return fmt.Errorf("rendered XML not found for %q", args[0])
-			}
-
-			// Get example directory to run ebdeck from there (for relative paths in XML)
-			source, name := cfg.parseExample(args[0])
-			exampleDir, err := cfg.getExampleDir(source, name)
-			if err != nil {
-				return err
-			}
-
-			ebdeckPath, err := cfg.resolveBinary("ebdeck")
-			if err != nil {
-				return err
-			}
-			viewCmd := exec.CommandContext(cmd.Context(), ebdeckPath, xmlPath)
-			viewCmd.Dir = exampleDir
-			viewCmd.Env = append(os.Environ(), "DECKFONTS="+cfg.fontsDir)
-			viewCmd.Stdout = os.Stdout
-			viewCmd.Stderr = os.Stderr
-			return viewCmd.Run()
-		},
+// addDirtyFlags adds --stash and --force for commands that update the clones.
+func addDirtyFlags(cmd *cobra.Command, cfg *config) {
+	var stash, force bool
+	cmd.Flags().BoolVar(&stash, "stash", false, "stash local changes in the clones (and keep local commits on a backup branch) before updating")
+	cmd.Flags().BoolVar(&force, "force", false, "discard local changes and commits in the clones when updating")
+	cmd.MarkFlagsMutuallyExclusive("stash", "force")
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		switch {
+		case stash:
+			cfg.dirtyRepos = dirtyStash
+		case force:
+			cfg.dirtyRepos = dirtyForce
+		}
 	}
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Alias and favorite commands
+
+func newAliasCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "List short names for examples",
+		Long: `An alias is a short name any example argument accepts in place of
+source/name: after alias add demo dubois/plate07, decktool run demo renders
+dubois/plate07. Completion offers aliases first. An alias shadows a deckviz
+example of the same name, which stays reachable as deckviz/<name>. Aliases
+and favorites are per user: ~/.decktool/aliases.json, or the file
+DECKTOOL_ALIASES names.
+
+Examples:
+  decktool alias add demo dubois/plate07
+  decktool run demo
+  decktool alias
+  decktool alias remove demo`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := cfg.shortcuts.aliasNames()
+			if len(names) == 0 {
+				fmt.Println("No aliases (decktool alias add <name> <example>)")
+				return nil
+			}
+			for _, name := range names {
+				fmt.Printf("%-12s %s\n", name, cfg.shortcuts.Aliases[name])
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add <name> <example>",
+		Short: "Point an alias at an example",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return cfg.exampleCompletion(cmd, args, toComplete)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.addAlias(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("✓ %s -> %s\n", args[0], cfg.shortcuts.Aliases[args[0]])
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "remove <name>",
+		Short: "Delete an alias",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return cfg.shortcuts.aliasNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.removeAlias(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Removed alias %s\n", args[0])
+			return nil
+		},
+	})
+	return cmd
+}
+
+func newFavoriteCommand(cfg *config) *cobra.Command {
+	var remove bool
+	cmd := &cobra.Command{
+		Use:   "favorite [example]...",
+		Short: "Mark examples as favorites",
+		Long: `Favorites are offered first by completion and listed by examples --favorites.
+Without arguments favorite lists them; --remove unmarks the examples given.
+
+Examples:
+  decktool favorite deckviz/fire dubois/plate07
+  decktool favorite
+  decktool favorite --remove deckviz/fire`,
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				if remove {
+					return fmt.Errorf("--remove needs the examples to unmark")
+				}
+				if len(cfg.shortcuts.Favorites) == 0 {
+					fmt.Println("No favorites (decktool favorite <example>)")
+				}
+				for _, fav := range cfg.shortcuts.Favorites {
+					fmt.Println(fav)
+				}
+				return nil
+			}
+			for _, example := range args {
+				if err := cfg.setFavorite(example, !remove); err != nil {
+					return err
+				}
+				if remove {
+					fmt.Printf("✓ %s is no longer a favorite\n", cfg.normalizeExampleName(example))
+				} else {
+					fmt.Printf("✓ %s is a favorite\n", cfg.normalizeExampleName(example))
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&remove, "remove", false, "unmark the examples instead")
+	return cmd
+}
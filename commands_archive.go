@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Run snapshot commands
+
+func newArchiveCommand(cfg *config) *cobra.Command {
+	var label string
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Snapshot the last run's inputs and outputs into the archive",
+		Long: fmt.Sprintf(`Record the current run as an immutable snapshot in %s: the lockfile, the
+toolchain binaries' digests, the repo SHAs, the test report (metrics and
+pending visual diffs), the build summary and every rendered output.
+
+Files are stored content-addressed, so unchanged outputs cost nothing, and
+the snapshot id is the hash of its manifest. dev clean leaves the archive
+alone.
+
+Examples:
+  decktool test && decktool archive --label "before decksh bump"
+  decktool archive list
+  decktool archive diff 3f2a 9c41`, archiveDir),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, snap, err := cfg.createSnapshot(cmd.Context(), label)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Snapshot %s (%d files, %d tools, %d repos)\n", id, len(snap.Files), len(snap.Toolchain), len(snap.Repos))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&label, "label", "", "note stored with the snapshot")
+	cmd.AddCommand(newArchiveListCommand(cfg), newArchiveDiffCommand(cfg))
+	return cmd
+}
+
+func newArchiveListCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List snapshots, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, snaps, err := cfg.listSnapshots()
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				fmt.Println("No snapshots; create one with: decktool archive")
+				return nil
+			}
+			for _, id := range ids {
+				snap := snaps[id]
+				fmt.Printf("%s  %s  %4d files  %s\n", id, snap.Created.Local().Format(time.DateTime), len(snap.Files), snap.Label)
+			}
+			return nil
+		},
+	}
+}
+
+func newArchiveDiffCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <a> <b>",
+		Short: "Compare two snapshots (ids may be abbreviated)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idA, a, err := cfg.resolveSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			idB, b, err := cfg.resolveSnapshot(args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s (%s) → %s (%s)\n", idA, a.Created.Local().Format(time.DateTime), idB, b.Created.Local().Format(time.DateTime))
+			if n := diffSnapshots(os.Stdout, a, b); n > 0 {
+				fmt.Printf("%d difference(s)\n", n)
+			} else {
+				fmt.Println("✓ Identical")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Vulnerability audit command
+
+func newAuditCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit",
+		Short: "Scan the toolchain and decktool for known vulnerabilities",
+		Long: `Run govulncheck over every toolchain binary's package and decktool itself,
+in the .src workspace as checked out for dev build (the report records the
+source SHAs, lockfile pins first). Vulnerabilities whose code is called are
+listed with the binaries they affect; ones only imported or required are
+counted. govulncheck is installed into GOBIN when missing.
+
+The report is written to .dist/attestations/vulnerabilities.json, which
+dev release uploads with the binaries. The command fails when a called
+vulnerability is found, so CI can gate on it.
+
+Examples:
+  decktool dev build && decktool dev audit`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, path, err := cfg.auditToolchain(cmd.Context())
+			if err != nil {
+				return err
+			}
+			printVulnReport(report)
+			fmt.Printf("Report: %s\n", relToCwd(path))
+			if n := len(report.affected()); n > 0 {
+				return fmt.Errorf("called vulnerabilities found: %d", n)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// Shared cache commands
+
+func newCacheCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the multi-user shared cache (DECKTOOL_CACHE)",
+		Long: `Set DECKTOOL_CACHE to a group-writable directory shared by everyone on a build
+machine. Release binaries are downloaded into it once, checksummed, and hard
+linked into each .dist; repos cloned with full history (<REPO>_DEPTH=0) are
+fetched once into bare mirrors that each checkout borrows objects from. Writers take per-entry locks, so concurrent
+ensure and dev build runs are safe.
+
+Example setup:
+  sudo install -d -m 2775 -g devs /srv/decktool-cache
+  export DECKTOOL_CACHE=/srv/decktool-cache`,
+	}
+	cmd.AddCommand(newCacheVerifyCommand(cfg))
+	cmd.AddCommand(newCacheCleanCommand(cfg))
+	return cmd
+}
+
+func newCacheCleanCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clean",
+		Short: "Move the cached release binaries to the cache trash",
+		Long: `Move DECKTOOL_CACHE/releases into the cache's trash/, so the next ensure
+downloads the binaries again. The trash is kept for DECKTOOL_TRASH_DAYS
+(default 7) days; decktool restore brings it back. Repo mirrors are left
+alone: checkouts borrow their objects.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.cacheDir == "" {
+				return errors.New("DECKTOOL_CACHE is not set")
+			}
+			releases := filepath.Join(cfg.cacheDir, "releases")
+			if _, err := os.Stat(releases); err != nil {
+				fmt.Println("✓ Shared cache holds no releases")
+				return nil
+			}
+			trash := filepath.Join(cfg.cacheDir, "trash")
+			if err := mkdirShared(trash); err != nil {
+				return err
+			}
+			entry, err := moveToTrash(trash, releases, "cache clean")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Moved %s to trash; undo with decktool restore %s\n", releases, entry.ID)
+			return nil
+		},
+	}
+}
+
+func newCacheVerifyCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check every cached binary against its checksum and drop corrupt ones",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.cacheDir == "" {
+				return errors.New("DECKTOOL_CACHE is not set")
+			}
+			corrupt, err := cfg.verifySharedCache()
+			if err != nil {
+				return err
+			}
+			if corrupt > 0 {
+				return fmt.Errorf("%d corrupt cache entries removed; run ensure to download them again", corrupt)
+			}
+			fmt.Println("✓ Shared cache verified")
+			return nil
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Toolchain channel and rollback commands
+
+func newChannelCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "channel",
+		Short: "Show the release channel ensure follows",
+		Long: fmt.Sprintf(`Show the toolchain channel and the release it is pinned to.
+
+Channels: stable (latest full release), beta (latest prerelease such as
+v0.2.0-beta) and nightly (latest dev-* build from dev release). The channel
+is recorded in %s; every ensure moves the pin to the channel's newest
+release. DECKTOOL_CHANNEL overrides it for one shell or CI job. Without a
+channel, ensure uses the newest release of any kind.`, lockFileName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lock, err := loadLockFile()
+			if err != nil {
+				return err
+			}
+			channel, source := currentChannel(lock)
+			if channel == "" {
+				fmt.Println("No channel set; ensure uses the newest release of any kind")
+				return nil
+			}
+			fmt.Printf("Channel: %s (from %s)\n", channel, source)
+			if pin := lock.Toolchain; pin != nil && pin.Channel == channel && pin.Release != "" {
+				fmt.Printf("Pinned:  %s (updated %s)\n", pin.Release, pin.Updated.Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(newChannelSwitchCommand(cfg))
+	return cmd
+}
+
+func newChannelSwitchCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "switch <" + strings.Join(toolchainChannels, "|") + ">",
+		Short: "Follow another release channel and re-fetch the binaries",
+		Long: fmt.Sprintf(`Record the channel in %s, pin its newest release and download that
+release's binaries, replacing the current ones.
+
+Examples:
+  decktool channel switch nightly   # always track dev builds
+  decktool channel switch stable`, lockFileName),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: toolchainChannels,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cfg.switchChannel(cmd.Context(), args[0])
+		},
+	}
+}
+
+func newRollbackCommand(cfg *config) *cobra.Command {
+	var list, release bool
+	cmd := &cobra.Command{
+		Use:   "rollback [tag]",
+		Short: "Switch back to a previously installed toolchain",
+		Long: `Restore the binaries of an earlier release from the toolchain sets kept in
+.dist/toolchains (the last 3 installed by ensure; DECKTOOL_TOOLCHAIN_KEEP
+changes the count). Nothing is downloaded.
+
+Without a tag, rolls back to the set installed before the current one. The
+restored toolchain is held: ensure does not install newer releases until
+--release (or "channel switch").
+
+Examples:
+  decktool rollback --list
+  decktool rollback               # previous toolchain
+  decktool rollback dev-20251029-143052
+  decktool rollback --release     # let ensure update again`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case release:
+				return cfg.releaseToolchainHold()
+			case list:
+				sets, err := cfg.listToolchainSets()
+				if err != nil {
+					return err
+				}
+				if len(sets) == 0 {
+					fmt.Println("No toolchains kept yet (ensure keeps each release it installs)")
+					return nil
+				}
+				state := cfg.loadToolchainState()
+				for _, set := range sets {
+					mark := " "
+					if set.Tag == state.Current {
+						mark = "*"
+						if state.Held {
+							mark = "⊘"
+						}
+					}
+					fmt.Printf("%s %-24s installed %s  %d binaries\n", mark, set.Tag, set.Installed.Format("2006-01-02 15:04"), len(set.Files))
+				}
+				return nil
+			}
+			tag := ""
+			if len(args) > 0 {
+				tag = args[0]
+			}
+			return cfg.rollbackToolchain(tag)
+		},
+	}
+	cmd.Flags().BoolVar(&list, "list", false, "list the kept toolchains (* current, ⊘ held)")
+	cmd.Flags().BoolVar(&release, "release", false, "release the hold so ensure installs new releases again")
+	return cmd
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Deck palette command
+
+func newColorsCommand(cfg *config) *cobra.Command {
+	var include string
+	cmd := &cobra.Command{
+		Use:   "colors <example>",
+		Short: "List a deck's color palette and flag low-contrast text",
+		Long: `Render the example and list the colors its slides use (named, #rgb and
+rgb() values resolve to the same color), with how often and for what:
+background, text, fill or gradient. On a color terminal each color gets an
+approximate swatch (NO_COLOR turns them off).
+
+Text whose contrast with its slide background is below WCAG AA (4.5:1, or
+3:1 for large text) is listed with the slides it appears on.
+
+--include writes the palette as a decksh file of color variables
+(color1="#4682b4" ...), most used first, to include from other decks.
+
+Examples:
+  decktool colors deckviz/fire
+  decktool colors deckviz/fire --include palette.dsh`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := cfg.ensureBins(ctx); err != nil {
+				return err
+			}
+			if err := cfg.ensureReposFor(ctx, args[:1]); err != nil {
+				return err
+			}
+			example := cfg.normalizeExampleName(args[0])
+			xmlPath, err := cfg.renderExample(ctx, example)
+			if err != nil {
+				return err
+			}
+			palette, err := extractPalette(xmlPath)
+			if err != nil {
+				return err
+			}
+			printPalette(example, palette, terminalColors())
+			if include == "" {
+				return nil
+			}
+			f, err := os.Create(include)
+			if err != nil {
+				return err
+			}
+			if err := writePaletteInclude(f, example, palette); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Palette written to %s (include \"%s\")\n", include, include)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&include, "include", "", "write the palette as a decksh include file")
+	return cmd
+}
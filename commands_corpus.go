@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Corpus pinning commands
+
+func newCorpusCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "corpus",
+		Short: "Manage the pinned example corpus used by test",
+	}
+	cmd.AddCommand(newCorpusUpdateCommand(cfg))
+	return cmd
+}
+
+func newCorpusUpdateCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update",
+		Short: "Roll the corpus forward and refresh the test baseline",
+		Long: fmt.Sprintf(`Update the data repos to their branch heads, record the SHAs in %s,
+and re-render the corpus to record which examples currently fail.
+
+Commit %s so test runs use the same corpus until the next update.
+
+Examples:
+  decktool corpus update`, lockFileName, lockFileName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := cfg.ensureBins(ctx); err != nil {
+				return err
+			}
+			lock, err := loadLockFile() // after ensure, which may move the channel pin
+			if err != nil {
+				return err
+			}
+			if err := cfg.ensureRepos(ctx); err != nil {
+				return err
+			}
+			if err := cfg.recordCorpusPins(ctx, lock); err != nil {
+				return err
+			}
+
+			fmt.Println("Rendering corpus to refresh the baseline...")
+			results, err := cfg.testCorpus(ctx)
+			if err != nil {
+				return err
+			}
+			lock.Baseline = corpusBaseline{Updated: time.Now().UTC()}
+			for _, result := range results {
+				if result.err != nil {
+					lock.Baseline.Failing = append(lock.Baseline.Failing, result.name)
+				}
+			}
+			if err := lock.save(); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Updated %s: %d examples, %d failing in baseline\n",
+				lockFileName, len(results), len(lock.Baseline.Failing))
+			return nil
+		},
+	}
+}
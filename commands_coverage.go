@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Language coverage command (decktool test coverage)
+
+func newTestCoverageCommand(cfg *config) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Report which decksh keywords the corpus and feature decks exercise",
+		Long: `Read the keywords the decksh parser accepts from its source (checked out
+in .src/decksh, cloned if missing) and report which of them the corpus and
+the feature decks use. Keywords only the corpus exercises, and keywords
+nothing exercises, are candidates for new feature decks.
+
+Examples:
+  decktool test coverage
+  decktool test coverage --json > coverage.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if asJSON {
+				ctx = withToolOutput(ctx, os.Stderr) // keep stdout for the JSON
+			}
+			if err := cfg.ensureRepos(ctx); err != nil {
+				return err
+			}
+			report, err := cfg.languageCoverage(ctx)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+			printCoverage(os.Stdout, report)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the coverage of every keyword as JSON")
+	return cmd
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Data connector commands
+
+func newDataCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data",
+		Short: "Fetch the data files decks render from (SQLite, CSV and JSON endpoints)",
+		Long: `Data connectors keep a deck's data files (dchart input and the like) current
+from a SQLite query or an HTTP endpoint. Configure them per example in
+decktool.json:
+
+  "data": {
+    "ttl": "1h",
+    "sources": [
+      { "deck": "sales/quarterly", "file": "revenue.d",
+        "sql": "select region, sum(total) from orders group by 1", "db": "sales.db" },
+      { "deck": "sales/quarterly", "file": "targets.d", "http": "https://bi.example.com/targets",
+        "fields": ["region", "target"], "ttl": "24h" }
+    ]
+  }
+
+Files are written tab separated (the format dchart reads), or as CSV when
+the name ends in .csv. Results are cached in .dist/data for the source's
+ttl (default 1h); run, test and the other render commands pull before
+rendering, so a deck regenerates with fresh data from one command.`,
+	}
+	cmd.AddCommand(newDataPullCommand(cfg))
+	return cmd
+}
+
+func newDataPullCommand(cfg *config) *cobra.Command {
+	var src dataSource
+	var output, ttl string
+	var refresh bool
+	cmd := &cobra.Command{
+		Use:   "pull [example]...",
+		Short: "Fetch configured data files, or run one connector ad hoc",
+		Long: `Fetch the configured data files of the given examples (all configured
+examples when none are given), reusing cached results younger than their ttl
+unless --refresh is given.
+
+With --sql or --http, run that connector once and write the result to -o.
+SQL queries run read-only through the sqlite3 shell (SQLITE3 overrides it).
+HTTP responses are parsed by Content-Type unless --format is given; JSON
+must be an array of arrays, or of objects with --fields naming the columns.
+
+Examples:
+  decktool data pull
+  decktool data pull sales/quarterly --refresh
+  decktool data pull --sql "select name, value from metrics" --db metrics.db -o metrics.d
+  decktool data pull --http https://api.example.com/stats --fields name,count -o stats.d`,
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if src.SQL != "" || src.HTTP != "" {
+				if len(args) > 0 {
+					return errors.New("examples cannot be combined with --sql or --http")
+				}
+				if output == "" {
+					return errors.New("-o is required with --sql or --http")
+				}
+				src.File, src.TTL = filepath.Base(output), ttl
+				if err := src.validate(); err != nil {
+					return err
+				}
+				fetched, err := cfg.pullData(ctx, src, output, src.ttl(cfg.file.Data.TTL), refresh)
+				if err != nil {
+					return err
+				}
+				printPulled(output, src, fetched)
+				return nil
+			}
+
+			sources := cfg.file.Data.Sources
+			if len(sources) == 0 {
+				return errors.New(`no data sources configured (see the "data" section in decktool data --help)`)
+			}
+			var examples []string
+			for _, arg := range args {
+				examples = append(examples, cfg.normalizeExampleName(arg))
+			}
+			// Repos of the decks pulled for are cloned as they come up
+			if err := cfg.syncDataRepos(ctx, func(*repoConfig) bool { return false }); err != nil {
+				return err
+			}
+			pulled := 0
+			for _, s := range sources {
+				if len(examples) > 0 && !slices.Contains(examples, s.Deck) {
+					continue
+				}
+				if err := cfg.materializeExample(ctx, s.Deck); err != nil {
+					return err
+				}
+				source, name := cfg.parseExample(s.Deck)
+				dir, err := cfg.getExampleDir(source, name)
+				if err != nil {
+					return err
+				}
+				out := filepath.Join(dir, s.File)
+				fetched, err := cfg.pullData(ctx, s, out, s.ttl(cfg.file.Data.TTL), refresh)
+				if err != nil {
+					return fmt.Errorf("pull %s for %s: %w", s.File, s.Deck, err)
+				}
+				printPulled(s.Deck+"/"+s.File, s, fetched)
+				pulled++
+			}
+			if pulled == 0 {
+				return fmt.Errorf("no data sources configured for %v", args)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "fetch even when a cached result is still fresh")
+	cmd.Flags().StringVar(&src.SQL, "sql", "", "SQLite query to run (with --db)")
+	cmd.Flags().StringVar(&src.DB, "db", "", "SQLite database for --sql")
+	cmd.Flags().StringVar(&src.HTTP, "http", "", "URL returning CSV, TSV or JSON")
+	cmd.Flags().StringVar(&src.Format, "format", "", "response format: csv, tsv or json (default: from Content-Type)")
+	cmd.Flags().BoolVar(&src.Header, "header", false, "drop the first CSV/TSV row")
+	cmd.Flags().StringSliceVar(&src.Fields, "fields", nil, "JSON object keys to write as columns, in order")
+	cmd.Flags().StringVar(&ttl, "ttl", "", "reuse a cached result younger than this (default 1h)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write with --sql or --http")
+	cmd.MarkFlagsMutuallyExclusive("sql", "http")
+	return cmd
+}
+
+func printPulled(file string, src dataSource, fetched bool) {
+	if fetched {
+		fmt.Printf("✓ %s pulled from %s (%s)\n", file, src.describe(), time.Now().Format(time.TimeOnly))
+		return
+	}
+	fmt.Printf("✓ %s up to date (cached)\n", file)
+}
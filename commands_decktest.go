@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Deck test command (shard and coverage subcommands in commands_shard.go
+// and commands_coverage.go)
+
+func newTestCommand(cfg *config) *cobra.Command {
+	var featuresOnly, notify bool
+	var status, prComment, reportURL string
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Render and measure the feature decks and the example corpus",
+		Long: `Lint and render the embedded feature decks (one tiny deck per decksh
+construct) and every example in the corpus, reporting failures per suite.
+
+A failing feature deck points at the language feature that broke; a failing
+corpus example shows which real-world deck is affected.
+
+When decktool.lock pins the data repos (see "corpus update"), the corpus is
+checked out at those SHAs and only failures outside the recorded baseline
+fail the run.
+
+Each deck is also converted to PDF and measured (XML elements and size, PDF
+size, pages, font objects); the report flags output growth compared with the
+last run of a different toolchain.
+
+Every rendered deck must pass the property checks (no element outside the
+canvas, no zero-size text, no missing images, no NaN/Inf values; tuned in
+the "checks" section of decktool.json); "run" reports the same as warnings.
+
+Slides are rendered to PNG and compared with golden images (recorded on the
+first run of each deck); differences fail the deck and can be reviewed with
+"decktool test approve".
+
+--status <repo>[@<sha>] publishes the result as a commit status
+("decktool/corpus") on that GitHub repo's commit, by default the HEAD of its
+checkout, with --report-url as the details link. It uses gh's token
+(GH_TOKEN or gh auth login).
+
+--pr-comment <repo>#<number> posts a summary on that pull request of the
+repo's upstream: pass rate, failing decks, slides that differ from their
+goldens (diff thumbnails when --report-url serves the .test directory) and
+render time against the previous toolchain. Later runs update the same
+comment instead of adding new ones.
+
+--workers splits the corpus into one shard per worker and merges their
+results: "local" renders here, ssh://host[/dir] runs the decktool clone at
+dir (default ~/decktool-build/<workspace name>) with go run, and
+http(s)://host:port asks a "decktool serve" daemon (token from
+DECKTOOL_WORKER_TOKEN). Workers check the data repos out at this machine's
+SHAs; a failed worker's shard is rendered locally. Golden images are only
+compared for shards rendered here.
+
+--notify posts the result (pass rate, failing decks, slides that differ
+from their goldens, --report-url) to the sinks in the "notify" section of
+decktool.json, as scheduled jobs do; use it for CI runs.
+
+Every run records the inputs (files in the example directory) and toolchain
+(dshlint, decksh, pdfdeck, pngdeck) each corpus example passed with in
+.test/snapshot.json. --changed-only re-renders only examples whose inputs or
+toolchain changed since their last pass, or that have not passed yet; the
+others count as passed.
+
+After a run with corpus failures at a terminal, decktool offers to triage
+them: each failure is shown with its error, the end of the tool output and
+the .dsh lines the output points at, and can be retried, opened in
+$VISUAL/$EDITOR, recorded as a known failure in the decktool.lock baseline,
+or written up as a report in .test/triage (with gh, a prefilled decksh issue
+opens in the browser).
+
+Examples:
+  decktool test
+  decktool test --features-only
+  decktool test --changed-only
+  decktool test --status decksh@3f2a9c1 --report-url https://ci.example.com/run/42
+  decktool test --pr-comment decksh#42 --report-url https://ci.example.com/run/42
+  decktool test --notify --report-url https://ci.example.com/run/42
+  decktool test --workers local,ssh://render1,http://render2:8080`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			// Triage is offered after a failing run at the terminal
+			cfg.triage = interactiveTerminal()
+			if err := cfg.ensureBins(ctx); err != nil {
+				return err
+			}
+			lock, err := loadLockFile()
+			if err != nil {
+				return err
+			}
+			if !featuresOnly {
+				if len(lock.Repos) > 0 {
+					fmt.Printf("Using corpus pinned in %s\n", lockFileName)
+				}
+				if err := cfg.ensurePinnedRepos(ctx, lock); err != nil {
+					return err
+				}
+			}
+			var target *commitStatusTarget
+			if status != "" {
+				// Resolve before the run so a bad target fails fast
+				if target, err = cfg.resolveStatusTarget(ctx, status); err != nil {
+					return err
+				}
+			}
+			var pr *prTarget
+			if prComment != "" {
+				if pr, err = cfg.resolvePRTarget(prComment); err != nil {
+					return err
+				}
+			}
+			summary, err := cfg.runDeckTests(ctx, featuresOnly, lock.knownFailures())
+			if target != nil {
+				if perr := cfg.publishCommitStatus(ctx, *target, summary, err, reportURL); perr != nil {
+					fmt.Printf("⚠ Commit status not published: %v\n", perr)
+				}
+			}
+			if pr != nil {
+				if perr := cfg.publishPRComment(ctx, *pr, summary, err, reportURL); perr != nil {
+					fmt.Printf("⚠ PR comment not published: %v\n", perr)
+				}
+			}
+			if notify {
+				cfg.notify(ctx, testNotification(summary, err, reportURL))
+			}
+			if cfg.triage {
+				cfg.offerTriage(ctx, summary.triage, lock)
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&featuresOnly, "features-only", false, "only render the embedded feature decks")
+	cmd.Flags().StringVar(&status, "status", "", "publish the result as a commit status on <repo>[@<sha>]")
+	cmd.Flags().StringVar(&prComment, "pr-comment", "", "post or update a summary comment on upstream PR <repo>#<number>")
+	cmd.Flags().StringSliceVar(&cfg.corpusWorkers, "workers", nil, "render corpus shards on these workers (local, ssh://host[/dir], http(s)://host:port)")
+	cmd.Flags().BoolVar(&cfg.changedOnly, "changed-only", false, "only re-test corpus examples whose inputs or toolchain changed since their last pass")
+	cmd.MarkFlagsMutuallyExclusive("changed-only", "workers")
+	cmd.Flags().StringVar(&reportURL, "report-url", "", "details link (and diff image base URL) for published results")
+	cmd.Flags().BoolVar(&notify, "notify", false, "post the result to the notification sinks in decktool.json")
+	cmd.AddCommand(newTestApproveCommand(cfg))
+	cmd.AddCommand(newTestShardCommand(cfg))
+	cmd.AddCommand(newTestCoverageCommand(cfg))
+	return cmd
+}
+
+func newTestApproveCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve",
+		Short: "Review visual differences and accept or reject new baselines",
+		Long: `Open a local web page that steps through the slides that differed from
+their golden images in the last test run, showing before, after and a diff.
+Each new rendering can be accepted as the baseline or rejected individually.
+
+Examples:
+  decktool test approve`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cfg.approveDiffs(cmd.Context())
+		},
+	}
+}
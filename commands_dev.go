@@ -2,164 +2,159 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
 
-// Dev commands
-
-// Dev commands
+// Dev build command (decktool dev build; release and clean in
+// commands_devrelease.go and commands_devclean.go)
 
 func newDevBuildCommand(cfg *config) *cobra.Command {
+	var compareLast bool
+	var verifyReproducible bool
+	var fullPaths bool
+	var frozen bool
+	var noSync bool
+	var remote string
+	var remoteExisting bool
+	var ref string
+
 	cmd := &cobra.Command{
-		Use:   "dev-build",
+		Use:   "build",
 		Short: "Build all deck binaries for native, WASM, and WASI targets",
 		Long: `Build all deck binaries for all targets (native, wasm, wasi).
 
+Binaries are built with -trimpath and without VCS stamping so they do not
+embed workspace paths; use --full-paths (or "build" in decktool.json) to debug.
+
+Each run records a summary (status, size, duration per artifact, flags of
+each native tool) so the next run can highlight new failures, size or
+build-time regressions and upstream CLI changes.
+
+The Go modules resolved for the toolchain (versions and go.sum hashes) are
+recorded in the build summary and in decktool.lock. --frozen refuses to
+build when resolution differs from decktool.lock, e.g. after a repo sync
+pulled in a new transitive dependency. It is on by default once the lock
+records a module graph; --frozen=false (or "dev": {"unlocked": true})
+accepts the changes and rewrites the lock.
+
+--remote ssh://[user@]host[:port][/dir] builds on a builder host instead:
+the workspace (decktool and the code repos as checked out here, local edits
+included) is copied to dir (default ~/decktool-build/<workspace name>) and
+built there, and the artifacts and logs are copied back into .dist. Use it
+when this machine is slow or lacks the OS for the cgo viewers; native
+binaries are named for the builder's platform. --remote-existing builds the
+clone already at dir instead, letting it sync its own repos. The builder
+needs sh, tar, git and Go; ssh runs non-interactively (keys or an agent).
+
+--ref <repo>@<ref> builds the native tools with one code repo checked out at
+another branch, tag, SHA or PR ref (a worktree, see "dev worktree") into
+.dist/refs/<repo>@<ref>, leaving .dist and the primary checkout untouched.
+
 Examples:
-  decktool dev-build`,
+  decktool dev build
+  decktool dev build --frozen=false          # accept module changes, rewrite the lock
+  decktool dev build --remote ssh://builder.local
+  decktool dev build --ref decksh@pull/42/head
+  decktool dev build --compare-last
+  decktool dev build --verify-reproducible   # build twice, compare hashes`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
+			if !cmd.Flags().Changed("frozen") {
+				var err error
+				if frozen, err = cfg.defaultFrozen(); err != nil {
+					return err
+				}
+			}
+			if remote != "" {
+				var flags []string
+				if fullPaths {
+					flags = append(flags, "--full-paths")
+				}
+				flags = append(flags, fmt.Sprintf("--frozen=%t", frozen))
+				return cfg.remoteBuild(ctx, remote, remoteExisting, flags)
+			}
 
 			// Ensure repos are synced
-			fmt.Println("Syncing build repositories...")
-			if err := cfg.ensureBuildRepos(ctx); err != nil {
-				return fmt.Errorf("sync build repos: %w", err)
+			if noSync {
+				fmt.Println("Building the repositories as checked out (--no-sync)")
+			} else {
+				fmt.Println("Syncing build repositories...")
+				if err := cfg.ensureBuildRepos(ctx); err != nil {
+					return fmt.Errorf("sync build repos: %w", err)
+				}
 			}
 			fmt.Println("Creating go.work workspace...")
 			if err := cfg.ensureWorkspace(ctx); err != nil {
 				return fmt.Errorf("create workspace: %w", err)
 			}
 
+			if fullPaths {
+				cfg.file.Build.FullPaths = true
+			}
+			if ref != "" {
+				return cfg.buildRef(ctx, ref)
+			}
+			modules, err := cfg.pinModuleGraph(ctx, frozen)
+			if err != nil {
+				return err
+			}
+
 			// Build all targets to dist directory
 			buildTargets := []buildTarget{targetNative, targetWASM, targetWASI}
 
+			if verifyReproducible {
+				fmt.Println("Verifying reproducibility (each artifact is built twice)...")
+				return cfg.verifyReproducible(ctx, buildTargets)
+			}
+
 			fmt.Printf("Building %d binaries for targets: %v\n", len(cfg.toolchain), buildTargets)
 			results, err := cfg.buildAll(ctx, buildTargets, cfg.distDir)
 			if err != nil {
 				return err
 			}
 
-			// Report results
-			fmt.Println("\n=== Build Results ===")
-			successes := 0
-			failures := 0
-			skipped := 0
-			for _, result := range results {
-				if result.err != nil {
-					if strings.Contains(result.err.Error(), "not supported") {
-						fmt.Printf("⊘ %s: %v\n", result.binary, result.err)
-						skipped++
-					} else {
-						fmt.Printf("✗ %s: %v\n", result.binary, result.err)
-						failures++
-					}
-				} else {
-					fmt.Printf("✓ %s\n", result.path)
-					successes++
+			// Report results and compare against the previous run
+			failures := printBuildResults(results)
+			summary := cfg.summarizeBuild(results)
+			summary.CLI = cfg.captureCLISurface(ctx)
+			summary.Modules = modules
+			if compareLast {
+				prev, err := cfg.loadBuildSummary()
+				if err != nil {
+					return err
 				}
+				cfg.printBuildComparison(prev, summary)
 			}
-			fmt.Printf("\nTotal: %d succeeded, %d failed, %d skipped\n", successes, failures, skipped)
-
-			if failures > 0 {
-				return fmt.Errorf("some builds failed")
+			if err := cfg.saveBuildSummary(summary); err != nil {
+				return fmt.Errorf("save build summary: %w", err)
 			}
 
-			return nil
-		},
-	}
-	return cmd
-}
-
-func newDevReleaseCommand(cfg *config) *cobra.Command {
-	var skipBuild bool
-	var prerelease bool
-	var version string
-
-	cmd := &cobra.Command{
-		Use:   "dev-release",
-		Short: "Create a GitHub release with built binaries",
-		Long: `Create a GitHub release and upload all binaries from dist/ directory.
-
-By default, creates a timestamped prerelease (e.g., dev-20251029-143052).
-Use --version to specify a custom version tag.
-
-Examples:
-  decktool dev-release                           # Auto-timestamped prerelease
-  decktool dev-release --version=v0.1.0          # Official release
-  decktool dev-release --version=v0.1.0-beta     # Beta prerelease
-  decktool dev-release --skip-build              # Use existing dist/ binaries`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Context()
-
-			// Build all binaries unless skipped
-			if !skipBuild {
-				fmt.Println("Building all binaries...")
-				buildTargets := []buildTarget{targetNative, targetWASM, targetWASI}
-				results, err := cfg.buildAll(ctx, buildTargets, cfg.distDir)
-				if err != nil {
-					return fmt.Errorf("build failed: %w", err)
-				}
-
-				// Check for build failures
-				failCount := 0
-				for _, result := range results {
-					if result.err != nil && !strings.Contains(result.err.Error(), "not supported") {
-						failCount++
-					}
-				}
-				if failCount > 0 {
-					return fmt.Errorf("some builds failed, cannot create release")
+			var built []string
+			for _, result := range results {
+				if result.status() == "ok" {
+					built = append(built, result.path)
 				}
-				fmt.Println("✓ Build completed")
 			}
-
-			// Generate version if not specified
-			if version == "" {
-				version = cfg.generateReleaseVersion()
-				prerelease = true
-			}
-
-			// Create GitHub release
-			return cfg.createGithubRelease(ctx, version, prerelease)
-		},
-	}
-	cmd.Flags().BoolVar(&skipBuild, "skip-build", false, "skip building binaries, use existing dist/ files")
-	cmd.Flags().BoolVar(&prerelease, "prerelease", false, "mark as prerelease (default for auto-versioned releases)")
-	cmd.Flags().StringVar(&version, "version", "", "version tag (default: auto-generated timestamp)")
-	return cmd
-}
-
-func newDevCleanCommand(cfg *config) *cobra.Command {
-	return &cobra.Command{
-		Use:   "dev-clean",
-		Short: "Remove all dot folders (.data, .src, .dist, .fonts) for fresh start",
-		Long: `Remove all cached data folders including repositories, source code, built binaries, and fonts.
-
-This is useful for starting fresh or troubleshooting issues.
-
-Examples:
-  decktool dev-clean`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// Remove dot folders defined in config
-			folders := []string{cfg.distDir, cfg.fontsDir}
-			for _, repo := range cfg.repos {
-				folders = append(folders, repo.dir)
+			if err := cfg.checkSizeBudgets(built); err != nil {
+				return err
 			}
 
-			for _, folder := range folders {
-				if _, err := os.Stat(folder); err == nil {
-					fmt.Printf("Removing %s...\n", folder)
-					if err := os.RemoveAll(folder); err != nil {
-						return fmt.Errorf("failed to remove %s: %w", folder, err)
-					}
-				}
+			if failures > 0 {
+				return fmt.Errorf("some builds failed")
 			}
 
-			fmt.Println("✓ All dot folders removed")
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&compareLast, "compare-last", false, "compare results with the previous build")
+	cmd.Flags().BoolVar(&frozen, "frozen", false, "fail if Go module resolution differs from "+lockFileName)
+	cmd.Flags().BoolVar(&fullPaths, "full-paths", false, "keep absolute source paths in binaries (debug builds)")
+	cmd.Flags().BoolVar(&noSync, "no-sync", false, "build the code repos as checked out, without pulling or patching")
+	cmd.Flags().StringVar(&remote, "remote", "", "build on a builder host (ssh://[user@]host[:port][/dir]) and fetch the artifacts")
+	cmd.Flags().BoolVar(&remoteExisting, "remote-existing", false, "with --remote, build the clone already on the builder instead of copying this workspace")
+	cmd.Flags().StringVar(&ref, "ref", "", "build native tools with <repo>@<ref> checked out in a worktree")
+	cmd.Flags().BoolVar(&verifyReproducible, "verify-reproducible", false, "build each artifact twice and report differing hashes (dist is untouched)")
+	addDirtyFlags(cmd, cfg)
+	return cmd
 }
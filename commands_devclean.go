@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Dev clean command (decktool dev clean)
+
+func newDevCleanCommand(cfg *config) *cobra.Command {
+	var permanent bool
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove all dot folders (.data, .src, .dist, .fonts, .test, .jobs) for fresh start",
+		Long: `Remove all cached data folders including repositories, source code, built binaries, and fonts.
+
+This is useful for starting fresh or troubleshooting issues. The folders are
+moved into .trash rather than deleted, and kept there for DECKTOOL_TRASH_DAYS
+(default 7) days: a .src checkout with unpushed work can be brought back with
+decktool restore. --permanent deletes them outright. The repo mirrors
+(~/.decktool/mirrors) are kept, so the next ensure clones from them.
+
+Examples:
+  decktool dev clean
+  decktool restore`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Remove dot folders defined in config
+			folders := []string{cfg.distDir, cfg.fontsDir, cfg.testDir, cfg.jobsDir}
+			for _, repo := range cfg.repos {
+				folders = append(folders, repo.dir)
+			}
+
+			for _, folder := range folders {
+				if _, err := os.Stat(folder); err != nil {
+					continue
+				}
+				if permanent {
+					fmt.Printf("Removing %s...\n", folder)
+					if err := os.RemoveAll(folder); err != nil {
+						return fmt.Errorf("failed to remove %s: %w", folder, err)
+					}
+					continue
+				}
+				entry, err := moveToTrash(cfg.trashDir, folder, "dev clean")
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Moved %s to trash (%s)\n", folder, entry.ID)
+			}
+
+			if permanent {
+				fmt.Println("✓ All dot folders removed")
+			} else {
+				fmt.Println("✓ All dot folders moved to .trash; bring one back with decktool restore <id>")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&permanent, "permanent", false, "delete the folders instead of moving them to the trash")
+	return cmd
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Dev release command (decktool dev release)
+
+func newDevReleaseCommand(cfg *config) *cobra.Command {
+	var skipBuild bool
+	var prerelease bool
+	var version string
+
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Create a GitHub release with built binaries",
+		Long: `Create a GitHub release and upload all binaries from dist/ directory.
+
+By default, creates a timestamped prerelease (e.g., dev-20251029-143052).
+Use --version to specify a custom version tag.
+
+A SLSA provenance statement (provenance.intoto.jsonl) recording the builder,
+source SHAs and build parameters is uploaded with the binaries; ensure and
+"release verify" check downloads against it. The provenance is signed with
+DECKTOOL_SIGNING_KEY (default ~/.decktool/signing.key, created on first use).
+Once published, the release is downloaded and verified against it like
+"release verify" does; "dev": {"no_verify": true} skips that.
+
+The govulncheck report of the toolchain (vulnerabilities.json, see "dev audit")
+is uploaded too and summarized in the release notes, as is NOTICES with the
+licenses of every linked module (see "dev licenses"); a denied license blocks
+the release.
+
+Examples:
+  decktool dev release                           # Auto-timestamped prerelease
+  decktool dev release --version=v0.1.0          # Official release
+  decktool dev release --version=v0.1.0-beta     # Beta prerelease
+  decktool dev release --skip-build              # Use existing dist/ binaries`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			started := time.Now()
+
+			// Build all binaries unless skipped
+			if !skipBuild {
+				fmt.Println("Building all binaries...")
+				buildTargets := []buildTarget{targetNative, targetWASM, targetWASI}
+				results, err := cfg.buildAll(ctx, buildTargets, cfg.distDir)
+				if err != nil {
+					return fmt.Errorf("build failed: %w", err)
+				}
+
+				// Check for build failures
+				failCount := 0
+				for _, result := range results {
+					if result.status() == "failed" {
+						failCount++
+					}
+				}
+				if failCount > 0 {
+					return fmt.Errorf("some builds failed, cannot create release")
+				}
+				fmt.Println("✓ Build completed")
+			}
+
+			// Generate version if not specified
+			if version == "" {
+				version = cfg.generateReleaseVersion()
+				prerelease = true
+			}
+
+			// Create GitHub release
+			if err := cfg.createGithubRelease(ctx, version, prerelease, started); err != nil {
+				return err
+			}
+			if !cfg.verifiedReleases() {
+				return nil
+			}
+			fmt.Printf("Verifying published release %s...\n", version)
+			if err := cfg.verifyRelease(ctx, version); err != nil {
+				return fmt.Errorf("release %s is published but does not verify: %w", version, err)
+			}
+			fmt.Printf("✓ %s matches its provenance\n", version)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&skipBuild, "skip-build", false, "skip building binaries, use existing dist/ files")
+	cmd.Flags().BoolVar(&prerelease, "prerelease", false, "mark as prerelease (default for auto-versioned releases)")
+	cmd.Flags().StringVar(&version, "version", "", "version tag (default: auto-generated timestamp)")
+	return cmd
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Dist inspection commands
+
+func newDistCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dist",
+		Short: "Inspect built artifacts in the dist directory",
+	}
+	cmd.AddCommand(newDistLogsCommand(cfg))
+	return cmd
+}
+
+func newDistLogsCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logs [artifact]",
+		Short: "Show the full build log for an artifact",
+		Long: `Show the captured go build output for one artifact from the last dev build.
+
+Examples:
+  decktool dev dist logs decksh-wasm.wasm
+  decktool dev dist logs gcdeck-linux-amd64`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return cfg.listBuildLogs(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cfg.printBuildLog(args[0])
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Embed command
+
+func newEmbedCommand(cfg *config) *cobra.Command {
+	var format, baseURL string
+	cmd := &cobra.Command{
+		Use:   "embed <example>",
+		Short: "Print a snippet embedding an example's gallery page",
+		Long: `Print an HTML snippet (an iframe of the example's published gallery page,
+falling back to its linked thumbnail) or, with --format markdown, a linked
+thumbnail for READMEs, where iframes are not rendered.
+
+The gallery location is --base-url, DECKTOOL_GALLERY_URL, or the GitHub
+Pages site of the origin remote (where gallery --deploy gh-pages publishes).
+
+Examples:
+  decktool embed deckviz/fire
+  decktool embed deckviz/fire --format markdown
+  decktool embed deckviz/fire --base-url https://decks.example.com`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := cfg.exampleCatalog()
+			if err != nil {
+				return err
+			}
+			source, name := cfg.parseExample(args[0])
+			id := catalogID(source + "/" + name)
+			for _, e := range entries {
+				if e.ID != id {
+					continue
+				}
+				if baseURL == "" {
+					if baseURL, err = cfg.galleryBaseURL(cmd.Context()); err != nil {
+						return err
+					}
+				}
+				snippet, err := embedSnippet(e, strings.TrimSuffix(baseURL, "/"), format)
+				if err != nil {
+					return err
+				}
+				fmt.Println(snippet)
+				return nil
+			}
+			return fmt.Errorf("example %q not found (see decktool examples)", args[0])
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "html", "snippet format: html or markdown")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "URL the gallery is published at")
+	return cmd
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Examples command
+
+func newExamplesCommand(cfg *config) *cobra.Command {
+	var favorites bool
+	var porcelainFormat string
+	cmd := &cobra.Command{
+		Use:   "examples",
+		Short: "List available examples",
+		Long: `List every example as source/name.
+
+--porcelain prints an example record per example, or favorite and alias
+records with --favorites (see decktool which --help for the format).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := startPorcelain(porcelainFormat)
+			if err != nil {
+				return err
+			}
+			if favorites {
+				// Favorites first, then aliases with what they point at
+				for _, fav := range cfg.shortcuts.Favorites {
+					if p != nil {
+						p.record("favorite", fav)
+						continue
+					}
+					fmt.Println(fav)
+				}
+				for _, name := range cfg.shortcuts.aliasNames() {
+					if p != nil {
+						p.record("alias", name, cfg.shortcuts.Aliases[name])
+						continue
+					}
+					fmt.Printf("%s -> %s\n", name, cfg.shortcuts.Aliases[name])
+				}
+				return nil
+			}
+			if err := cfg.ensureRepos(cmd.Context()); err != nil {
+				return err
+			}
+			examples, err := cfg.listExamples()
+			if err != nil {
+				return err
+			}
+			for _, ex := range examples {
+				if p != nil {
+					source, name := cfg.parseExample(ex)
+					dir, _ := cfg.getExampleDir(source, name)
+					p.record("example", ex, dir)
+					continue
+				}
+				fmt.Println(ex)
+			}
+			return nil
+		},
+		ValidArgsFunction: cfg.exampleCompletion,
+	}
+	addPorcelainFlag(cmd, &porcelainFormat)
+	cmd.Flags().BoolVar(&favorites, "favorites", false, "list only your favorites and aliases (see decktool favorite and alias)")
+	cmd.AddCommand(newExamplesExportListCommand(cfg))
+	return cmd
+}
+
+func newExamplesExportListCommand(cfg *config) *cobra.Command {
+	var format, output string
+	cmd := &cobra.Command{
+		Use:   "export-list",
+		Short: "Export the example catalog as JSON or YAML",
+		Long: `Export every example with a stable id (derived from its name), title,
+tags (source and decksh constructs used), slide count, script and data
+files, and thumbnail (first PNG page, once rendered) for docs sites and the
+gallery generator. Reads the repos as last synced; run ensure first.
+
+Examples:
+  decktool examples export-list > examples.json
+  decktool examples export-list --format yaml -o site/data/examples.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := cfg.exampleCatalog()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return fmt.Errorf("no examples found (run ensure first)")
+			}
+			w := os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return writeCatalog(w, entries, format)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "output format: json or yaml")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to file instead of stdout")
+	return cmd
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Fuzzing command
+
+func newFuzzCommand(cfg *config) *cobra.Command {
+	var duration, timeout time.Duration
+	var seed uint64
+	var goFuzz bool
+	cmd := &cobra.Command{
+		Use:   "fuzz",
+		Short: "Mutate corpus decks to find inputs that crash or hang decksh or pdfdeck",
+		Long: `Mutate the corpus and feature decks (deleted, duplicated and swapped
+lines, boundary numbers, spliced keywords, unbalanced quotes, truncation) and
+run each input through decksh and pdfdeck from its example directory. An
+input that panics, dies from a signal or runs past --timeout is minimized
+line by line and saved with a report to .test/crashers/; ordinary errors on
+malformed decks are expected and ignored. Each distinct failure is saved
+once, so reruns only add new ones.
+
+--go instead runs Go's native fuzzer over decksh.Process in the .src/decksh
+checkout (cloned if missing), seeded with the same decks; the generated
+harness is removed afterwards.
+
+Examples:
+  decktool dev fuzz --duration 10m
+  decktool dev fuzz --seed 42 --timeout 5s
+  decktool dev fuzz --go --duration 30m`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := cfg.ensureBins(ctx); err != nil {
+				return err
+			}
+			if err := cfg.ensureRepos(ctx); err != nil {
+				return err
+			}
+			seeds, err := cfg.fuzzSeeds()
+			if err != nil {
+				return err
+			}
+			var stats fuzzStats
+			if goFuzz {
+				stats, err = cfg.goFuzzDecksh(ctx, seeds, duration)
+			} else {
+				if seed == 0 {
+					seed = uint64(time.Now().UnixNano())
+				}
+				stats, err = cfg.fuzzDecks(ctx, seeds, duration, timeout, seed)
+			}
+			if err != nil {
+				return err
+			}
+			if stats.execs > 0 {
+				fmt.Printf("\n%d input(s), ", stats.execs)
+			} else {
+				fmt.Println()
+			}
+			fmt.Printf("%d failure(s), %d new reproducer(s)\n", stats.failures, stats.saved)
+			crashers, err := cfg.listCrashers()
+			if err != nil {
+				return err
+			}
+			if len(crashers) > 0 {
+				fmt.Printf("%d reproducer(s) in %s; report them upstream with the .txt next to each\n", len(crashers), relToCwd(cfg.getCrashersDir()))
+			}
+			if stats.saved > 0 {
+				return fmt.Errorf("%d new crash(es) found", stats.saved)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&duration, "duration", 5*time.Minute, "how long to fuzz")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "per-tool run time after which an input counts as a hang")
+	cmd.Flags().Uint64Var(&seed, "seed", 0, "random seed, to replay a run (default: time based)")
+	cmd.Flags().BoolVar(&goFuzz, "go", false, "use Go's native fuzzer on decksh.Process in the decksh checkout")
+	return cmd
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// Gallery command
+
+func newGalleryCommand(cfg *config) *cobra.Command {
+	var out, templates, deploy string
+	cmd := &cobra.Command{
+		Use:   "gallery",
+		Short: "Generate a static HTML gallery of the examples",
+		Long: `Generate a static gallery (index plus one page per example, with a thumbnail
+of the first slide) from the repos as last synced; run ensure first.
+
+Builds are incremental: a thumbnail is re-rendered only when the example's
+files or the rendering binaries changed, and only pages whose HTML changed
+are rewritten. Use --templates to iterate on index.html.tmpl and
+example.html.tmpl without re-rendering anything.
+
+Each build also writes an accessibility report (accessibility.html and
+.json) from the rendered decks: images without a caption to use as alt
+text, text whose contrast with the slide background is below WCAG AA
+(4.5:1, 3:1 for large text), and text under 12px on the deck's canvas.
+
+--deploy publishes the result: gh-pages commits it to the gh-pages branch
+of the origin remote (or DECKTOOL_PAGES_REMOTE) using git's configured
+credentials; dir://<path> mirrors it into a directory.
+
+Examples:
+  decktool gallery
+  decktool gallery --templates ./my-templates --out ./site
+  decktool gallery --deploy gh-pages
+  decktool gallery --deploy dir:///var/www/gallery`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if out == "" {
+				out = cfg.getGalleryDir()
+			}
+			stats, err := cfg.buildGallery(cmd.Context(), out, templates)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Gallery in %s: %d thumbnails rendered, %d reused, %d failed; %d pages written\n",
+				out, stats.rendered, stats.reused, stats.failed, stats.pagesWritten)
+			if a := stats.a11y; len(a.Examples) > 0 {
+				fmt.Printf("⚠ Accessibility: %d example(s) with issues: %d image(s) without alt text, %d low-contrast text(s), %d tiny text(s); see %s\n",
+					len(a.Examples), a.Counts["alt-text"], a.Counts["contrast"], a.Counts["tiny-text"], filepath.Join(out, accessibilityID+".html"))
+			}
+			if deploy == "" {
+				return nil
+			}
+			return cfg.deployGallery(cmd.Context(), out, deploy)
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "output directory (default .dist/gallery)")
+	cmd.Flags().StringVar(&templates, "templates", "", "directory with index.html.tmpl and example.html.tmpl overriding the built-in templates")
+	cmd.Flags().StringVar(&deploy, "deploy", "", "publish the gallery: gh-pages or dir://<path>")
+	return cmd
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Handout command
+
+func newHandoutCommand(cfg *config) *cobra.Command {
+	var output, url string
+	var columns int
+	cmd := &cobra.Command{
+		Use:   "handout <example>",
+		Short: "Export a printable PDF handout with a QR code to the hosted deck",
+		Long: `Export a printable handout: the example's slides as thumbnails in a grid on
+letter pages, each page footed with a QR code and the URL of the hosted deck.
+
+The URL is --url, or the example's page in the published gallery (see
+embed for how the gallery location is found).
+
+Writes .dist/handout/<id>.pdf unless -o is given.
+
+Examples:
+  decktool handout deckviz/fire
+  decktool handout deckviz/fire --columns 3 -o fire-handout.pdf
+  decktool handout deckviz/fire --url https://talks.example.com/fire`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, name := cfg.parseExample(args[0])
+			example := source + "/" + name
+			if url == "" {
+				base, err := cfg.galleryBaseURL(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("%w (or pass --url)", err)
+				}
+				url = base + "/" + catalogID(example) + ".html"
+			}
+			title := example
+			dir, err := cfg.getExampleDir(source, name)
+			if err != nil {
+				return err
+			}
+			if deckTitle, _, _, err := scanDeck(cfg.getExampleDshPath(dir, name)); err == nil && deckTitle != "" {
+				title = deckTitle
+			}
+			if output == "" {
+				output = cfg.getHandoutPath(example)
+			}
+			if err := cfg.renderHandout(cmd.Context(), example, title, url, columns, output); err != nil {
+				return err
+			}
+			fmt.Printf("✓ %s (QR: %s)\n", output, url)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output PDF (default .dist/handout/<id>.pdf)")
+	cmd.Flags().StringVar(&url, "url", "", "URL the QR code links to (default: the example's gallery page)")
+	cmd.Flags().IntVar(&columns, "columns", 2, "thumbnails per row")
+	return cmd
+}
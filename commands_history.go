@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Invocation history command
+
+func newHistoryCommand(cfg *config) *cobra.Command {
+	var asJSON, all, failed bool
+	var limit int
+	var since time.Duration
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show past decktool commands with their outcome and outputs",
+		Long: `Every decktool invocation is recorded in ~/.decktool/history.jsonl with its
+arguments, duration, outcome, the toolchain it ran (release tag and a
+fingerprint of the render binaries) and the artifacts it wrote. history shows
+the commands run in the current directory, newest last.
+
+Examples:
+  decktool history
+  decktool history --since 24h
+  decktool history --failed --all
+  decktool history --json --limit 100 > history.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			cutoff := time.Time{}
+			if since > 0 {
+				cutoff = time.Now().Add(-since)
+			}
+			entries, err := readHistory(func(e historyEntry) bool {
+				return (all || e.Dir == dir) && (!failed || e.Error != "") && !e.Time.Before(cutoff) &&
+					(len(e.Args) == 0 || e.Args[0] != "history")
+			}, limit)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if entries == nil {
+					entries = []historyEntry{}
+				}
+				return enc.Encode(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Println("No commands recorded")
+				return nil
+			}
+			for _, e := range entries {
+				printHistoryEntry(e, all)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the entries as JSON")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "number of entries to show (0 for all)")
+	cmd.Flags().DurationVar(&since, "since", 0, "only commands started within this long (e.g. 24h)")
+	cmd.Flags().BoolVar(&all, "all", false, "include commands run in any directory")
+	cmd.Flags().BoolVar(&failed, "failed", false, "only commands that failed")
+	return cmd
+}
+
+func printHistoryEntry(e historyEntry, showDir bool) {
+	mark := "✓"
+	if e.Error != "" {
+		mark = "✗"
+	}
+	duration := (time.Duration(e.DurationMS) * time.Millisecond).Round(10 * time.Millisecond)
+	fmt.Printf("%s %s  decktool %s  (%s)\n", mark, e.Time.Local().Format(time.DateTime), strings.Join(e.Args, " "), duration)
+	if showDir {
+		fmt.Printf("    in %s\n", e.Dir)
+	}
+	if e.Toolchain != "" {
+		fmt.Printf("    toolchain %s\n", e.Toolchain)
+	}
+	if e.Error != "" {
+		fmt.Printf("    error: %s\n", e.Error)
+	}
+	for _, a := range e.Artifacts {
+		fmt.Printf("    → %s\n", a)
+	}
+	if e.MoreArtifacts > 0 {
+		fmt.Printf("    → … and %d more\n", e.MoreArtifacts)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Job queue commands (serve's persistent render jobs)
+
+func newJobsCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Inspect and cancel render jobs queued through serve",
+		Long: fmt.Sprintf(`Render jobs submitted to "serve" (POST /jobs) are stored in %s/<id>/ with
+their source, log and artifact, and survive restarts.`, jobsDir),
+	}
+	cmd.AddCommand(newJobsListCommand(cfg))
+	cmd.AddCommand(newJobsCancelCommand(cfg))
+	cmd.AddCommand(newJobsLogsCommand(cfg))
+	return cmd
+}
+
+func newJobsListCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List render jobs, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs, err := cfg.listJobs()
+			if err != nil {
+				return err
+			}
+			if len(jobs) == 0 {
+				fmt.Println("No jobs")
+				return nil
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tSTATUS\tFORMAT\tCREATED\tDURATION\tARTIFACT")
+			for _, j := range jobs {
+				artifact := j.Artifact
+				if j.Status == jobFailed {
+					artifact, _, _ = strings.Cut(j.Error, "\n")
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", j.ID, j.Status, j.Format,
+					j.Created.Local().Format(time.DateTime), j.duration().Round(time.Millisecond), artifact)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newJobsCancelCommand(cfg *config) *cobra.Command {
+	addr := "127.0.0.1:8080"
+	cmd := &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a pending or running job",
+		Long: `Cancel a job through the running serve instance. When serve is not running,
+the job is marked cancelled in the store so it is not recovered on restart.
+
+Examples:
+  decktool jobs cancel 20260101-120000-a1b2c3
+  SERVE_TOKENS=secret decktool jobs cancel --addr host:8080 20260101-120000-a1b2c3`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.jobCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			err := cancelRemoteJob(cmd.Context(), addr, id)
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				err = cfg.cancelStoredJob(id)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Cancelled %s\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", addr, "address of the running serve instance")
+	return cmd
+}
+
+func cancelRemoteJob(ctx context.Context, addr, id string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "http://"+addr+"/jobs/"+id, nil)
+	if err != nil {
+		return err
+	}
+	if tok, _, _ := strings.Cut(os.Getenv("SERVE_TOKENS"), ","); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(tok))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("cancel %s: %s", id, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func newJobsLogsCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:               "logs <id>",
+		Short:             "Print the lint, render and convert output of a job",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.jobCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			j, err := cfg.loadJob(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(filepath.Join(cfg.getJobDir(j.ID), jobLogFile))
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("No output yet (job is %s)\n", j.Status)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+}
+
+func (cfg *config) jobCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	jobs, err := cfg.listJobs()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var ids []string
+	for _, j := range jobs {
+		if strings.HasPrefix(j.ID, toComplete) {
+			ids = append(ids, j.ID+"\t"+string(j.Status))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
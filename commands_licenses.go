@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// License inventory command
+
+func newLicensesCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "licenses",
+		Short: "Inventory the licenses of modules linked into the toolchain",
+		Long: fmt.Sprintf(`List every Go module linked into the toolchain binaries (as checked out
+for dev build) with its detected license, and collect the license texts into
+.dist/attestations/%s. dev release ships that file with the binaries.
+
+Licenses listed under "licenses": {"deny": [...]} in %s fail the command
+and block dev release; "unknown" denies modules whose license could not be
+recognized.
+
+Examples:
+  decktool dev licenses`, noticesFile, configFile),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cfg.checkLicenses(cmd.Context(), true)
+			if path != "" {
+				fmt.Printf("Notices: %s\n", relToCwd(path))
+			}
+			return err
+		},
+	}
+}
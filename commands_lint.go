@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Lint command
+
+func newLintCommand(cfg *config) *cobra.Command {
+	var style, listRules bool
+	cmd := &cobra.Command{
+		Use:   "lint [example]...",
+		Short: "Lint examples with dshlint and, with --style, the project style rules",
+		Long: `Lint the given examples (all examples when none are given) with dshlint.
+
+--style also renders each example and applies the style rules to the deck:
+  text-overflow    text estimated to run past the slide edge
+  words-per-slide  slides with more than style.max_words words (default 60)
+  fonts            decks using more than style.max_fonts fonts (default 3)
+  image-stretch    images drawn larger than their native resolution, or
+                   at a distorted aspect ratio
+
+Rules are configured in the "style" section of decktool.json: "disable"
+turns rules off, "skip" maps a rule to deck patterns it ignores.
+
+Examples:
+  decktool lint
+  decktool lint deckviz/fire --style
+  decktool lint --rules`,
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listRules {
+				for _, rule := range styleRules {
+					fmt.Printf("%-16s %s\n", rule.name, rule.summary)
+				}
+				return nil
+			}
+			ctx := cmd.Context()
+			if err := cfg.ensureBins(ctx); err != nil {
+				return err
+			}
+			if err := cfg.ensureReposFor(ctx, args); err != nil {
+				return err
+			}
+			examples := args
+			if len(examples) == 0 {
+				all, err := cfg.listExamples()
+				if err != nil {
+					return err
+				}
+				examples = all
+			}
+
+			var failed, findings int
+			for _, raw := range examples {
+				example := cfg.normalizeExampleName(raw)
+				if !style {
+					source, name := cfg.parseExample(raw)
+					err := cfg.materializeExample(ctx, raw)
+					dir := ""
+					if err == nil {
+						dir, err = cfg.getExampleDir(source, name)
+					}
+					if err == nil {
+						err = cfg.lintDeck(ctx, dir, name+".dsh")
+					}
+					if err != nil {
+						fmt.Printf("✗ %s: %v\n", example, err)
+						failed++
+					}
+					continue
+				}
+				xmlPath, err := cfg.renderExample(ctx, raw)
+				if errors.Is(err, os.ErrNotExist) {
+					fmt.Printf("⊘ %s: %v\n", example, err)
+					continue
+				}
+				if err != nil {
+					fmt.Printf("✗ %s: %v\n", example, err)
+					failed++
+					continue
+				}
+				found, err := cfg.lintStyle(example, xmlPath)
+				if err != nil {
+					fmt.Printf("✗ %s: %v\n", example, err)
+					failed++
+					continue
+				}
+				if len(found) > 0 {
+					lines := make([]string, len(found))
+					for i, f := range found {
+						lines[i] = f.String()
+					}
+					fmt.Printf("⚠ %s: %d style finding(s)\n  %s\n", example, len(found), strings.Join(lines, "\n  "))
+					findings += len(found)
+				}
+			}
+
+			switch {
+			case failed > 0:
+				return fmt.Errorf("%d of %d example(s) failed to lint", failed, len(examples))
+			case findings > 0:
+				return fmt.Errorf("%d style finding(s)", findings)
+			}
+			fmt.Printf("✓ %d example(s) lint clean\n", len(examples))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&style, "style", false, "also render and apply the style rules")
+	cmd.Flags().BoolVar(&listRules, "rules", false, "list the style rules and exit")
+	return cmd
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Open Graph image command
+
+func newOGImageCommand(cfg *config) *cobra.Command {
+	var output, title string
+	var overlay bool
+	cmd := &cobra.Command{
+		Use:   "og-image <example>",
+		Short: "Render an example's title slide as a 1200x630 social preview image",
+		Long: `Render the example's title slide scaled to cover a 1200x630 card, for use as
+an Open Graph image. --overlay draws the deck title (its leading comment, or
+the example name) in a band across the bottom; --title sets other text.
+
+Writes .dist/og/<id>.png unless -o is given.
+
+Examples:
+  decktool og-image deckviz/fire
+  decktool og-image deckviz/fire --overlay
+  decktool og-image deckviz/fire --title "Fire, visualised" -o fire-card.png`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, name := cfg.parseExample(args[0])
+			example := source + "/" + name
+			if overlay && title == "" {
+				title = example
+				dir, err := cfg.getExampleDir(source, name)
+				if err != nil {
+					return err
+				}
+				if deckTitle, _, _, err := scanDeck(cfg.getExampleDshPath(dir, name)); err == nil && deckTitle != "" {
+					title = deckTitle
+				}
+			}
+			if output == "" {
+				output = cfg.getOGImagePath(example)
+			}
+			if err := cfg.renderOGImage(cmd.Context(), example, title, output); err != nil {
+				return err
+			}
+			fmt.Printf("✓ %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output PNG (default .dist/og/<id>.png)")
+	cmd.Flags().BoolVar(&overlay, "overlay", false, "draw the deck title across the bottom")
+	cmd.Flags().StringVar(&title, "title", "", "overlay text (implies --overlay)")
+	return cmd
+}
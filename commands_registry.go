@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// Registry commands
+
+func newRegistryCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "List the example corpora run, view and completion can address",
+		Long: `List every known corpus: the built-in example repos and the deck directories
+registered with registry add. A registered directory holds one subdirectory
+per deck (<dir>/<deck>/<deck>.dsh), addressed as <name>/<deck> from any
+project. The registry is per user: ~/.decktool/registry.json, or the file
+DECKTOOL_REGISTRY names.
+
+Examples:
+  decktool registry
+  decktool registry add ~/talks
+  decktool registry add ./decks --name team
+  decktool run talks/gophercon
+  decktool registry remove talks`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			groups, err := cfg.examplesBySource()
+			if err != nil {
+				return err
+			}
+			var names []string
+			for name := range groups {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				repo := cfg.repos[name]
+				if !repo.cloned() {
+					fmt.Printf("⊘ %-12s %s (built-in, not fetched yet)\n", name, repo.dir)
+					continue
+				}
+				fmt.Printf("✓ %-12s %s (built-in, %d examples)\n", name, repo.dir, len(groups[name]))
+			}
+			corpora, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+			for _, c := range corpora {
+				if _, err := os.Stat(c.Dir); err != nil {
+					fmt.Printf("✗ %-12s %s (missing; registry remove %s drops it)\n", c.Name, c.Dir, c.Name)
+					continue
+				}
+				fmt.Printf("✓ %-12s %s (%d decks)\n", c.Name, c.Dir, len(c.deckNames()))
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(newRegistryAddCommand(cfg))
+	cmd.AddCommand(newRegistryRemoveCommand())
+	return cmd
+}
+
+func newRegistryAddCommand(cfg *config) *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Use:   "add <dir>",
+		Short: "Register a deck directory as a corpus",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cfg.registerCorpus(args[0], name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Registered %s (%s, %d decks)\n", c.Name, c.Dir, len(c.deckNames()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "corpus name (default: the directory's name)")
+	return cmd
+}
+
+func newRegistryRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Forget a registered corpus (its files stay)",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			corpora, _ := loadRegistry()
+			var names []string
+			for _, c := range corpora {
+				names = append(names, c.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := unregisterCorpus(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Removed %s from the registry\n", args[0])
+			return nil
+		},
+	}
+}
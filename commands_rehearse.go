@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Rehearsal command
+
+func newRehearseCommand(cfg *config) *cobra.Command {
+	var wpm int
+	var timeBox, length time.Duration
+	cmd := &cobra.Command{
+		Use:   "rehearse <example>",
+		Short: "Estimate how long an example takes to present, slide by slide",
+		Long: `Render the example and estimate its presentation time: the words on each
+slide (text and list items) at --wpm words per minute, plus 5 seconds for
+each image or chart.
+
+Slides estimated over --time-box are flagged, as is a total over --length.
+
+Examples:
+  decktool rehearse deckviz/fire
+  decktool rehearse deckviz/fire --wpm 110 --time-box 90s --length 20m`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if wpm <= 0 {
+				return errors.New("--wpm must be positive")
+			}
+			ctx := cmd.Context()
+			if err := cfg.ensureBins(ctx); err != nil {
+				return err
+			}
+			if err := cfg.ensureReposFor(ctx, args[:1]); err != nil {
+				return err
+			}
+			example := cfg.normalizeExampleName(args[0])
+			xmlPath, err := cfg.renderExample(ctx, example)
+			if err != nil {
+				return err
+			}
+			timings, err := estimateTimings(xmlPath, wpm)
+			if err != nil {
+				return err
+			}
+			printTimings(example, timings, wpm, timeBox, length)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&wpm, "wpm", 130, "speaking rate in words per minute")
+	cmd.Flags().DurationVar(&timeBox, "time-box", 2*time.Minute, "flag slides estimated over this (0 to disable)")
+	cmd.Flags().DurationVar(&length, "length", 0, "flag a total over this talk length")
+	return cmd
+}
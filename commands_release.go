@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Published release commands
+
+func newReleaseCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Inspect published GitHub releases",
+	}
+	cmd.AddCommand(newReleaseVerifyCommand(cfg))
+	cmd.AddCommand(newReleaseExportVerificationCommand(cfg))
+	return cmd
+}
+
+func newReleaseVerifyCommand(cfg *config) *cobra.Command {
+	var bundle string
+	cmd := &cobra.Command{
+		Use:   "verify [tag | artifact-dir]",
+		Short: "Check a release's assets against its SLSA provenance",
+		Long: fmt.Sprintf(`Download every asset of a release (default: the latest) and check each one
+against the digests in its %s, after checking its signature against
+the signing key pinned in ~/.decktool/trust.json. Prints the builder and
+source SHAs.
+
+With --bundle, verify artifacts in a directory offline against a bundle from
+"release export-verification" instead (for air-gapped networks).
+
+Examples:
+  decktool release verify
+  decktool release verify v0.1.0
+  decktool release verify --bundle v0.1.0-verification.tar.gz ./artifacts`, provenanceFile),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if bundle != "" {
+				dir := "."
+				if len(args) > 0 {
+					dir = args[0]
+				}
+				if err := verifyWithBundle(bundle, dir); err != nil {
+					return err
+				}
+				fmt.Println("✓ Artifacts match the verification bundle")
+				return nil
+			}
+			ctx := cmd.Context()
+			if err := cfg.ensureGhCli(ctx); err != nil {
+				return err
+			}
+			var tag string
+			if len(args) > 0 {
+				tag = args[0]
+			} else {
+				var err error
+				if tag, err = latestReleaseTag(ctx); err != nil {
+					return err
+				}
+			}
+			if err := cfg.verifyRelease(ctx, tag); err != nil {
+				return err
+			}
+			fmt.Printf("✓ %s matches its provenance\n", tag)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.acceptNewSigner, "accept-new-signer", false, "pin a changed release signing key (after an announced key rotation)")
+	cmd.Flags().StringVar(&bundle, "bundle", "", "verify offline against this verification bundle")
+	return cmd
+}
+
+func newReleaseExportVerificationCommand(cfg *config) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "export-verification <tag>",
+		Short: "Write an offline verification bundle for a release",
+		Long: `Write <tag>-verification.tar.gz holding the release's signed provenance,
+SHA256SUMS and a manifest. Carry it into an air-gapped network separately
+from the artifacts and check them there with
+"decktool release verify --bundle <file> <dir>" or "sha256sum -c SHA256SUMS".
+
+Examples:
+  decktool release export-verification v0.1.0
+  decktool release export-verification v0.1.0 -o /media/usb/v0.1.0.tar.gz`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			tag := args[0]
+			if err := cfg.ensureGhCli(ctx); err != nil {
+				return err
+			}
+			if output == "" {
+				output = verificationBundleName(tag)
+			}
+			if err := cfg.exportVerificationBundle(ctx, tag, output); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Wrote %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "bundle path (default <tag>-verification.tar.gz)")
+	cmd.Flags().BoolVar(&cfg.acceptNewSigner, "accept-new-signer", false, "pin a changed release signing key (after an announced key rotation)")
+	return cmd
+}
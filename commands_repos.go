@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// Repo commands
+
+func newReposCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repos",
+		Short: "List the managed repos and their remotes",
+		Long: `List every managed repo with its URL and branch. Set <REPO>_REPO (e.g.
+DECKSH_REPO) to your fork to work from it: origin is then the fork and the
+canonical ajstarks repo is configured as upstream on every sync. Clones
+with local changes or commits are flagged; updates leave them alone.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var names []string
+			for name := range cfg.repos {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				repo := cfg.repos[name]
+				fmt.Printf("%-10s %s (%s)\n", name, repo.url, repo.branch)
+				if repo.isFork() {
+					fmt.Printf("%-10s   upstream %s\n", "", repo.upstream)
+				}
+				if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+					continue
+				}
+				if cfg.onDemand(repo) {
+					names := cfg.treeExampleNames(repo)
+					var present int
+					for _, name := range names {
+						if _, err := os.Stat(filepath.Join(repo.dir, name)); err == nil {
+							present++
+						}
+					}
+					fmt.Printf("%-10s   sparse: %d of %d examples checked out (more on first use)\n", "", present, len(names))
+				}
+				if changes, err := cfg.localChanges(cmd.Context(), repo); err == nil && !changes.empty() {
+					fmt.Printf("%-10s   ⚠ %s (kept on update unless --stash or --force)\n", "", changes)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(newReposSyncForkCommand(cfg))
+	return cmd
+}
+
+func newReposSyncForkCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync-fork [repo]...",
+		Short: "Fast-forward forks from upstream and push them (all forks by default)",
+		Long: `Fetch the canonical upstream branch into each fork's checkout, fast-forward
+the branch and push it to the fork, so a build starts from current upstream
+plus nothing else. A fork whose branch has diverged is reported and left
+alone. Pushing uses git's configured credentials.
+
+Examples:
+  DECKSH_REPO=git@github.com:me/decksh.git decktool repos sync-fork decksh
+  decktool repos sync-fork && decktool dev build`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var names []string
+			for name := range cfg.repos {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				for name, repo := range cfg.repos {
+					if repo.isFork() {
+						args = append(args, name)
+					}
+				}
+				if len(args) == 0 {
+					fmt.Println("No repo points at a fork (set e.g. DECKSH_REPO to your fork's URL)")
+					return nil
+				}
+				sort.Strings(args)
+			}
+			failed := 0
+			for _, name := range args {
+				repo, ok := cfg.repos[name]
+				if !ok {
+					return fmt.Errorf("unknown repo %q (see decktool repos)", name)
+				}
+				if err := cfg.syncFork(cmd.Context(), repo); err != nil {
+					fmt.Printf("✗ %v\n", err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d fork(s) not synced", failed)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Run and view commands
+
+func newRunCommand(cfg *config) *cobra.Command {
+	var suite, porcelainFormat string
+	cmd := &cobra.Command{
+		Use:   "run [example]...",
+		Short: "Lint and render one or more examples",
+		Long: `Lint and render examples. Besides example names, "-" reads a deck from
+stdin and an http(s) URL fetches a remote .dsh; these render in a private temp
+workspace and the XML is copied to the current directory.
+
+Data files configured in the "data" section of decktool.json are pulled
+(or reused from cache) before an example renders; see decktool data pull.
+
+Examples:
+  decktool run deckviz/fire
+  cat deck.dsh | decktool run -
+  decktool run https://example.com/deck.dsh --keep-temp
+  decktool run sales/quarterly --refresh-data
+  decktool run --suite smoke
+
+A suite is a named set of examples in the "suites" section of decktool.json,
+with the formats to convert them to, decksh variables and environment to
+render them with, and the examples expected to fail; run --suite exits
+non-zero when any example turns out otherwise.
+
+--porcelain prints a rendered or skipped record per example (see decktool
+which --help for the format).`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if suite != "" && len(args) > 0 {
+				return fmt.Errorf("--suite runs the suite's examples; drop %s", strings.Join(args, " "))
+			}
+			if suite != "" {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if suite != "" && porcelainFormat != "" {
+				return fmt.Errorf("--porcelain does not cover --suite; its exit status tells whether the suite passed")
+			}
+			p, err := startPorcelain(porcelainFormat)
+			if err != nil {
+				return err
+			}
+			if suite != "" {
+				s, err := cfg.runSuite(suite)
+				if err != nil {
+					return err
+				}
+				if err := cfg.ensureBins(cmd.Context()); err != nil {
+					return err
+				}
+				if err := cfg.ensureReposFor(cmd.Context(), s.Examples); err != nil {
+					return err
+				}
+				return cfg.runSuiteExamples(cmd.Context(), s)
+			}
+			if err := cfg.ensureBins(cmd.Context()); err != nil {
+				return err
+			}
+			if err := cfg.ensureReposFor(cmd.Context(), args); err != nil {
+				return err
+			}
+			results, err := cfg.runExamples(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			if p != nil {
+				for _, raw := range cfg.uniqueExamples(args) {
+					key := cfg.exampleKey(raw)
+					if xmlPath, ok := results[key]; ok {
+						p.record("rendered", key, xmlPath)
+					} else {
+						p.record("skipped", key, "no deck script")
+					}
+				}
+				return nil
+			}
+			var keys []string
+			for k := range results {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, name := range keys {
+				fmt.Printf("%s -> %s\n", name, results[name])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.keepTemp, "keep-temp", false, "keep temp workspaces of stdin/remote renders for debugging")
+	cmd.Flags().BoolVar(&cfg.refreshData, "refresh-data", false, "re-fetch the examples' data connector results even when cached")
+	cmd.Flags().StringVar(&cfg.outDir, "out", "", "copy the rendered XML into `dir` as <source>/<name>.xml")
+	addPorcelainFlag(cmd, &porcelainFormat)
+	cmd.Flags().StringVar(&suite, "suite", "", "run a suite from decktool.json instead of the examples given")
+	cmd.RegisterFlagCompletionFunc("suite", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, s := range cfg.file.Suites {
+			names = append(names, s.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func newViewCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:               "view [example]",
+		Short:             "Render and open an example in ebdeck",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cfg.exampleCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.ensureBins(cmd.Context()); err != nil {
+				return err
+			}
+			if err := cfg.ensureReposFor(cmd.Context(), args); err != nil {
+				return err
+			}
+			results, err := cfg.runExamples(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			xmlPath, ok := results[cfg.exampleKey(args[0])]
+			if !ok {
+				return fmt.Errorf("rendered XML not found for %q", args[0])
+			}
+
+			// Get example directory to run ebdeck from there (for relative paths in XML)
+			source, name := cfg.parseExample(args[0])
+			exampleDir, err := cfg.getExampleDir(source, name)
+			if err != nil {
+				return err
+			}
+
+			ebdeckPath, err := cfg.resolveBinary("ebdeck")
+			if err != nil {
+				return err
+			}
+			viewCmd := exec.CommandContext(cmd.Context(), ebdeckPath, xmlPath)
+			viewCmd.Dir = exampleDir
+			cfg.useEnv(viewCmd, envTools)
+			viewCmd.Stdout = os.Stdout
+			viewCmd.Stderr = os.Stderr
+			return viewCmd.Run()
+		},
+	}
+}
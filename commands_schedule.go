@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Scheduled regeneration commands
+
+func newScheduleCommand(cfg *config) *cobra.Command {
+	var only []string
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Re-render and republish decks on a cron schedule",
+		Long: `Run the jobs in the "schedule" section of decktool.json as they fall due,
+until interrupted. Each run syncs the data repos, re-pulls the decks' data
+connectors (see decktool data), renders each deck to its formats and copies
+them into export, and optionally rebuilds and deploys the gallery:
+
+  "schedule": {
+    "jobs": [
+      { "name": "sales", "cron": "0 7 * * 1-5", "decks": ["sales/quarterly"],
+        "formats": ["pdf", "png"], "export": "/var/www/reports" },
+      { "name": "site", "cron": "@hourly", "gallery": true, "deploy": "gh-pages" }
+    ]
+  }
+
+cron takes five fields (minute hour day month weekday, local time) or
+@hourly, @daily, @weekly, @monthly. Jobs run one at a time; the outcome of
+each job's last run is kept in .dist/schedule.json and shown by list, and
+posted to the sinks in the "notify" section of decktool.json.
+
+Examples:
+  decktool schedule
+  decktool schedule --job sales
+  decktool schedule list
+  decktool schedule run sales`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs := cfg.file.Schedule.Jobs
+			if len(only) > 0 {
+				jobs = nil
+				for _, name := range only {
+					job, err := cfg.scheduleJob(name)
+					if err != nil {
+						return err
+					}
+					jobs = append(jobs, job)
+				}
+			}
+			if err := cfg.ensureBins(cmd.Context()); err != nil {
+				return err
+			}
+			return cfg.runSchedule(cmd.Context(), jobs)
+		},
+	}
+	cmd.Flags().StringSliceVar(&only, "job", nil, "run only these jobs")
+	cmd.AddCommand(newScheduleListCommand(cfg))
+	cmd.AddCommand(newScheduleRunCommand(cfg))
+	return cmd
+}
+
+func newScheduleListCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List scheduled jobs with their next and last runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs := cfg.file.Schedule.Jobs
+			if len(jobs) == 0 {
+				fmt.Println(`No scheduled jobs (see the "schedule" section in decktool schedule --help)`)
+				return nil
+			}
+			state := cfg.loadScheduleState()
+			now := time.Now()
+			for _, job := range jobs {
+				fmt.Printf("%s (%s)\n", job.Name, job.Cron)
+				fmt.Printf("  next: %s\n", job.nextRun(now).Format(time.DateTime))
+				run, ok := state[job.Name]
+				switch {
+				case !ok:
+					fmt.Println("  last: never run")
+				case run.Error != "":
+					fmt.Printf("  last: ✗ %s (%s): %s\n", run.Started.Format(time.DateTime), run.Duration, run.Error)
+				default:
+					fmt.Printf("  last: ✓ %s (%s)\n", run.Started.Format(time.DateTime), run.Duration)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newScheduleRunCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "run <job>",
+		Short: "Run a scheduled job now",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var names []string
+			for _, job := range cfg.file.Schedule.Jobs {
+				if !slices.Contains(args, job.Name) {
+					names = append(names, job.Name)
+				}
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := cfg.scheduleJob(args[0])
+			if err != nil {
+				return err
+			}
+			if err := cfg.ensureBins(cmd.Context()); err != nil {
+				return err
+			}
+			return cfg.runScheduledJob(cmd.Context(), job)
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Render API server command
+
+func newServeCommand(cfg *config) *cobra.Command {
+	addr := "127.0.0.1:8080"
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local HTTP API that renders decksh source",
+		Long: `Serve an HTTP API that renders decksh posted to /render. Each request is
+rendered in its own temp workspace, removed afterwards (keep with --keep-temp).
+
+Clients are rate limited and renders are capped in size and concurrency (see
+"serve" in decktool.json). Set SERVE_TOKENS to require "Authorization: Bearer
+<token>"; serving on a non-loopback address without tokens is refused.
+
+POST /jobs queues a render instead and returns its id; jobs persist in .jobs/
+and are recovered after a restart. Poll GET /jobs/<id>, then fetch
+/jobs/<id>/artifact or /jobs/<id>/logs; DELETE /jobs/<id> cancels (or use
+"decktool jobs list/cancel/logs").
+
+Prometheus metrics (render counts and durations including jobs, errors,
+rejections, renders in progress, queued jobs) are exposed at /metrics.
+
+Examples:
+  decktool serve
+  SERVE_TOKENS=secret decktool serve --addr 0.0.0.0:8080
+  curl --data-binary @deck.dsh http://127.0.0.1:8080/render > deck.xml
+  curl --data-binary @deck.dsh 'http://127.0.0.1:8080/render?format=pdf' > deck.pdf
+  curl --data-binary @deck.dsh 'http://127.0.0.1:8080/jobs?format=pdf'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.ensureBins(cmd.Context()); err != nil {
+				return err
+			}
+			return cfg.serve(cmd.Context(), addr)
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", addr, "listen address")
+	cmd.Flags().BoolVar(&cfg.keepTemp, "keep-temp", false, "keep per-request temp workspaces for debugging")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Corpus shard command (decktool test shard, worker mode)
+
+func newTestShardCommand(cfg *config) *cobra.Command {
+	var pins []string
+	cmd := &cobra.Command{
+		Use:   "shard <i>/<n>",
+		Short: "Render one shard of the corpus and print the results as JSON (worker mode)",
+		Long: `Render every n-th example of the sorted corpus, starting at the i-th, and
+print the results as JSON on stdout for a coordinator ("test --workers");
+progress goes to stderr. --pin <repo>@<sha> renders a data repo at the
+coordinator's SHA, in a worktree of the shard's own; the checkouts in .data
+are left as they are.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shard, of, err := parseShard(args[0])
+			if err != nil {
+				return err
+			}
+			pinned, err := decodePins(pins)
+			if err != nil {
+				return err
+			}
+			// Keep stdout for the JSON; tools and progress write to stderr
+			result, err := cfg.renderShard(withToolOutput(cmd.Context(), os.Stderr), shard, of, pinned)
+			if err != nil {
+				return err
+			}
+			return json.NewEncoder(os.Stdout).Encode(result)
+		},
+	}
+	cmd.Flags().StringArrayVar(&pins, "pin", nil, "check data repo out at <repo>@<sha> before rendering")
+	return cmd
+}
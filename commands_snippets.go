@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Snippet library commands
+
+func newSnippetsCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snippets",
+		Short: "Manage the library of reusable decksh include files",
+		Long: `The snippet library holds decksh files (title layouts, chart styles, color
+definitions) that decks include as
+
+  include "snippets/<name>.dsh"
+
+Whenever decktool renders a deck, such includes resolve to the library, so
+the same deck works on any machine with the snippet in its library. A deck
+directory with its own snippets/<name>.dsh uses that copy instead. Includes
+inside a snippet resolve against the deck's directory as usual.
+
+The library is .snippets, or DECKTOOL_SNIPPETS to share one between
+projects.`,
+	}
+	cmd.AddCommand(newSnippetsListCommand(cfg))
+	cmd.AddCommand(newSnippetsAddCommand(cfg))
+	cmd.AddCommand(newSnippetsUseCommand(cfg))
+	return cmd
+}
+
+func newSnippetsListCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the snippets in the library",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snippets, err := cfg.listSnippets()
+			if err != nil {
+				return err
+			}
+			if len(snippets) == 0 {
+				fmt.Printf("No snippets in %s (add one with decktool snippets add <file.dsh>)\n", cfg.snippetsDir)
+				return nil
+			}
+			fmt.Printf("Snippets in %s:\n", cfg.snippetsDir)
+			for _, s := range snippets {
+				fmt.Printf("  %-20s %s\n", s.Name, s.Description)
+			}
+			return nil
+		},
+	}
+}
+
+func newSnippetsAddCommand(cfg *config) *cobra.Command {
+	var name string
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "add <file.dsh>",
+		Short: "Copy a decksh file into the snippet library",
+		Long: `Copy a decksh file into the library, named after the file unless --name is
+given. Its first // comment line is shown as the description by list.
+
+Examples:
+  decktool snippets add title-layout.dsh
+  decktool snippets add ./styles/bars.dsh --name bar-chart --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			added, err := cfg.addSnippet(args[0], name, force)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Added %s; include it with: %s\n", added, snippetIncludeLine(added))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "library name (default: the file name)")
+	cmd.Flags().BoolVar(&force, "force", false, "replace a snippet with the same name")
+	return cmd
+}
+
+func newSnippetsUseCommand(cfg *config) *cobra.Command {
+	var into string
+	cmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Print the include line for a snippet, or add it to a deck",
+		Long: `Print the line that includes a library snippet. --into adds it to a deck
+script instead, after its deck line.
+
+Examples:
+  decktool snippets use title-layout
+  decktool snippets use title-layout --into talk.dsh`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			snippets, _ := cfg.listSnippets()
+			var names []string
+			for _, s := range snippets {
+				names = append(names, s.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := snippetName(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(cfg.getSnippetPath(name)); err != nil {
+				return fmt.Errorf("snippet %q is not in the library (see decktool snippets list)", name)
+			}
+			if into == "" {
+				fmt.Println(snippetIncludeLine(name))
+				return nil
+			}
+			script, err := os.ReadFile(into)
+			if err != nil {
+				return err
+			}
+			updated, changed := insertSnippetInclude(script, name)
+			if !changed {
+				fmt.Printf("✓ %s already includes %s\n", into, name)
+				return nil
+			}
+			if err := os.WriteFile(into, updated, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Added %s to %s\n", snippetIncludeLine(name), into)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&into, "into", "", "deck script to add the include to")
+	return cmd
+}
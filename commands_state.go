@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Environment state commands
+
+func newStateCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export or import the environment (repos, toolchain, config, history)",
+		Long: `Capture everything needed to reproduce a problem in one file: repo URLs,
+branches and SHAs, toolchain digests, decktool.json, decktool.lock and the
+recent decktool commands run in this project. Attach it to a bug report; the
+maintainer imports it to get the same corpus and config.
+
+Examples:
+  decktool state export -o my-state.json
+  decktool state import my-state.json`,
+	}
+	cmd.AddCommand(newStateExportCommand(cfg), newStateImportCommand(cfg))
+	return cmd
+}
+
+func newStateExportCommand(cfg *config) *cobra.Command {
+	var output string
+	var historySize int
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the environment state file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := cfg.exportState(cmd.Context(), historySize)
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(st, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(output, append(data, '\n'), 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Wrote %s (%d repos, %d tools, %d commands)\n", output, len(st.Repos), len(st.Toolchain), len(st.History))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "decktool-state.json", "state file to write")
+	cmd.Flags().IntVar(&historySize, "history", 20, "number of recent commands to include")
+	return cmd
+}
+
+func newStateImportCommand(cfg *config) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Recreate an exported environment in the current directory",
+		Long: `Restore decktool.json and decktool.lock from the state file, pin the data
+repos to its SHAs, download the toolchain and report any binary that differs
+from the exported one. Repos exported from other URLs or branches are used
+for this import and printed as env exports to keep using them.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := loadState(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Environment exported %s from %s/%s (decktool %s)\n",
+				st.Exported.Local().Format(time.DateTime), st.Host.OS, st.Host.Arch, shortSHA(st.Host.Decktool))
+			if err := cfg.importState(cmd.Context(), st, force); err != nil {
+				return err
+			}
+			if len(st.History) > 0 {
+				fmt.Println("\nRecent commands in the exported environment:")
+				for _, h := range st.History {
+					mark := "✓"
+					if h.Error != "" {
+						mark = "✗"
+					}
+					fmt.Printf("  %s %s decktool %s\n", mark, h.Time.Local().Format(time.DateTime), strings.Join(h.Args, " "))
+					if h.Error != "" {
+						fmt.Printf("      %s\n", h.Error)
+					}
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "replace existing decktool.json and decktool.lock")
+	return cmd
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Trash commands
+
+func newRestoreCommand(cfg *config) *cobra.Command {
+	var empty bool
+	cmd := &cobra.Command{
+		Use:   "restore [id]...",
+		Short: "Bring back folders removed by dev clean or cache clean",
+		Long: `dev clean and cache clean move folders into a trash instead of deleting them.
+Without arguments restore lists what the trash holds; with ids it moves those
+folders back where they were (refusing if something has been created there
+since). Entries are purged after DECKTOOL_TRASH_DAYS (default 7) days, or
+now with --empty.
+
+Examples:
+  decktool restore
+  decktool restore 20261016-144946-src
+  decktool restore --empty`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			entries, _ := listTrash(cfg.trashRoots())
+			var ids []string
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+			return ids, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if empty {
+				if len(args) > 0 {
+					return errors.New("--empty takes no ids")
+				}
+				for _, root := range cfg.trashRoots() {
+					if err := purgeTrash(root, 0); err != nil {
+						return err
+					}
+				}
+				fmt.Println("✓ Trash emptied")
+				return nil
+			}
+			entries, err := listTrash(cfg.trashRoots())
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				if len(entries) == 0 {
+					fmt.Println("Trash is empty")
+					return nil
+				}
+				keep := time.Duration(trashDays()) * 24 * time.Hour
+				for _, e := range entries {
+					fmt.Printf("%s  %s (%s, %s by %s, purged %s)\n", e.ID, e.Path, formatSize(e.size()),
+						e.Trashed.Local().Format(time.DateTime), e.Command, e.Trashed.Add(keep).Local().Format(time.DateOnly))
+				}
+				return nil
+			}
+			var failed int
+			for _, id := range args {
+				i := -1
+				for j, e := range entries {
+					if e.ID == id {
+						i = j
+					}
+				}
+				if i < 0 {
+					fmt.Printf("✗ %s: not in the trash\n", id)
+					failed++
+					continue
+				}
+				if err := entries[i].restore(); err != nil {
+					fmt.Printf("✗ %s: %v\n", id, err)
+					failed++
+					continue
+				}
+				fmt.Printf("✓ Restored %s\n", entries[i].Path)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d restore(s) failed", failed, len(args))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&empty, "empty", false, "permanently delete everything in the trash")
+	return cmd
+}
@@ -33,6 +33,75 @@ func newCompletionCommand(root *cobra.Command) *cobra.Command {
 	}
 }
 
+func newWhichCommand(cfg *config) *cobra.Command {
+	var porcelainFormat string
+	cmd := &cobra.Command{
+		Use:   "which [tool]...",
+		Short: "Show which binary each deck tool resolves to",
+		Long: `Print the path each tool runs from: the downloaded toolchain in .dist,
+then PATH, then the Go bin directory. Without arguments every toolchain
+tool is listed.
+
+--porcelain (also on examples, run and ensure) prints stable lines for
+programs to parse. The format is versioned (--porcelain=v1 is the current
+and only one) and only ever gains new record types. Each line is a record
+type and fields separated by tabs; a tab, newline or backslash inside a
+field is written as \t, \n or \\. Progress and tool output go to stderr;
+failures still exit non-zero. v1 records:
+
+  example   <source/name> <dir>
+  rendered  <example> <xml path>
+  skipped   <example> <reason>
+  tool      <name> <path>
+  missing   <name>
+  repo      <name> <dir> <fetched|unfetched> <commit or ->
+  favorite  <example>
+  alias     <name> <example>
+
+Examples:
+  decktool which decksh
+  decktool which --porcelain`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var names []string
+			for _, spec := range cfg.toolchain {
+				names = append(names, spec.name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := startPorcelain(porcelainFormat)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				for _, spec := range cfg.toolchain {
+					args = append(args, spec.name)
+				}
+			}
+			var missing []string
+			for _, name := range args {
+				if p != nil {
+					cfg.porcelainTool(p, name)
+					continue
+				}
+				path, err := cfg.resolveBinary(name)
+				if err != nil {
+					fmt.Printf("✗ %v\n", err)
+					missing = append(missing, name)
+					continue
+				}
+				fmt.Println(path)
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("%d tool(s) not found (decktool ensure downloads the toolchain)", len(missing))
+			}
+			return nil
+		},
+	}
+	addPorcelainFlag(cmd, &porcelainFormat)
+	return cmd
+}
+
 func newSetupCommand(cfg *config) *cobra.Command {
 	defaultShell := detectShell()
 
@@ -56,7 +125,7 @@ func newSetupCommand(cfg *config) *cobra.Command {
 				if err := cfg.ensureBins(ctx); err != nil {
 					return err
 				}
-				if err := cfg.ensureRepos(ctx); err != nil {
+				if err := cfg.syncDataRepos(ctx, func(*repoConfig) bool { return false }); err != nil {
 					return err
 				}
 			}
@@ -89,3 +158,19 @@ func newSetupCommand(cfg *config) *cobra.Command {
 	cmd.Flags().StringVar(&local, "local", "", "e.g. --local=bin/decktool to place binary in repo")
 	return cmd
 }
+
+func newShellCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "Start a subshell with the deck toolchain on PATH",
+		Long: `Start your shell with the dist binaries on PATH under their plain names
+(decksh, pdfdeck, ...) and DECKFONTS set, so upstream tools can be run directly.
+
+Examples:
+  decktool shell`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cfg.runToolchainShell(cmd.Context())
+		},
+	}
+}
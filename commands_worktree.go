@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Worktree commands
+
+func newWorktreeCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "worktree",
+		Short: "Check out other refs of code repos side by side (git worktrees)",
+		Long: `Check out branches, tags, SHAs or PR refs of the code repos (decksh, deck, ...)
+as git worktrees under .src/.worktrees/<repo>/<ref>. They share the primary
+clone's objects and leave its checkout alone, so several refs can be built
+and compared at once; build one with dev build --ref <repo>@<ref>.
+
+Examples:
+  decktool dev worktree add decksh@pull/42/head
+  decktool dev worktree list
+  decktool dev worktree remove decksh@pull/42/head`,
+	}
+	cmd.AddCommand(newWorktreeAddCommand(cfg), newWorktreeListCommand(cfg), newWorktreeRemoveCommand(cfg))
+	return cmd
+}
+
+func newWorktreeAddCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <repo>@<ref>...",
+		Short: "Fetch refs and check them out in worktrees (updating existing ones)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				repo, ref, err := cfg.parseRepoRef(arg)
+				if err != nil {
+					return err
+				}
+				dir, sha, err := cfg.ensureWorktree(cmd.Context(), repo, ref)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✓ %s@%s at %s in %s\n", repo.name, ref, shortSHA(sha), dir)
+			}
+			return nil
+		},
+	}
+}
+
+func newWorktreeListCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List ref worktrees",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			worktrees, err := cfg.listWorktrees(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(worktrees) == 0 {
+				fmt.Println("No worktrees; add one with: decktool dev worktree add <repo>@<ref>")
+				return nil
+			}
+			for _, wt := range worktrees {
+				fmt.Printf("%-10s %s  %s\n", wt.repo, shortSHA(wt.sha), relToCwd(wt.dir))
+			}
+			return nil
+		},
+	}
+}
+
+func newWorktreeRemoveCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <repo>@<ref>...",
+		Short: "Remove ref worktrees",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				repo, ref, err := cfg.parseRepoRef(arg)
+				if err != nil {
+					return err
+				}
+				if err := cfg.removeWorktree(cmd.Context(), repo, ref); err != nil {
+					return err
+				}
+				fmt.Printf("✓ Removed %s@%s\n", repo.name, ref)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Release completeness gate: the dist must hold the full artifact matrix
+
+// releaseConfig describes the artifact matrix a release must contain, e.g.
+//
+//	"release": {"platforms": ["linux/amd64", "darwin/arm64"], "optional": ["gcdeck"]}
+//
+// Without platforms only the host platform is expected. Optional binaries
+// (typically cgo UI viewers built on other machines) may be missing.
+type releaseConfig struct {
+	Platforms []string `json:"platforms"` // GOOS/GOARCH pairs for native binaries
+	Optional  []string `json:"optional"`  // binaries allowed to be absent
+}
+
+func (r releaseConfig) validate() error {
+	for _, p := range r.Platforms {
+		if goos, goarch, ok := strings.Cut(p, "/"); !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("release.platforms: %q is not GOOS/GOARCH", p)
+		}
+	}
+	return nil
+}
+
+func (r releaseConfig) platforms() []string {
+	if len(r.Platforms) == 0 {
+		return []string{runtime.GOOS + "/" + runtime.GOARCH}
+	}
+	return r.Platforms
+}
+
+// expectedArtifacts returns the release filenames for every toolchain
+// binary on every supported target.
+func (cfg *config) expectedArtifacts() []string {
+	var expected []string
+	for _, spec := range cfg.toolchain {
+		if slices.Contains(cfg.file.Release.Optional, spec.name) {
+			continue
+		}
+		for _, p := range cfg.file.Release.platforms() {
+			goos, goarch, _ := strings.Cut(p, "/")
+			expected = append(expected, platformFilename(spec.name, goos, goarch))
+		}
+		if spec.wasmSupport {
+			expected = append(expected, cfg.buildFilename(spec.name, targetWASM))
+		}
+		if spec.wasiSupport {
+			expected = append(expected, cfg.buildFilename(spec.name, targetWASI))
+		}
+	}
+	return expected
+}
+
+// checkReleaseCompleteness fails when any expected artifact is missing.
+func (cfg *config) checkReleaseCompleteness(artifacts []string) error {
+	present := make(map[string]bool)
+	for _, path := range artifacts {
+		present[filepath.Base(path)] = true
+	}
+	var missing []string
+	for _, name := range cfg.expectedArtifacts() {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Printf("✓ All %d expected artifacts present\n", len(cfg.expectedArtifacts()))
+		return nil
+	}
+	fmt.Printf("✗ %d expected artifacts missing from %s:\n", len(missing), cfg.distDir)
+	for _, name := range missing {
+		fmt.Printf("    %s\n", name)
+	}
+	return fmt.Errorf("incomplete release: %d artifacts missing (see \"release\" in %s to change the matrix)", len(missing), configFile)
+}
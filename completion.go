@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Shell completion for example names
+
+func (cfg *config) exampleCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	groups, err := cfg.examplesBySource()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	// Registered corpora complete like the built-in repos
+	corpora, _ := loadRegistry()
+	for _, c := range corpora {
+		groups[c.Name] = c.deckNames()
+	}
+
+	suggestions := make(map[string]struct{})
+
+	if strings.Contains(toComplete, "/") {
+		parts := strings.SplitN(toComplete, "/", 2)
+		src := parts[0]
+		partial := ""
+		if len(parts) > 1 {
+			partial = parts[1]
+		}
+		if names, ok := groups[src]; ok {
+			for _, name := range names {
+				if strings.HasPrefix(name, partial) {
+					suggestions[src+"/"+name] = struct{}{}
+				}
+			}
+		}
+	} else {
+		lower := strings.ToLower(toComplete)
+		for src, names := range groups {
+			prefix := src + "/"
+			if strings.HasPrefix(strings.ToLower(prefix), lower) || toComplete == "" {
+				suggestions[prefix] = struct{}{}
+			}
+			for _, name := range names {
+				candidate := src + "/" + name
+				if strings.HasPrefix(strings.ToLower(candidate), lower) || strings.HasPrefix(strings.ToLower(name), lower) {
+					suggestions[candidate] = struct{}{}
+					if src == "deckviz" {
+						suggestions[name] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	// Aliases and favorites are listed first below
+	for name := range cfg.shortcuts.Aliases {
+		delete(suggestions, name)
+	}
+	for _, fav := range cfg.shortcuts.Favorites {
+		delete(suggestions, fav)
+	}
+	var matches []string
+	for suggestion := range suggestions {
+		matches = append(matches, suggestion)
+	}
+	sort.Strings(matches)
+	return append(cfg.shortcutCompletion(toComplete), matches...), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// shortcutCompletion offers the user's aliases and then favorites ahead of
+// the example names.
+func (cfg *config) shortcutCompletion(toComplete string) []string {
+	lower := strings.ToLower(toComplete)
+	var out []string
+	for _, name := range cfg.shortcuts.aliasNames() {
+		if strings.HasPrefix(name, lower) {
+			out = append(out, name+"\t"+cfg.shortcuts.Aliases[name])
+		}
+	}
+	for _, fav := range cfg.shortcuts.Favorites {
+		_, name := cfg.parseExample(fav)
+		if strings.HasPrefix(strings.ToLower(fav), lower) || strings.HasPrefix(strings.ToLower(name), lower) {
+			out = append(out, fav+"\tfavorite")
+		}
+	}
+	return out
+}
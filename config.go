@@ -1,9 +1,6 @@
 package main
 
-import (
-	"fmt"
-	"strings"
-)
+import "time"
 
 // =============================================================================
 // Constants
@@ -11,10 +8,21 @@ import (
 
 // Directory structure constants
 const (
-	dataDir  = ".data"
-	srcDir   = ".src"
-	distDir  = ".dist"
-	fontsDir = ".fonts"
+	dataDir    = ".data"
+	srcDir     = ".src"
+	distDir    = ".dist"
+	fontsDir   = ".fonts"
+	testDir    = ".test"
+	jobsDir    = ".jobs"
+	archiveDir = ".archive"
+	snippetDir = ".snippets"
+	trashDir   = ".trash"
+)
+
+// Project files (read from the working directory)
+const (
+	configFile   = "decktool.json" // optional structured settings
+	lockFileName = "decktool.lock" // pinned repo SHAs, test baseline, toolchain channel
 )
 
 // =============================================================================
@@ -36,18 +44,22 @@ type binSpec struct {
 	wasmSupport bool
 	wasiSupport bool
 	requiresUI  bool
+	uiDeps      []string // pkg-config modules required for the cgo build on Linux
 }
 
 type buildResult struct {
-	binary string
-	target buildTarget
-	path   string
-	err    error
+	binary   string
+	target   buildTarget
+	path     string
+	log      string // build output captured for this artifact
+	duration time.Duration
+	err      error
 }
 
 type repoConfig struct {
 	name      string
 	url       string
+	upstream  string // canonical ajstarks URL; differs from url for forks
 	dir       string
 	branch    string
 	depth     int
@@ -56,17 +68,47 @@ type repoConfig struct {
 	sparseRaw string
 	sparse    []string
 	isData    bool
+
+	sparseOnDemand bool     // check example directories out as they are used (see sparse.go)
+	shared         []string // directories on-demand checkouts always include
+	skipLFS        bool     // leave Git LFS files as pointers (<NAME>_LFS=skip)
+	skipSubmodules bool     // do not check out submodules (<NAME>_SUBMODULES=skip)
 }
 
 type config struct {
-	goCmd     string
-	gitCmd    string
-	goBinDir  string
-	distDir   string // absolute path to dist directory
-	fontsDir  string // absolute path to fonts directory
-	repos     map[string]*repoConfig
-	fontsRepo *repoConfig // deckfonts repo (managed separately)
-	toolchain []binSpec
+	goCmd       string
+	gitCmd      string
+	goBinDir    string
+	distDir     string // absolute path to dist directory
+	fontsDir    string // absolute path to fonts directory
+	testDir     string // absolute path to test output directory
+	jobsDir     string // absolute path to the serve job store
+	archiveDir  string // absolute path to the run snapshot archive
+	snippetsDir string // absolute path to the decksh snippet library (DECKTOOL_SNIPPETS or .snippets)
+	trashDir    string // absolute path to the trash dev clean moves folders into
+	cacheDir    string // shared cache of releases and mirrors (DECKTOOL_CACHE), "" if unset
+	mirrorsDir  string // bare repo mirrors checkouts are cloned from (see mirrors.go), "" if disabled
+	repos       map[string]*repoConfig
+	fontsRepo   *repoConfig // deckfonts repo (managed separately)
+	toolchain   []binSpec
+	file        fileConfig // settings from configFile
+	keepTemp    bool       // keep temp render workspaces for debugging (--keep-temp)
+	printEnv    bool       // print the environment of each child process (--print-env)
+	devMode     bool       // running a "dev" command: maintainer defaults (see devmode.go)
+	outDir      string     // shared output directory for rendered XML (run --out), "" to leave it in place
+	goWork      string     // go.work for builds (GOWORK), "" for .src/go.work
+
+	acceptNewSigner bool              // re-pin a changed release signing key (--accept-new-signer)
+	forceDownload   bool              // replace binaries even when local copies are newer (channel switch)
+	corpusWorkers   []string          // render the corpus in shards on these workers (test --workers)
+	changedOnly     bool              // skip examples that passed with the same inputs and toolchain (test --changed-only)
+	refreshData     bool              // fetch data connector results even when cached (run --refresh-data)
+	dirtyRepos      string            // what updates do with local work in clones: refuse (""), stash or force (DECKTOOL_DIRTY)
+	deckVars        map[string]string // decksh variables set in every rendered script (run --suite)
+	toolEnv         []string          // KEY=value pairs added to the deck tools' environment (run --suite)
+	triage          bool              // keep tool output of failed corpus renders for triage (test at a terminal)
+
+	shortcuts exampleShortcuts // the user's example aliases and favorites
 }
 
 // =============================================================================
@@ -83,81 +125,3 @@ func (t buildTarget) buildEnv() (goos, goarch string) {
 		return "", ""
 	}
 }
-
-
-// =============================================================================
-// Config Loading and Initialization
-// =============================================================================
-
-func loadConfig() (*config, error) {
-	cfg := &config{
-		goCmd:  getenvDefault("GO", "go"),
-		gitCmd: getenvDefault("GIT", "git"),
-		repos:  make(map[string]*repoConfig),
-	}
-
-	// Initialize repositories and toolchain
-	cfg.initDataRepos()
-	cfg.initCodeRepos()
-	cfg.initToolchain()
-	if err := cfg.initFontsRepo(); err != nil {
-		return nil, err
-	}
-
-	// Resolve go bin directory
-	binDir, err := resolveGoBin(cfg.goCmd)
-	if err != nil {
-		return nil, err
-	}
-	cfg.goBinDir = binDir
-
-	return cfg, nil
-}
-
-func (cfg *config) initToolchain() {
-	cfg.toolchain = []binSpec{
-		// decksh tools
-		{name: "decksh", pkg: "github.com/ajstarks/decksh/cmd/decksh", repo: "decksh", wasmSupport: true, wasiSupport: true},
-		{name: "dshfmt", pkg: "github.com/ajstarks/decksh/cmd/dshfmt", repo: "decksh", wasmSupport: true, wasiSupport: true},
-		{name: "dshlint", pkg: "github.com/ajstarks/decksh/cmd/dshlint", repo: "decksh", wasmSupport: true, wasiSupport: true},
-
-		// deck tools
-		{name: "pdfdeck", pkg: "github.com/ajstarks/deck/cmd/pdfdeck", repo: "deck", wasmSupport: true, wasiSupport: true},
-		{name: "pngdeck", pkg: "github.com/ajstarks/deck/cmd/pngdeck", repo: "deck", wasmSupport: true, wasiSupport: true},
-		{name: "svgdeck", pkg: "github.com/ajstarks/deck/cmd/svgdeck", repo: "deck", wasmSupport: true, wasiSupport: true},
-
-		// gift tools
-		{name: "gift", pkg: "github.com/ajstarks/gift", repo: "gift", wasmSupport: true, wasiSupport: true},
-		{name: "giftsh", pkg: "github.com/ajstarks/giftsh", repo: "giftsh", wasmSupport: true, wasiSupport: true},
-
-		// UI apps (native only)
-		{name: "ebdeck", pkg: "github.com/ajstarks/ebcanvas/ebdeck", repo: "ebcanvas", requiresUI: true},
-		{name: "gcdeck", pkg: "github.com/ajstarks/giocanvas/gcdeck", repo: "giocanvas", requiresUI: true},
-	}
-}
-
-func (cfg *config) finalize() error {
-	// Resolve all repo directories to absolute paths
-	for _, repo := range cfg.repos {
-		var err error
-		if repo.dir, err = absPath(repo.dir); err != nil {
-			return fmt.Errorf("resolve %s dir: %w", repo.name, err)
-		}
-		repo.filter = strings.Fields(strings.TrimSpace(repo.filterRaw))
-		repo.sparse = strings.Fields(strings.TrimSpace(repo.sparseRaw))
-	}
-
-	// Resolve dist directory to absolute path
-	var err error
-	if cfg.distDir, err = absPath(distDir); err != nil {
-		return fmt.Errorf("resolve dist dir: %w", err)
-	}
-
-	// Resolve fonts repo directory to absolute path
-	if cfg.fontsRepo.dir, err = absPath(cfg.fontsRepo.dir); err != nil {
-		return fmt.Errorf("resolve fonts dir: %w", err)
-	}
-	cfg.fontsDir = cfg.fontsRepo.dir
-
-	return nil
-}
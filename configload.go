@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/joeblew999/deck-test/pkg/toolchain"
+)
+
+// =============================================================================
+// Config Loading and Initialization
+// =============================================================================
+
+func loadConfig() (*config, error) {
+	cfg := &config{
+		goCmd:  getenvDefault("GO", "go"),
+		gitCmd: getenvDefault("GIT", "git"),
+		repos:  make(map[string]*repoConfig),
+	}
+
+	file, err := loadFileConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.file = file
+
+	// Initialize repositories and toolchain
+	cfg.initDataRepos()
+	cfg.initCodeRepos()
+	cfg.initToolchain()
+	if err := cfg.initFontsRepo(); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyCloneFilters(); err != nil {
+		return nil, err
+	}
+
+	// Resolve go bin directory
+	binDir, err := toolchain.GoBinDir(cfg.goCmd)
+	if err != nil {
+		return nil, err
+	}
+	cfg.goBinDir = binDir
+
+	// Shared multi-user cache is opt-in
+	if dir := os.Getenv("DECKTOOL_CACHE"); dir != "" {
+		if !fileLocking {
+			return nil, fmt.Errorf("DECKTOOL_CACHE is not supported on %s: concurrent writers need file locks", runtime.GOOS)
+		}
+		if cfg.cacheDir, err = expandPath(dir); err != nil {
+			return nil, fmt.Errorf("resolve DECKTOOL_CACHE: %w", err)
+		}
+	}
+
+	// Repos are cloned from local mirrors unless DECKTOOL_MIRRORS=off
+	if cfg.mirrorsDir, err = cfg.resolveMirrorsDir(); err != nil {
+		return nil, err
+	}
+
+	// Local work in the clones is kept unless told to stash or discard it
+	switch cfg.dirtyRepos = os.Getenv("DECKTOOL_DIRTY"); cfg.dirtyRepos {
+	case dirtyRefuse, dirtyStash, dirtyForce:
+	default:
+		return nil, fmt.Errorf("DECKTOOL_DIRTY: %q is not stash or force", cfg.dirtyRepos)
+	}
+
+	return cfg, nil
+}
+
+func (cfg *config) initToolchain() {
+	cfg.toolchain = []binSpec{
+		// decksh tools
+		{name: "decksh", pkg: "github.com/ajstarks/decksh/cmd/decksh", repo: "decksh", wasmSupport: true, wasiSupport: true},
+		{name: "dshfmt", pkg: "github.com/ajstarks/decksh/cmd/dshfmt", repo: "decksh", wasmSupport: true, wasiSupport: true},
+		{name: "dshlint", pkg: "github.com/ajstarks/decksh/cmd/dshlint", repo: "decksh", wasmSupport: true, wasiSupport: true},
+
+		// deck tools
+		{name: "pdfdeck", pkg: "github.com/ajstarks/deck/cmd/pdfdeck", repo: "deck", wasmSupport: true, wasiSupport: true},
+		{name: "pngdeck", pkg: "github.com/ajstarks/deck/cmd/pngdeck", repo: "deck", wasmSupport: true, wasiSupport: true},
+		{name: "svgdeck", pkg: "github.com/ajstarks/deck/cmd/svgdeck", repo: "deck", wasmSupport: true, wasiSupport: true},
+
+		// gift tools
+		{name: "gift", pkg: "github.com/ajstarks/gift", repo: "gift", wasmSupport: true, wasiSupport: true},
+		{name: "giftsh", pkg: "github.com/ajstarks/giftsh", repo: "giftsh", wasmSupport: true, wasiSupport: true},
+
+		// UI apps (native only)
+		{name: "ebdeck", pkg: "github.com/ajstarks/ebcanvas/ebdeck", repo: "ebcanvas", requiresUI: true,
+			uiDeps: []string{"x11", "xcursor", "xrandr", "xinerama", "xi", "xxf86vm", "gl"}},
+		{name: "gcdeck", pkg: "github.com/ajstarks/giocanvas/gcdeck", repo: "giocanvas", requiresUI: true,
+			uiDeps: []string{"wayland-client", "wayland-egl", "wayland-cursor", "xkbcommon", "xkbcommon-x11", "x11-xcb", "egl", "vulkan", "xcursor", "xfixes"}},
+	}
+}
+
+func (cfg *config) finalize() error {
+	// Resolve all repo directories to absolute paths
+	for _, repo := range cfg.repos {
+		var err error
+		if repo.dir, err = absPath(repo.dir); err != nil {
+			return fmt.Errorf("resolve %s dir: %w", repo.name, err)
+		}
+		repo.filter = strings.Fields(strings.TrimSpace(repo.filterRaw))
+		repo.sparse = strings.Fields(strings.TrimSpace(repo.sparseRaw))
+		repo.skipLFS = os.Getenv(strings.ToUpper(repo.name)+"_LFS") == "skip"
+		repo.skipSubmodules = os.Getenv(strings.ToUpper(repo.name)+"_SUBMODULES") == "skip"
+	}
+
+	// Resolve dist directory to absolute path
+	var err error
+	if cfg.distDir, err = absPath(distDir); err != nil {
+		return fmt.Errorf("resolve dist dir: %w", err)
+	}
+
+	// Resolve test output directory to absolute path
+	if cfg.testDir, err = absPath(testDir); err != nil {
+		return fmt.Errorf("resolve test dir: %w", err)
+	}
+
+	// Resolve job store directory to absolute path
+	if cfg.jobsDir, err = absPath(jobsDir); err != nil {
+		return fmt.Errorf("resolve jobs dir: %w", err)
+	}
+
+	// Resolve snapshot archive directory to absolute path
+	if cfg.archiveDir, err = absPath(archiveDir); err != nil {
+		return fmt.Errorf("resolve archive dir: %w", err)
+	}
+
+	// Resolve trash directory to absolute path
+	if cfg.trashDir, err = absPath(trashDir); err != nil {
+		return fmt.Errorf("resolve trash dir: %w", err)
+	}
+
+	// The snippet library may be shared between projects
+	if dir := os.Getenv("DECKTOOL_SNIPPETS"); dir != "" {
+		if cfg.snippetsDir, err = expandPath(dir); err != nil {
+			return fmt.Errorf("resolve DECKTOOL_SNIPPETS: %w", err)
+		}
+	} else if cfg.snippetsDir, err = absPath(snippetDir); err != nil {
+		return fmt.Errorf("resolve snippets dir: %w", err)
+	}
+
+	// Resolve fonts repo directory to absolute path
+	if cfg.fontsRepo.dir, err = absPath(cfg.fontsRepo.dir); err != nil {
+		return fmt.Errorf("resolve fonts dir: %w", err)
+	}
+	cfg.fontsDir = cfg.fontsRepo.dir
+
+	if err := cfg.checkHookRepos(); err != nil {
+		return err
+	}
+
+	// Example aliases and favorites are per user
+	if cfg.shortcuts, err = loadShortcuts(); err != nil {
+		return err
+	}
+
+	return nil
+}
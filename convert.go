@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// Deck conversion with the deck output tools (pdfdeck, pngdeck, svgdeck).
+// The tools themselves run through pkg/render; this adds tracing, source
+// mapping of errors and artifact records.
+
+// renderer runs this workspace's tools with the command's tool output;
+// tool names the one it will run for --print-env.
+func (cfg *config) renderer(ctx context.Context, tool string) render.Renderer {
+	stdout, stderr := toolOutput(ctx)
+	recordToolUse()
+	env := cfg.childEnv(envTools)
+	cfg.showEnv(envTools, []string{tool}, env)
+	return render.Renderer{Tools: cfg.tools(), Env: env, Stdout: stdout, Stderr: stderr}
+}
+
+// toolStderr is what a failed deck tool wrote to stderr.
+func toolStderr(err error) string {
+	var toolErr *render.ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Stderr
+	}
+	return ""
+}
+
+// convertDeck converts a rendered deck XML into format next to the XML.
+// Relative assets in the deck resolve against dir.
+func (cfg *config) convertDeck(ctx context.Context, dir, xmlPath, format string) (err error) {
+	ctx, span := startSpan(ctx, "convert", "deck.format", format, "deck.xml", xmlPath)
+	defer func() { span.end(err) }()
+
+	if err := cfg.renderer(ctx, render.Converters[format]).Convert(ctx, dir, xmlPath, format); err != nil {
+		// The deck's script sits next to its XML (source.xml from source.dsh)
+		if xml, rerr := os.ReadFile(xmlPath); rerr == nil {
+			stdout, _ := toolOutput(ctx)
+			err = annotateToolError(stdout, err, strings.TrimSuffix(xmlPath, ".xml")+".dsh", toolStderr(err), 0, xml)
+		}
+		return err
+	}
+	if format == "pdf" {
+		recordArtifact(render.ConvertedPath(xmlPath, format))
+	} else {
+		pages, _ := filepath.Glob(strings.TrimSuffix(xmlPath, ".xml") + "-*." + format)
+		for _, page := range pages {
+			recordArtifact(page)
+		}
+	}
+	return nil
+}
+
+// convertFormats converts a rendered deck to each format at once; the
+// converters write separate files, so they are independent. Each one's tool
+// output is held back and printed in format order when all are done.
+func (cfg *config) convertFormats(ctx context.Context, dir, xmlPath string, formats []string) error {
+	if len(formats) == 1 {
+		return cfg.convertDeck(ctx, dir, xmlPath, formats[0])
+	}
+	outputs := make([]bytes.Buffer, len(formats))
+	errs := make([]error, len(formats))
+	var wg sync.WaitGroup
+	for i, format := range formats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = cfg.convertDeck(withToolOutput(ctx, &outputs[i]), dir, xmlPath, format)
+		}()
+	}
+	wg.Wait()
+	stdout, _ := toolOutput(ctx)
+	for i := range outputs {
+		outputs[i].WriteTo(stdout)
+	}
+	return errors.Join(errs...)
+}
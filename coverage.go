@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Language coverage: which decksh keywords the corpus and the feature decks
+// exercise, measured against the keywords the decksh parser accepts
+// (extracted in coveragegrammar.go, printed by coveragereport.go)
+
+// keywordCoverage is one grammar keyword and where it is used.
+type keywordCoverage struct {
+	Keyword  string   `json:"keyword"`
+	Examples []string `json:"examples,omitempty"` // corpus examples using it
+	Features []string `json:"features,omitempty"` // feature decks using it
+}
+
+type coverageReport struct {
+	Grammar  string            `json:"grammar"` // decksh revision the keywords come from
+	Examples int               `json:"examples"`
+	Keywords []keywordCoverage `json:"keywords"`
+}
+
+// languageCoverage cross-references the corpus and the feature decks with
+// the grammar of the checked-out decksh source.
+func (cfg *config) languageCoverage(ctx context.Context) (*coverageReport, error) {
+	decksh := cfg.repos["decksh"]
+	if _, err := os.Stat(filepath.Join(decksh.dir, ".git")); err != nil {
+		if err := cfg.gitCloneOrUpdate(ctx, decksh); err != nil {
+			return nil, fmt.Errorf("check out decksh source: %w", err)
+		}
+	}
+	grammar, err := deckshGrammar(decksh.dir)
+	if err != nil {
+		return nil, err
+	}
+	report := &coverageReport{Grammar: "decksh"}
+	if sha, err := cfg.gitHead(ctx, decksh); err == nil {
+		report.Grammar += "@" + shortSHA(sha)
+	}
+
+	byKeyword := make(map[string]*keywordCoverage, len(grammar))
+	for _, kw := range grammar {
+		report.Keywords = append(report.Keywords, keywordCoverage{Keyword: kw})
+	}
+	for i := range report.Keywords {
+		byKeyword[report.Keywords[i].Keyword] = &report.Keywords[i]
+	}
+
+	examples, err := cfg.listExamples()
+	if err != nil {
+		return nil, err
+	}
+	for _, example := range examples {
+		source, name := cfg.parseExample(example)
+		dir, err := cfg.getExampleDir(source, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(cfg.getExampleDshPath(dir, name))
+		if err != nil {
+			continue // directory without a deck
+		}
+		used, err := deckKeywords(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", example, err)
+		}
+		report.Examples++
+		for kw := range used {
+			if c, ok := byKeyword[kw]; ok {
+				c.Examples = append(c.Examples, example)
+			}
+		}
+	}
+	for _, name := range listFeatureDecks() {
+		f, err := featureDecks.Open("features/" + name + ".dsh")
+		if err != nil {
+			return nil, err
+		}
+		used, err := deckKeywords(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("features/%s: %w", name, err)
+		}
+		for kw := range used {
+			if c, ok := byKeyword[kw]; ok {
+				c.Features = append(c.Features, name)
+			}
+		}
+	}
+	return report, nil
+}
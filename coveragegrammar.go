@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keywords of the decksh grammar (from its source) and of a deck
+
+// deckshGrammar collects the keywords decksh dispatches on: the string cases
+// of every switch over a line's first token (switch tokens[0]) in its source.
+func deckshGrammar(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			sw, ok := n.(*ast.SwitchStmt)
+			if !ok || !isFirstToken(sw.Tag) {
+				return true
+			}
+			for _, stmt := range sw.Body.List {
+				for _, expr := range stmt.(*ast.CaseClause).List {
+					if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						if kw, err := strconv.Unquote(lit.Value); err == nil && kw != "" {
+							seen[kw] = true
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("no keyword switch found in %s; has the decksh parser changed?", relToCwd(dir))
+	}
+	keywords := make([]string, 0, len(seen))
+	for kw := range seen {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+	return keywords, nil
+}
+
+// isFirstToken matches expressions of the form <ident>[0].
+func isFirstToken(expr ast.Expr) bool {
+	index, ok := expr.(*ast.IndexExpr)
+	if !ok {
+		return false
+	}
+	_, isIdent := index.X.(*ast.Ident)
+	lit, isLit := index.Index.(*ast.BasicLit)
+	return isIdent && isLit && lit.Value == "0"
+}
+
+// deckKeywords returns the first token of every non-comment line.
+func deckKeywords(r io.Reader) (map[string]bool, error) {
+	used := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		used[strings.Fields(line)[0]] = true
+	}
+	return used, scanner.Err()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Printing the language coverage report
+
+// printCoverage summarizes coverage and lists the keywords no feature deck
+// exercises: corpus-only ones first (most used first), then untested ones.
+func printCoverage(w io.Writer, report *coverageReport) {
+	var inCorpus, inFeatures int
+	var corpusOnly, untested []keywordCoverage
+	for _, c := range report.Keywords {
+		if len(c.Examples) > 0 {
+			inCorpus++
+		}
+		if len(c.Features) > 0 {
+			inFeatures++
+			continue
+		}
+		if len(c.Examples) > 0 {
+			corpusOnly = append(corpusOnly, c)
+		} else {
+			untested = append(untested, c)
+		}
+	}
+	total := len(report.Keywords)
+	fmt.Fprintf(w, "=== decksh language coverage (%d keywords in %s) ===\n", total, report.Grammar)
+	fmt.Fprintf(w, "Corpus:        %d/%d keywords (%d examples)\n", inCorpus, total, report.Examples)
+	fmt.Fprintf(w, "Feature decks: %d/%d keywords (%d decks)\n", inFeatures, total, len(listFeatureDecks()))
+
+	if len(corpusOnly) > 0 {
+		sort.SliceStable(corpusOnly, func(i, j int) bool { return len(corpusOnly[i].Examples) > len(corpusOnly[j].Examples) })
+		fmt.Fprintf(w, "\nUsed by the corpus but not by a feature deck (%d):\n", len(corpusOnly))
+		for _, c := range corpusOnly {
+			fmt.Fprintf(w, "  ⊘ %-12s %d example(s), e.g. %s\n", c.Keyword, len(c.Examples), c.Examples[0])
+		}
+	}
+	if len(untested) > 0 {
+		fmt.Fprintf(w, "\nNot exercised at all (%d):\n", len(untested))
+		for _, c := range untested {
+			fmt.Fprintf(w, "  ✗ %s\n", c.Keyword)
+		}
+	}
+	if len(corpusOnly)+len(untested) > 0 {
+		fmt.Fprintln(w, "\nAdd a feature deck (features/<construct>.dsh) for these to catch regressions by construct.")
+	}
+}
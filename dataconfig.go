@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// dataConfig lists the connectors of the project's decks.
+type dataConfig struct {
+	TTL     string       `json:"ttl"` // default for sources without one (1h)
+	Sources []dataSource `json:"sources"`
+}
+
+// dataSource is one data file and the query that produces it.
+type dataSource struct {
+	Deck   string   `json:"deck"`   // example (source/name) whose directory gets the file
+	File   string   `json:"file"`   // file name relative to the example directory
+	SQL    string   `json:"sql"`    // query run against DB with sqlite3
+	DB     string   `json:"db"`     // SQLite database, relative to the project
+	HTTP   string   `json:"http"`   // URL returning CSV, TSV or JSON
+	Format string   `json:"format"` // csv, tsv or json (default: from Content-Type)
+	Header bool     `json:"header"` // drop the first CSV/TSV row
+	Fields []string `json:"fields"` // JSON object keys to write as columns, in order
+	TTL    string   `json:"ttl"`    // how long a pull is reused
+}
+
+func (c dataConfig) validate() error {
+	if c.TTL != "" {
+		if _, err := time.ParseDuration(c.TTL); err != nil {
+			return fmt.Errorf("data.ttl: %w", err)
+		}
+	}
+	for i, src := range c.Sources {
+		if src.Deck == "" {
+			return fmt.Errorf("data.sources[%d]: deck is required", i)
+		}
+		if err := src.validate(); err != nil {
+			return fmt.Errorf("data.sources[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s dataSource) validate() error {
+	switch {
+	case (s.SQL == "") == (s.HTTP == ""):
+		return errors.New("exactly one of sql and http is required")
+	case s.SQL != "" && s.DB == "":
+		return errors.New("sql needs a db")
+	case s.File == "":
+		return errors.New("file is required")
+	case filepath.IsAbs(s.File) || !filepath.IsLocal(s.File):
+		return fmt.Errorf("file %q must be relative to the example directory", s.File)
+	}
+	switch s.Format {
+	case "", "csv", "tsv", "json":
+	default:
+		return fmt.Errorf("unknown format %q (csv, tsv or json)", s.Format)
+	}
+	if s.TTL != "" {
+		if _, err := time.ParseDuration(s.TTL); err != nil {
+			return fmt.Errorf("ttl: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s dataSource) ttl(fallback string) time.Duration {
+	for _, v := range []string{s.TTL, fallback} {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultDataTTL
+}
+
+func (s dataSource) describe() string {
+	if s.SQL != "" {
+		return "sql " + s.DB
+	}
+	return s.HTTP
+}
+
+// cacheKey identifies a source's result; the output format is part of it
+// because the cache holds the encoded file.
+func (s dataSource) cacheKey() string {
+	spec, _ := json.Marshal([]any{s.SQL, s.DB, s.HTTP, s.Format, s.Header, s.Fields, dataOutputCSV(s.File)})
+	sum := sha256.Sum256(spec)
+	return hex.EncodeToString(sum[:])[:16]
+}
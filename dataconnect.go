@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Data connectors: fetch the data files a deck reads (dchart input and the
+// like) from a SQLite query or an HTTP CSV/JSON endpoint before it renders.
+// Results are cached in .dist/data and reused for the source's TTL, so
+// renders stay fast and work offline; a failed fetch falls back to the
+// stale copy.
+
+const defaultDataTTL = time.Hour
+
+// pullData writes a source's result to out, fetching it unless a cached
+// copy younger than ttl exists (refresh always fetches). It reports
+// whether a fetch happened.
+func (cfg *config) pullData(ctx context.Context, src dataSource, out string, ttl time.Duration, refresh bool) (bool, error) {
+	stdout, _ := toolOutput(ctx)
+	cached := filepath.Join(cfg.getDataCacheDir(), src.cacheKey())
+	if info, err := os.Stat(cached); err == nil && !refresh && time.Since(info.ModTime()) < ttl {
+		return false, copyDataFile(cached, out)
+	}
+	rows, err := cfg.fetchRows(ctx, src)
+	if err != nil {
+		if _, statErr := os.Stat(cached); statErr == nil {
+			fmt.Fprintf(stdout, "⚠ %s: %v; using the cached copy\n", src.describe(), err)
+			return false, copyDataFile(cached, out)
+		}
+		return false, err
+	}
+	data, err := encodeRows(rows, dataOutputCSV(out))
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cached), 0o755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(cached, data, 0o644); err != nil {
+		return false, err
+	}
+	return true, writeDataFile(out, data)
+}
+
+// pullExampleData refreshes the configured data files of one example.
+func (cfg *config) pullExampleData(ctx context.Context, example, dir string, refresh bool) error {
+	stdout, _ := toolOutput(ctx)
+	for _, src := range cfg.file.Data.Sources {
+		if src.Deck != example {
+			continue
+		}
+		fetched, err := cfg.pullData(ctx, src, filepath.Join(dir, src.File), src.ttl(cfg.file.Data.TTL), refresh)
+		if err != nil {
+			return fmt.Errorf("pull %s for %s: %w", src.File, example, err)
+		}
+		if fetched {
+			fmt.Fprintf(stdout, "Pulled %s from %s\n", src.File, src.describe())
+		}
+	}
+	return nil
+}
+
+// dataOutputs returns the files the data connectors write, for telling
+// them from local edits in the clones.
+func (cfg *config) dataOutputs() []string {
+	var paths []string
+	for _, src := range cfg.file.Data.Sources {
+		source, name := cfg.parseExample(src.Deck)
+		if dir, err := cfg.getExampleDir(source, name); err == nil {
+			paths = append(paths, filepath.Join(dir, src.File))
+		}
+	}
+	return paths
+}
+
+// fetchRows runs a source's query.
+func (cfg *config) fetchRows(ctx context.Context, src dataSource) ([][]string, error) {
+	if src.SQL != "" {
+		return querySQLite(ctx, src.DB, src.SQL)
+	}
+	return fetchHTTPRows(ctx, src)
+}
+
+func copyDataFile(cached, out string) error {
+	data, err := os.ReadFile(cached)
+	if err != nil {
+		return err
+	}
+	if current, err := os.ReadFile(out); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return writeDataFile(out, data)
+}
+
+func writeDataFile(out string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		return err
+	}
+	recordArtifact(out)
+	return nil
+}
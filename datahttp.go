@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// HTTP data connector: CSV, TSV or JSON endpoints
+
+const maxDataPullBytes = 50 << 20
+
+// fetchHTTPRows downloads a CSV, TSV or JSON endpoint, taking the format
+// from the source or else the response's Content-Type.
+func fetchHTTPRows(ctx context.Context, src dataSource) ([][]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.HTTP, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, text/csv, text/tab-separated-values;q=0.9, */*;q=0.5")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDataPullBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxDataPullBytes {
+		return nil, fmt.Errorf("response exceeds %d MB", maxDataPullBytes>>20)
+	}
+	format := src.Format
+	if format == "" {
+		format = "csv"
+		switch mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); {
+		case strings.HasSuffix(mediaType, "json"):
+			format = "json"
+		case mediaType == "text/tab-separated-values":
+			format = "tsv"
+		}
+	}
+	if format == "json" {
+		return jsonRows(body, src.Fields)
+	}
+	return delimitedRows(body, format == "tsv", src.Header)
+}
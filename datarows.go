@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Decoding fetched rows and encoding the data files decks read
+
+// dataOutputCSV reports whether a data file is written as CSV; everything
+// else is tab separated, the format dchart reads.
+func dataOutputCSV(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".csv")
+}
+
+func delimitedRows(data []byte, tabs, header bool) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	if tabs {
+		r.Comma = '\t'
+		r.LazyQuotes = true
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if header && len(rows) > 0 {
+		rows = rows[1:]
+	}
+	return rows, nil
+}
+
+// jsonRows reads an array of arrays, or of objects whose fields are picked
+// in order.
+func jsonRows(data []byte, fields []string) ([][]string, error) {
+	var items []any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("JSON: expected an array of rows: %w", err)
+	}
+	rows := make([][]string, 0, len(items))
+	for i, item := range items {
+		var row []string
+		switch v := item.(type) {
+		case []any:
+			for _, cell := range v {
+				row = append(row, jsonCell(cell))
+			}
+		case map[string]any:
+			if len(fields) == 0 {
+				return nil, errors.New("JSON: rows are objects; set fields to the keys to write")
+			}
+			for _, f := range fields {
+				row = append(row, jsonCell(v[f]))
+			}
+		default:
+			return nil, fmt.Errorf("JSON: row %d is neither an array nor an object", i)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func jsonCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func encodeRows(rows [][]string, asCSV bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if asCSV {
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(rows); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(cell)
+		}
+		buf.WriteString(strings.Join(row, "\t") + "\n")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SQLite data connector
+
+// querySQLite runs a read-only query with the sqlite3 shell (SQLITE3).
+func querySQLite(ctx context.Context, db, query string) ([][]string, error) {
+	if _, err := os.Stat(db); err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, getenvDefault("SQLITE3", "sqlite3"), "-readonly", "-batch", "-bail", "-csv", "-noheader", db, query)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("sqlite3: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return delimitedRows(stdout.Bytes(), false, false)
+}
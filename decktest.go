@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Deck render tests: feature decks plus the example corpus
+
+type deckTestResult struct {
+	name     string
+	xml      string // rendered deck XML
+	err      error
+	metrics  *renderMetrics
+	duration time.Duration // lint, render and PDF conversion
+	inputs   string        // render cache key of a corpus example's directory
+	skipped  bool          // passed before with the same inputs and toolchain (--changed-only)
+	output   string        // lint and render output, kept for triage
+	checked  bool          // checkOutput already ran, alongside the next render
+	diffs    []visualDiff  // slides that differ from golden images
+}
+
+// deckTestSummary is the outcome of a run over all suites.
+type deckTestSummary struct {
+	total, passed, known, failed int
+	failures                     []string     // decks that failed outside the baseline
+	triage                       []triageItem // corpus examples among them
+	pending                      []visualDiff
+	run                          metricsRun
+	previous                     *metricsRun // last run of a different toolchain
+}
+
+func (s deckTestSummary) String() string {
+	text := fmt.Sprintf("%d/%d decks passed", s.passed, s.total)
+	if s.known > 0 {
+		text += fmt.Sprintf(", %d known failure(s)", s.known)
+	}
+	if s.failed > 0 {
+		text += fmt.Sprintf(", %d failed", s.failed)
+	}
+	return text
+}
+
+// runDeckTests renders the feature decks and, unless featuresOnly, the
+// corpus, then prints a pass/fail report per suite. Failures listed in known
+// (the lockfile baseline) are reported but do not fail the run.
+func (cfg *config) runDeckTests(ctx context.Context, featuresOnly bool, known map[string]bool) (deckTestSummary, error) {
+	suites := []struct {
+		name string
+		run  func(context.Context) ([]deckTestResult, error)
+	}{
+		{"Feature decks", cfg.testFeatureDecks},
+		{"Corpus", cfg.testCorpus},
+	}
+	if len(cfg.corpusWorkers) > 0 {
+		suites[1].run = func(ctx context.Context) ([]deckTestResult, error) {
+			return cfg.distributedCorpus(ctx, cfg.corpusWorkers)
+		}
+	}
+	if featuresOnly {
+		suites = suites[:1]
+	}
+
+	var summary deckTestSummary
+	var reports []string
+	var pending []visualDiff
+	run := metricsRun{Toolchain: cfg.toolchainID(), Time: time.Now().UTC(), Decks: make(map[string]renderMetrics)}
+	for _, suite := range suites {
+		results, err := suite.run(ctx)
+		if err != nil {
+			return summary, fmt.Errorf("%s: %w", suite.name, err)
+		}
+		pending = append(pending, cfg.checkOutputs(ctx, results)...)
+		if err := cfg.updateTestSnapshot(results); err != nil {
+			fmt.Printf("⚠ Test snapshot not updated: %v\n", err)
+		}
+		passed, skipped := 0, 0
+		report := ""
+		for _, result := range results {
+			switch {
+			case result.skipped:
+				passed++
+				skipped++
+			case result.err == nil:
+				passed++
+			case known[result.name]:
+				report += fmt.Sprintf("  ⊘ %s: known failure: %v\n", result.name, result.err)
+				summary.known++
+			default:
+				report += fmt.Sprintf("  ✗ %s: %v\n", result.name, result.err)
+				summary.failed++
+				summary.failures = append(summary.failures, fmt.Sprintf("%s: %v", result.name, result.err))
+				if suite.name == "Corpus" {
+					summary.triage = append(summary.triage, triageItem{example: result.name, err: result.err, output: result.output})
+				}
+			}
+			if result.metrics != nil {
+				run.Decks[result.name] = *result.metrics
+			}
+		}
+		summary.total += len(results)
+		summary.passed += passed
+		line := fmt.Sprintf("%s: %d/%d passed", suite.name, passed, len(results))
+		if skipped > 0 {
+			line += fmt.Sprintf(" (%d unchanged since their last pass, not re-run)", skipped)
+		}
+		reports = append(reports, line+"\n"+report)
+	}
+
+	fmt.Println("\n=== Test Results ===")
+	for _, report := range reports {
+		fmt.Print(report)
+	}
+	summary.run, summary.pending = run, pending
+	previous, err := cfg.reportMetrics(run)
+	if err != nil {
+		fmt.Printf("⚠ Metrics not recorded: %v\n", err)
+	}
+	summary.previous = previous
+	if err := cfg.savePendingDiffs(pending); err != nil {
+		return summary, fmt.Errorf("save visual diffs: %w", err)
+	}
+	if len(pending) > 0 {
+		fmt.Printf("\n%d slide(s) differ from golden images; review with: decktool test approve\n", len(pending))
+	}
+	if summary.failed > 0 {
+		return summary, fmt.Errorf("%d deck(s) failed", summary.failed)
+	}
+	return summary, nil
+}
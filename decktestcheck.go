@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Checks of rendered test decks: properties, PDF metrics and golden images
+
+// checkOutputs runs the property checks on each rendered deck, converts it
+// to PDF (collecting output metrics) and PNG (comparing slides with golden
+// images). A violation, a failed conversion or a visual difference fails the
+// deck; the differences are returned for review.
+func (cfg *config) checkOutputs(ctx context.Context, results []deckTestResult) []visualDiff {
+	var pending []visualDiff
+	for i := range results {
+		r := &results[i]
+		if !r.checked {
+			cfg.checkOutput(ctx, r)
+		}
+		pending = append(pending, r.diffs...)
+	}
+	return pending
+}
+
+// checkOutput checks one deck for checkOutputs. The PDF and PNG
+// conversions run at once.
+func (cfg *config) checkOutput(ctx context.Context, r *deckTestResult) {
+	r.checked = true
+	if r.err != nil || r.xml == "" { // failed, or rendered by a worker
+		return
+	}
+	if r.err = cfg.propertyError(r.name, r.xml); r.err != nil {
+		return
+	}
+	var measureErr, goldenErr error
+	var pdfOutput bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		measureErr = cfg.measureOutput(withToolOutput(ctx, &pdfOutput), r)
+	}()
+	r.diffs, goldenErr = cfg.compareGolden(ctx, r.name, r.xml)
+	<-done
+	stdout, _ := toolOutput(ctx)
+	pdfOutput.WriteTo(stdout)
+	switch {
+	case measureErr != nil:
+		r.err, r.diffs = measureErr, nil
+	case goldenErr != nil:
+		r.err = fmt.Errorf("golden: %w", goldenErr)
+	case len(r.diffs) > 0:
+		r.err = fmt.Errorf("%d slide(s) differ from golden images", len(r.diffs))
+	}
+}
+
+// measureOutput converts a rendered deck to PDF and records its metrics.
+func (cfg *config) measureOutput(ctx context.Context, r *deckTestResult) error {
+	start := time.Now()
+	if err := cfg.convertDeck(ctx, filepath.Dir(r.xml), r.xml, "pdf"); err != nil {
+		return fmt.Errorf("convert: %w", err)
+	}
+	r.duration += time.Since(start)
+	if m, err := measureRender(r.xml); err == nil {
+		m.RenderMS = r.duration.Milliseconds()
+		r.metrics = &m
+	}
+	return nil
+}
+
+// reportMetrics prints output totals and changes since the previous
+// toolchain, and returns that toolchain's run (nil if none).
+func (cfg *config) reportMetrics(run metricsRun) (*metricsRun, error) {
+	var total renderMetrics
+	for _, m := range run.Decks {
+		total.XMLBytes += m.XMLBytes
+		total.PDFBytes += m.PDFBytes
+		total.PDFPages += m.PDFPages
+		total.Slides += m.Slides
+	}
+	fmt.Printf("\n=== Output Metrics (toolchain %s) ===\n", run.Toolchain)
+	fmt.Printf("%d decks, %d slides, XML %s, PDF %s, %d pages\n",
+		len(run.Decks), total.Slides, formatSize(total.XMLBytes), formatSize(total.PDFBytes), total.PDFPages)
+
+	previous, err := cfg.recordMetrics(run)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		fmt.Println("No previous toolchain recorded to compare with")
+		return nil, nil
+	}
+	findings := compareMetrics(*previous, run)
+	fmt.Printf("Compared with toolchain %s (%s): ", previous.Toolchain, previous.Time.Local().Format(time.DateTime))
+	if len(findings) == 0 {
+		fmt.Println("no output changes")
+		return previous, nil
+	}
+	fmt.Printf("%d change(s)\n", len(findings))
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	return previous, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// testCorpus renders every example. With --changed-only, examples that last
+// passed with the same inputs and toolchain are reported from the snapshot
+// instead of re-rendered.
+func (cfg *config) testCorpus(ctx context.Context) ([]deckTestResult, error) {
+	examples, err := cfg.listExamples()
+	if err != nil {
+		return nil, err
+	}
+	var snapshot testSnapshot
+	var toolchain string
+	if cfg.changedOnly {
+		if snapshot, err = cfg.loadTestSnapshot(); err != nil {
+			return nil, err
+		}
+		if toolchain, err = cfg.snapshotToolchain(); err != nil {
+			return nil, err
+		}
+	}
+	var results []*deckTestResult
+	retest := make(map[string]int) // reason -> examples re-rendered for it
+	retested := 0
+	// Each rendered deck is checked (and converted) while the next renders
+	var checks convertPipeline
+	for _, example := range examples {
+		inputs, err := cfg.exampleInputs(example)
+		if err != nil {
+			inputs = "" // rendering reports the problem
+		}
+		if cfg.changedOnly && inputs != "" {
+			unchanged, reason := snapshot.unchangedSince(example, inputs, toolchain)
+			if unchanged {
+				results = append(results, &deckTestResult{name: example, skipped: true, metrics: snapshot.Examples[example].Metrics})
+				continue
+			}
+			retest[reason]++
+			retested++
+		}
+		start := time.Now()
+		renderCtx := ctx
+		var output bytes.Buffer
+		if cfg.triage {
+			renderCtx = captureToolOutput(ctx, &output)
+		}
+		xmlPath, err := cfg.renderExample(renderCtx, example)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // directory without a deck of the same name
+		}
+		result := &deckTestResult{name: example, xml: xmlPath, err: err, duration: time.Since(start), inputs: inputs}
+		if err != nil {
+			result.output = output.String()
+		} else {
+			checks.add(ctx, func(ctx context.Context) { cfg.checkOutput(ctx, result) })
+		}
+		results = append(results, result)
+	}
+	checks.wait()
+	if cfg.changedOnly {
+		fmt.Printf("Changed only: re-tested %d example(s) (%d inputs changed, %d toolchain changed, %d not passed before)\n",
+			retested, retest["inputs changed"], retest["toolchain changed"], retest["not passed before"])
+	}
+	out := make([]deckTestResult, len(results))
+	for i, r := range results {
+		out[i] = *r
+	}
+	return out, nil
+}
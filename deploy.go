@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Gallery deployment
+//
+// gh-pages commits the gallery onto the gh-pages branch of the project's
+// origin remote (DECKTOOL_PAGES_REMOTE overrides it); pushing goes through
+// git, so its configured credential helper or SSH key applies. dir://<path>
+// mirrors the gallery into a directory, e.g. a web server's document root.
+
+const pagesBranch = "gh-pages"
+
+// deployGallery publishes the gallery in dir to target.
+func (cfg *config) deployGallery(ctx context.Context, dir, target string) error {
+	switch {
+	case target == pagesBranch:
+		return cfg.deployGHPages(ctx, dir)
+	case strings.HasPrefix(target, "dir://"):
+		dest := strings.TrimPrefix(target, "dir://")
+		if dest == "" {
+			return errors.New("dir:// needs a path, e.g. dir:///var/www/gallery")
+		}
+		if err := syncDir(dir, dest); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Deployed gallery to %s\n", dest)
+		return nil
+	default:
+		return fmt.Errorf("unknown deploy target %q (want %s or dir://<path>)", target, pagesBranch)
+	}
+}
+
+func (cfg *config) deployGHPages(ctx context.Context, dir string) error {
+	remote := os.Getenv("DECKTOOL_PAGES_REMOTE")
+	if remote == "" {
+		var err error
+		if remote, err = cfg.gitOutput(ctx, "remote", "get-url", "origin"); err != nil {
+			return fmt.Errorf("no origin remote to deploy to (set DECKTOOL_PAGES_REMOTE): %w", err)
+		}
+	}
+	work, err := os.MkdirTemp("", "decktool-pages-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	// Build on top of the existing branch so its history is kept.
+	if _, err := cfg.gitOutput(ctx, "ls-remote", "--exit-code", "--heads", remote, pagesBranch); err == nil {
+		if err := cfg.runGit(ctx, "clone", "--quiet", "--depth", "1", "--branch", pagesBranch, remote, work); err != nil {
+			return err
+		}
+	} else {
+		if err := cfg.runGit(ctx, "init", "--quiet", work); err != nil {
+			return err
+		}
+		if err := cfg.runGit(ctx, "-C", work, "checkout", "--quiet", "--orphan", pagesBranch); err != nil {
+			return err
+		}
+	}
+	if err := syncDir(dir, work); err != nil {
+		return err
+	}
+	// GitHub Pages would otherwise run the site through Jekyll.
+	if err := os.WriteFile(filepath.Join(work, ".nojekyll"), nil, 0o644); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", work, "add", "--all"); err != nil {
+		return err
+	}
+	if status, err := cfg.gitOutput(ctx, "-C", work, "status", "--porcelain"); err != nil {
+		return err
+	} else if status == "" {
+		fmt.Printf("✓ %s is already up to date\n", pagesBranch)
+		return nil
+	}
+	if err := cfg.runGit(ctx, "-C", work, "commit", "--quiet", "-m", "Update gallery"); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", work, "push", "--quiet", remote, "HEAD:"+pagesBranch); err != nil {
+		return fmt.Errorf("push %s: %w", pagesBranch, err)
+	}
+	fmt.Printf("✓ Deployed gallery to %s of %s\n", pagesBranch, remote)
+	return nil
+}
+
+// syncDir makes dest mirror src: files are copied and files no longer in
+// src are removed. Dot files in dest (.git, .nojekyll) are left alone and
+// dot files in src (build state) are not published.
+func syncDir(src, dest string) error {
+	keep := make(map[string]bool)
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if rel != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		keep[rel] = true
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dest, rel), 0o755)
+		}
+		return copyFile(path, filepath.Join(dest, rel))
+	})
+	if err != nil {
+		return err
+	}
+	var stale []string
+	err = filepath.WalkDir(dest, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dest, path)
+		if rel == "." {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !keep[rel] {
+			stale = append(stale, path)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
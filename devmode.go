@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Toolchain development mode. Building, releasing and auditing the deck
+// toolchain lives under "decktool dev", apart from the commands presentation
+// authors use, and runs with stricter defaults there:
+//
+//   - dev build enforces the module graph recorded in decktool.lock, as
+//     --frozen does (the first build records one)
+//   - dev release verifies the published release against its provenance
+//
+// The "dev" section of the config file relaxes them for a workspace:
+//
+//	"dev": {"unlocked": true, "no_verify": true}
+//
+// The old top-level names (dev-build, audit, ...) still work, with the old
+// defaults, and print where the command moved.
+
+type devConfig struct {
+	Unlocked bool `json:"unlocked"`  // dev build: accept module graph changes, as without --frozen
+	NoVerify bool `json:"no_verify"` // dev release: skip release verify after publishing
+}
+
+// lockedBuilds reports whether builds enforce the recorded module graph
+// unless --frozen says otherwise.
+func (cfg *config) lockedBuilds() bool {
+	return cfg.devMode && !cfg.file.Dev.Unlocked
+}
+
+// verifiedReleases reports whether dev release checks what it published.
+func (cfg *config) verifiedReleases() bool {
+	return cfg.devMode && !cfg.file.Dev.NoVerify
+}
+
+// defaultFrozen is --frozen's value when not given: on in dev mode once
+// decktool.lock records a module graph.
+func (cfg *config) defaultFrozen() (bool, error) {
+	if !cfg.lockedBuilds() {
+		return false, nil
+	}
+	lock, err := loadLockFile()
+	if err != nil {
+		return false, err
+	}
+	return lock.Modules != nil, nil
+}
+
+func newDevCommand(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Build, release and audit the deck toolchain (maintainers)",
+		Long: `Commands for maintaining the deck toolchain itself: building the tools from
+the code repos in .src, publishing releases and auditing what they link.
+Presenting and rendering decks needs none of them; ensure downloads the
+released tools.
+
+Defaults here are stricter than for the deck commands:
+  dev build     enforces the module graph in decktool.lock (--frozen); the
+                first build records it, --frozen=false accepts changes
+  dev release   verifies the published release against its provenance
+                (see "release verify")
+
+Set "dev": {"unlocked": true} or {"no_verify": true} in decktool.json to
+relax them for a workspace.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg.devMode = true
+			return cfg.finalize()
+		},
+	}
+	cmd.AddCommand(newDevBuildCommand(cfg))
+	cmd.AddCommand(newDevReleaseCommand(cfg))
+	cmd.AddCommand(newDevCleanCommand(cfg))
+	cmd.AddCommand(newDistCommand(cfg))
+	cmd.AddCommand(newAuditCommand(cfg))
+	cmd.AddCommand(newLicensesCommand(cfg))
+	cmd.AddCommand(newFuzzCommand(cfg))
+	cmd.AddCommand(newWorktreeCommand(cfg))
+	return cmd
+}
+
+// movedToDev registers a command that now lives under "dev" at its old
+// top-level name, hidden, with a note on where it went.
+func movedToDev(cmd *cobra.Command, oldName string) *cobra.Command {
+	cmd.Deprecated = fmt.Sprintf("use \"decktool dev %s\"", cmd.Name())
+	cmd.Use = oldName + strings.TrimPrefix(cmd.Use, cmd.Name())
+	return cmd
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+)
+
+// Embedding snippets for deployed gallery pages
+//
+// Snippets point at a published gallery (see gallery --deploy): an iframe of
+// the example page whose fallback content is the linked thumbnail, or for
+// READMEs, where iframes are stripped, just the linked thumbnail.
+
+// galleryBaseURL is where the gallery is published: DECKTOOL_GALLERY_URL,
+// or the GitHub Pages site of the origin remote.
+func (cfg *config) galleryBaseURL(ctx context.Context) (string, error) {
+	if url := os.Getenv("DECKTOOL_GALLERY_URL"); url != "" {
+		return strings.TrimSuffix(url, "/"), nil
+	}
+	remote, err := cfg.gitOutput(ctx, "remote", "get-url", "origin")
+	if err == nil {
+		if url, ok := pagesURL(remote); ok {
+			return url, nil
+		}
+	}
+	return "", errors.New("cannot tell where the gallery is published; pass --base-url or set DECKTOOL_GALLERY_URL")
+}
+
+// githubRepo extracts owner and repo from a GitHub remote URL.
+func githubRepo(remote string) (owner, repo string, ok bool) {
+	path, ok := strings.CutPrefix(canonicalRepoURL(remote), "github.com/")
+	if !ok {
+		return "", "", false
+	}
+	owner, repo, ok = strings.Cut(path, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+	return owner, repo, true
+}
+
+// pagesURL maps a GitHub remote URL to its GitHub Pages site.
+func pagesURL(remote string) (string, bool) {
+	owner, repo, ok := githubRepo(remote)
+	if !ok {
+		return "", false
+	}
+	if strings.EqualFold(repo, owner+".github.io") {
+		return "https://" + owner + ".github.io", true
+	}
+	return "https://" + owner + ".github.io/" + repo, true
+}
+
+// embedSnippet renders the snippet for entry in the given format.
+func embedSnippet(entry catalogEntry, base, format string) (string, error) {
+	page := base + "/" + entry.ID + ".html"
+	image := base + "/thumbs/" + entry.ID + ".png"
+	alt := entry.Name
+	if entry.Title != "" {
+		alt = entry.Title
+	}
+	switch format {
+	case "html":
+		return fmt.Sprintf(`<iframe src="%s" title="%s" width="800" height="600" style="border:0" loading="lazy">
+  <a href="%s"><img src="%s" alt="%s"></a>
+</iframe>`, html.EscapeString(page), html.EscapeString(alt), html.EscapeString(page), html.EscapeString(image), html.EscapeString(alt)), nil
+	case "markdown":
+		alt = strings.NewReplacer("[", `\[`, "]", `\]`).Replace(alt)
+		return fmt.Sprintf("[![%s](%s)](%s)", alt, image, page), nil
+	default:
+		return "", fmt.Errorf("unknown format %q (want html or markdown)", format)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Child process environment. Every program decktool starts with an
+// environment of its own gets it here, by kind, so what a kind of child
+// needs (DECKFONTS for the deck tools, the "go" section and GOWORK for go)
+// is set on every code path that starts one. Other programs (git, gh)
+// inherit decktool's environment unchanged. --print-env shows what each
+// child gets on top of decktool's own environment.
+
+type envKind int
+
+const (
+	envTools  envKind = iota // deck tools: DECKFONTS at the managed fonts, and a suite's env
+	envGo                    // go: module settings from the "go" section
+	envGoWork                // go in the .src workspace: envGo plus GOWORK
+	envShell                 // toolchain shell and repo hooks: deck tools and go alike
+)
+
+var envKindNames = map[envKind]string{
+	envTools:  "deck tool",
+	envGo:     "go",
+	envGoWork: "go in workspace",
+	envShell:  "shell",
+}
+
+// childEnv returns decktool's environment with what kind needs set, then
+// extra. A KEY=value pair replaces earlier values of KEY; a bare KEY
+// removes it.
+func (cfg *config) childEnv(kind envKind, extra ...string) []string {
+	var set []string
+	if kind == envTools || kind == envShell {
+		set = append(set, "DECKFONTS="+cfg.fontsDir)
+	}
+	if kind == envTools {
+		set = append(set, cfg.toolEnv...)
+	}
+	if kind != envTools {
+		set = append(set, cfg.file.Go.env()...)
+	}
+	if kind == envGoWork && cfg.goWork != "" {
+		set = append(set, "GOWORK="+cfg.goWork)
+	}
+	return mergeEnv(os.Environ(), append(set, extra...)...)
+}
+
+// useEnv gives cmd the environment of kind, printing it with --print-env.
+func (cfg *config) useEnv(cmd *exec.Cmd, kind envKind, extra ...string) {
+	cmd.Env = cfg.childEnv(kind, extra...)
+	cfg.showEnv(kind, append([]string{filepath.Base(cmd.Path)}, cmd.Args[1:]...), cmd.Env)
+}
+
+// mergeEnv applies set to env in order.
+func mergeEnv(env []string, set ...string) []string {
+	env = slices.Clone(env)
+	for _, kv := range set {
+		key, _, hasValue := strings.Cut(kv, "=")
+		env = slices.DeleteFunc(env, func(e string) bool {
+			k, _, _ := strings.Cut(e, "=")
+			return k == key
+		})
+		if hasValue {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+var printedEnvs sync.Map
+
+// showEnv prints, for --print-env, how env differs from decktool's own
+// environment. Each command and environment is printed once, so tools run
+// in a loop do not repeat it.
+func (cfg *config) showEnv(kind envKind, argv []string, env []string) {
+	if !cfg.printEnv {
+		return
+	}
+	lines := envChanges(os.Environ(), env)
+	key := strings.Join(argv, " ") + "\x00" + strings.Join(lines, "\x00")
+	if _, seen := printedEnvs.LoadOrStore(key, true); seen {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "env (%s) %s\n", envKindNames[kind], strings.Join(argv, " "))
+	for _, l := range lines {
+		fmt.Fprintf(&b, "  %s\n", l)
+	}
+	if len(lines) == 0 {
+		b.WriteString("  (decktool's environment, unchanged)\n")
+	}
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// envChanges lists the variables env sets differently from base, then the
+// ones it removes (as -KEY), each sorted.
+func envChanges(base, env []string) []string {
+	values := func(env []string) map[string]string {
+		m := make(map[string]string, len(env))
+		for _, kv := range env {
+			k, v, _ := strings.Cut(kv, "=")
+			m[k] = v
+		}
+		return m
+	}
+	before, after := values(base), values(env)
+	var set, unset []string
+	for k, v := range after {
+		if old, ok := before[k]; !ok || old != v {
+			set = append(set, k+"="+v)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			unset = append(unset, "-"+k)
+		}
+	}
+	slices.Sort(set)
+	slices.Sort(unset)
+	return append(set, unset...)
+}
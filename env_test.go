@@ -0,0 +1,144 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMergeEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		set  []string
+		want []string
+	}{
+		{"adds", []string{"A=1"}, []string{"B=2"}, []string{"A=1", "B=2"}},
+		{"replaces", []string{"A=1", "B=2"}, []string{"A=3"}, []string{"B=2", "A=3"}},
+		{"replaces duplicates", []string{"A=1", "A=2"}, []string{"A=3"}, []string{"A=3"}},
+		{"bare key removes", []string{"A=1", "B=2"}, []string{"A"}, []string{"B=2"}},
+		{"bare key of unset variable", []string{"A=1"}, []string{"B"}, []string{"A=1"}},
+		{"empty value is kept", []string{"A=1"}, []string{"A="}, []string{"A="}},
+		{"later set wins", []string{}, []string{"A=1", "A=2"}, []string{"A=2"}},
+		{"remove then set", []string{"A=1"}, []string{"A", "A=2"}, []string{"A=2"}},
+		{"set then remove", []string{}, []string{"A=1", "A"}, nil},
+		{"value with equals", []string{"A=1"}, []string{"A=x=y"}, []string{"A=x=y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := slices.Clone(tt.env)
+			got := mergeEnv(env, tt.set...)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("mergeEnv(%q, %q) = %q, want %q", tt.env, tt.set, got, tt.want)
+			}
+			if !slices.Equal(env, tt.env) {
+				t.Errorf("mergeEnv modified its input: %q", env)
+			}
+		})
+	}
+}
+
+func TestEnvChanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		base, env []string
+		want      []string
+	}{
+		{"unchanged", []string{"A=1", "B=2"}, []string{"B=2", "A=1"}, nil},
+		{"added", []string{"A=1"}, []string{"A=1", "C=3", "B=2"}, []string{"B=2", "C=3"}},
+		{"changed", []string{"A=1"}, []string{"A=2"}, []string{"A=2"}},
+		{"removed", []string{"A=1", "B=2", "C=3"}, []string{"B=2"}, []string{"-A", "-C"}},
+		{"set before removed", []string{"A=1", "B=2"}, []string{"B=3", "C=4"}, []string{"B=3", "C=4", "-A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envChanges(tt.base, tt.env); !slices.Equal(got, tt.want) {
+				t.Errorf("envChanges(%q, %q) = %q, want %q", tt.base, tt.env, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChildEnv(t *testing.T) {
+	t.Setenv("DECKFONTS", "/inherited/fonts")
+	t.Setenv("GOWORK", "/inherited/go.work")
+	t.Setenv("GOPROXY", "https://inherited.example")
+	cfg := &config{
+		fontsDir: "/ws/.fonts",
+		goWork:   "/ws/.src/go.work",
+		toolEnv:  []string{"SUITE=1"},
+		file:     fileConfig{Go: goConfig{Proxy: "https://proxy.example"}},
+	}
+	tests := []struct {
+		kind envKind
+		want map[string]string // "" asserts the inherited value
+	}{
+		{envTools, map[string]string{
+			"DECKFONTS": "/ws/.fonts", "SUITE": "1",
+			"GOWORK": "/inherited/go.work", "GOPROXY": "https://inherited.example",
+		}},
+		{envGo, map[string]string{
+			"DECKFONTS": "/inherited/fonts", "SUITE": "",
+			"GOWORK": "/inherited/go.work", "GOPROXY": "https://proxy.example",
+		}},
+		{envGoWork, map[string]string{
+			"DECKFONTS": "/inherited/fonts", "SUITE": "",
+			"GOWORK": "/ws/.src/go.work", "GOPROXY": "https://proxy.example",
+		}},
+		{envShell, map[string]string{
+			"DECKFONTS": "/ws/.fonts", "SUITE": "",
+			"GOWORK": "/inherited/go.work", "GOPROXY": "https://proxy.example",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(envKindNames[tt.kind], func(t *testing.T) {
+			env := cfg.childEnv(tt.kind)
+			for key, want := range tt.want {
+				got, ok := lookupEnv(env, key)
+				if want == "" {
+					if ok {
+						t.Errorf("%s = %q, want unset", key, got)
+					}
+					continue
+				}
+				if got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			if n := countEnv(env, "DECKFONTS"); n != 1 {
+				t.Errorf("DECKFONTS set %d times", n)
+			}
+		})
+	}
+}
+
+func TestChildEnvExtra(t *testing.T) {
+	t.Setenv("DECKFONTS", "/inherited/fonts")
+	cfg := &config{fontsDir: "/ws/.fonts"}
+	env := cfg.childEnv(envShell, "DECKTOOL_HOOK=post_sync", "DECKFONTS")
+	if v, ok := lookupEnv(env, "DECKTOOL_HOOK"); !ok || v != "post_sync" {
+		t.Errorf("DECKTOOL_HOOK = %q, want post_sync", v)
+	}
+	if v, ok := lookupEnv(env, "DECKFONTS"); ok {
+		t.Errorf("DECKFONTS = %q, want removed by extra", v)
+	}
+}
+
+func lookupEnv(env []string, key string) (string, bool) {
+	for _, kv := range slices.Backward(env) {
+		if k, v, _ := strings.Cut(kv, "="); k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func countEnv(env []string, key string) int {
+	n := 0
+	for _, kv := range env {
+		if k, _, _ := strings.Cut(kv, "="); k == key {
+			n++
+		}
+	}
+	return n
+}
@@ -1,15 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/spf13/cobra"
 )
 
 func (cfg *config) listExamples() ([]string, error) {
@@ -31,51 +31,58 @@ func (cfg *config) examplesBySource() (map[string][]string, error) {
 	result := make(map[string][]string)
 	for name, repo := range cfg.repos {
 		// Only include data repos that contain examples
-		if !repo.isData {
+		if !repo.isData || repo == cfg.fontsRepo {
 			continue
 		}
-		result[name] = collectExampleNames(repo.dir)
+		if cfg.onDemand(repo) {
+			result[name] = cfg.treeExampleNames(repo)
+		} else {
+			result[name] = collectExampleNames(repo.dir)
+		}
 	}
 	return result, nil
 }
 
+// runExamples renders the examples, returning XML paths by exampleKey.
+// With an output directory (run --out) the XML is copied there as
+// <source>/<name>.xml and that copy is returned.
 func (cfg *config) runExamples(ctx context.Context, examples []string) (map[string]string, error) {
-	// Set DECKFONTS for all child processes
-	// NOTE: Due to a quirk with how Go's os.Setenv() interacts with some binaries,
-	// DECKFONTS may need to be exported in the shell before running decktool for
-	// the view/run commands to work properly. The ensure command prints the export.
-	oldDeckfonts := os.Getenv("DECKFONTS")
-	os.Setenv("DECKFONTS", cfg.fontsDir)
-	defer func() {
-		if oldDeckfonts != "" {
-			os.Setenv("DECKFONTS", oldDeckfonts)
-		} else {
-			os.Unsetenv("DECKFONTS")
-		}
-	}()
-
+	examples = cfg.uniqueExamples(examples)
+	if err := checkCollisions(examples, cfg.outputName); err != nil {
+		return nil, err
+	}
 	results := make(map[string]string)
 	for _, raw := range examples {
-		source, name := cfg.parseExample(raw)
-		dir, err := cfg.getExampleDir(source, name)
-		if err != nil {
-			return nil, err
+		key := cfg.exampleKey(raw)
+		if isAdHocDeck(raw) {
+			xmlPath, err := cfg.renderAdHoc(ctx, raw)
+			if err != nil {
+				return nil, err
+			}
+			results[key] = xmlPath
+			continue
 		}
-		dshPath := cfg.getExampleDshPath(dir, name)
-		if _, err := os.Stat(dshPath); err != nil {
-			fmt.Printf("Skipping %s: %v\n", cfg.normalizeExampleName(raw), err)
+		xmlPath, err := cfg.renderExample(ctx, raw)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("Skipping %s: %v\n", key, err)
 			continue
 		}
-
-		if err := cfg.runTool(ctx, dir, "dshlint", name+".dsh"); err != nil {
+		if err != nil {
 			return nil, err
 		}
-
-		xmlPath := cfg.getExampleXmlPath(dir, name)
-		if err := cfg.renderDeck(ctx, dir, name+".dsh", xmlPath); err != nil {
-			return nil, err
+		cfg.warnPropertyViolations(key, xmlPath)
+		if cfg.outDir != "" {
+			out := cfg.outputPath(cfg.outDir, raw)
+			if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+				return nil, err
+			}
+			if err := copyFile(xmlPath, out); err != nil {
+				return nil, err
+			}
+			recordArtifact(out)
+			xmlPath = out
 		}
-		results[cfg.normalizeExampleName(raw)] = xmlPath
+		results[key] = xmlPath
 	}
 
 	if len(results) == 0 {
@@ -84,89 +91,79 @@ func (cfg *config) runExamples(ctx context.Context, examples []string) (map[stri
 	return results, nil
 }
 
-func (cfg *config) renderDeck(ctx context.Context, dir, script, output string) error {
-	fmt.Printf("Rendering %s -> %s\n", script, output)
-	deckshPath, err := cfg.resolveBinary("decksh")
+// renderExample lints and renders one example, returning the XML path.
+// A missing .dsh file is reported as os.ErrNotExist.
+func (cfg *config) renderExample(ctx context.Context, raw string) (string, error) {
+	if err := cfg.materializeExample(ctx, raw); err != nil {
+		return "", err
+	}
+	source, name := cfg.parseExample(raw)
+	dir, err := cfg.getExampleDir(source, name)
 	if err != nil {
-		return err
+		return "", err
+	}
+	dshPath := cfg.getExampleDshPath(dir, name)
+	if _, err := os.Stat(dshPath); err != nil {
+		return "", err
 	}
 
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return err
+	if err := cfg.pullExampleData(ctx, source+"/"+name, dir, cfg.refreshData); err != nil {
+		return "", err
 	}
-	file, err := os.Create(output)
-	if err != nil {
-		return err
+	if err := cfg.lintDeck(ctx, dir, name+".dsh"); err != nil {
+		return "", err
 	}
-	defer file.Close()
 
-	cmd := exec.CommandContext(ctx, deckshPath, script)
-	cmd.Dir = dir
-	cmd.Stdout = file
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
-func (cfg *config) runTool(ctx context.Context, dir, tool, arg string) error {
-	path, err := cfg.resolveBinary(tool)
-	if err != nil {
-		return err
+	xmlPath := cfg.getExampleXmlPath(dir, name)
+	if err := cfg.renderDeck(ctx, dir, name+".dsh", xmlPath); err != nil {
+		return "", err
 	}
-	fmt.Printf("Linting %s/%s\n", dir, arg)
-	cmd := exec.CommandContext(ctx, path, arg)
-	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return xmlPath, nil
 }
 
-func (cfg *config) exampleCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	groups, err := cfg.examplesBySource()
+func (cfg *config) renderDeck(ctx context.Context, dir, script, output string) (err error) {
+	ctx, span := startSpan(ctx, "decksh", "deck.script", script, "deck.output", output)
+	defer func() { span.end(err) }()
+
+	stdout, _ := toolOutput(ctx)
+	fmt.Fprintf(stdout, "Rendering %s -> %s\n", script, output)
+	// Library snippet includes are rewritten and the script piped in, so
+	// relative includes still resolve against dir
+	resolved, ok, err := cfg.resolveSnippetIncludes(dir, script)
 	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
+		return err
 	}
-
-	suggestions := make(map[string]struct{})
-
-	if strings.Contains(toComplete, "/") {
-		parts := strings.SplitN(toComplete, "/", 2)
-		src := parts[0]
-		partial := ""
-		if len(parts) > 1 {
-			partial = parts[1]
-		}
-		if names, ok := groups[src]; ok {
-			for _, name := range names {
-				if strings.HasPrefix(name, partial) {
-					suggestions[src+"/"+name] = struct{}{}
-				}
-			}
-		}
-	} else {
-		lower := strings.ToLower(toComplete)
-		for src, names := range groups {
-			prefix := src + "/"
-			if strings.HasPrefix(strings.ToLower(prefix), lower) || toComplete == "" {
-				suggestions[prefix] = struct{}{}
-			}
-			for _, name := range names {
-				candidate := src + "/" + name
-				if strings.HasPrefix(strings.ToLower(candidate), lower) || strings.HasPrefix(strings.ToLower(name), lower) {
-					suggestions[candidate] = struct{}{}
-					if src == "deckviz" {
-						suggestions[name] = struct{}{}
-					}
-				}
+	if len(cfg.deckVars) > 0 {
+		if !ok {
+			if resolved, err = os.ReadFile(filepath.Join(dir, script)); err != nil {
+				return err
 			}
 		}
+		resolved, ok = applyDeckVars(resolved, cfg.deckVars), true
 	}
 
-	var matches []string
-	for suggestion := range suggestions {
-		matches = append(matches, suggestion)
+	var src io.Reader
+	offset := 0
+	if ok {
+		src = bytes.NewReader(resolved)
+		if original, err := os.ReadFile(filepath.Join(dir, script)); err == nil {
+			offset = lineOffset(original, resolved)
+		}
+	}
+	if err := cfg.renderer(ctx, "decksh").Render(ctx, dir, script, src, output); err != nil {
+		return annotateToolError(stdout, err, filepath.Join(dir, script), toolStderr(err), offset, nil)
 	}
-	sort.Strings(matches)
-	return matches, cobra.ShellCompDirectiveNoFileComp
+	recordArtifact(output)
+	return nil
+}
+
+func (cfg *config) lintDeck(ctx context.Context, dir, script string) (err error) {
+	ctx, span := startSpan(ctx, "dshlint", "deck.script", script)
+	defer func() { span.end(err) }()
+
+	stdout, _ := toolOutput(ctx)
+	fmt.Fprintf(stdout, "Linting %s/%s\n", dir, script)
+	return cfg.renderer(ctx, "dshlint").Lint(ctx, dir, script)
 }
 
 func collectExampleNames(root string) []string {
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Feature decks: one tiny embedded deck per decksh construct
+
+//go:embed features/*.dsh
+var featureDecks embed.FS
+
+func listFeatureDecks() []string {
+	entries, err := featureDecks.ReadDir("features")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".dsh"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeFeatureDecks copies the embedded decks into the feature test directory.
+func (cfg *config) writeFeatureDecks() error {
+	dir := cfg.getFeatureDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+	for _, name := range listFeatureDecks() {
+		data, err := featureDecks.ReadFile("features/" + name + ".dsh")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".dsh"), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// testFeatureDecks lints and renders every feature deck, one result per deck.
+func (cfg *config) testFeatureDecks(ctx context.Context) ([]deckTestResult, error) {
+	if err := cfg.writeFeatureDecks(); err != nil {
+		return nil, err
+	}
+	dir := cfg.getFeatureDir()
+	var results []deckTestResult
+	for _, name := range listFeatureDecks() {
+		result := deckTestResult{name: "features/" + name, xml: cfg.getExampleXmlPath(dir, name)}
+		start := time.Now()
+		if err := cfg.lintDeck(ctx, dir, name+".dsh"); err != nil {
+			result.err = fmt.Errorf("lint: %w", err)
+		} else if err := cfg.renderDeck(ctx, dir, name+".dsh", result.xml); err != nil {
+			result.err = fmt.Errorf("render: %w", err)
+		}
+		result.duration = time.Since(start)
+		results = append(results, result)
+	}
+	return results, nil
+}
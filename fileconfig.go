@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Project config file loading (decktool.json)
+//
+// Environment variables cover single values (repo URLs, branches). Settings
+// that need structure live in the optional config file, one section per feature.
+
+type fileConfig struct {
+	Budgets  budgetConfig   `json:"budgets"`
+	Build    buildConfig    `json:"build"`
+	Checks   checksConfig   `json:"checks"`
+	Clone    cloneConfig    `json:"clone"`
+	Data     dataConfig     `json:"data"`
+	Dev      devConfig      `json:"dev"`
+	Go       goConfig       `json:"go"`
+	Hooks    hooksConfig    `json:"hooks"`
+	Licenses licensesConfig `json:"licenses"`
+	Notify   notifyConfig   `json:"notify"`
+	Release  releaseConfig  `json:"release"`
+	Schedule scheduleConfig `json:"schedule"`
+	Serve    serveConfig    `json:"serve"`
+	Style    styleConfig    `json:"style"`
+	Suites   suitesConfig   `json:"suites"`
+}
+
+// loadFileConfig reads the config file; a missing file yields defaults.
+func loadFileConfig(path string) (fileConfig, error) {
+	var fc fileConfig
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fc, nil
+	}
+	if err != nil {
+		return fc, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return fc, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := fc.validate(); err != nil {
+		return fc, fmt.Errorf("%s: %w", path, err)
+	}
+	return fc, nil
+}
+
+func (fc fileConfig) validate() error {
+	if err := fc.Budgets.validate(); err != nil {
+		return err
+	}
+	if err := fc.Build.validate(); err != nil {
+		return err
+	}
+	if err := fc.Checks.validate(); err != nil {
+		return err
+	}
+	if err := fc.Clone.validate(); err != nil {
+		return err
+	}
+	if err := fc.Data.validate(); err != nil {
+		return err
+	}
+	if err := fc.Go.validate(); err != nil {
+		return err
+	}
+	if err := fc.Hooks.validate(); err != nil {
+		return err
+	}
+	if err := fc.Licenses.validate(); err != nil {
+		return err
+	}
+	if err := fc.Notify.validate(); err != nil {
+		return err
+	}
+	if err := fc.Release.validate(); err != nil {
+		return err
+	}
+	if err := fc.Schedule.validate(); err != nil {
+		return err
+	}
+	if err := fc.Serve.validate(); err != nil {
+		return err
+	}
+	if err := fc.Style.validate(); err != nil {
+		return err
+	}
+	return fc.Suites.validate()
+}
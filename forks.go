@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fork-aware remotes
+//
+// When <REPO>_REPO points at a fork, the checkout's origin is the fork and
+// the canonical ajstarks repo is added as the upstream remote, so the fork
+// can be fast-forwarded from it (repos sync-fork) before building.
+
+// canonicalRepoURL reduces a git URL to host/owner/repo for comparison.
+func canonicalRepoURL(url string) string {
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if rest, ok := strings.CutPrefix(url, "git@"); ok {
+		url = strings.Replace(rest, ":", "/", 1)
+	}
+	for _, prefix := range []string{"https://", "http://", "ssh://git@", "ssh://", "git://"} {
+		url = strings.TrimPrefix(url, prefix)
+	}
+	return strings.ToLower(url)
+}
+
+func (repo *repoConfig) isFork() bool {
+	return repo.upstream != "" && canonicalRepoURL(repo.url) != canonicalRepoURL(repo.upstream)
+}
+
+// ensureUpstreamRemote points the upstream remote of a fork's checkout at
+// the canonical repo.
+func (cfg *config) ensureUpstreamRemote(ctx context.Context, repo *repoConfig) error {
+	if !repo.isFork() {
+		return nil
+	}
+	current, err := cfg.gitOutput(ctx, "-C", repo.dir, "config", "--get", "remote.upstream.url")
+	switch {
+	case err != nil:
+		return cfg.runGit(ctx, "-C", repo.dir, "remote", "add", "upstream", repo.upstream)
+	case current != repo.upstream:
+		return cfg.runGit(ctx, "-C", repo.dir, "remote", "set-url", "upstream", repo.upstream)
+	}
+	return nil
+}
+
+// syncFork fast-forwards the fork's branch from upstream and pushes it to
+// the fork. A fork that has diverged is left alone.
+func (cfg *config) syncFork(ctx context.Context, repo *repoConfig) error {
+	if !repo.isFork() {
+		fmt.Printf("⊘ %s: not a fork (%s)\n", repo.name, repo.url)
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+		if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
+			return err
+		}
+	}
+	if err := cfg.ensureUpstreamRemote(ctx, repo); err != nil {
+		return err
+	}
+	// Fast-forward checks need history, which shallow clones lack
+	if shallow, _ := cfg.gitOutput(ctx, "-C", repo.dir, "rev-parse", "--is-shallow-repository"); shallow == "true" {
+		if err := cfg.runGit(ctx, "-C", repo.dir, "fetch", "--quiet", "--unshallow", "origin", repo.branch); err != nil {
+			return err
+		}
+	} else if err := cfg.runGit(ctx, "-C", repo.dir, "fetch", "--quiet", "origin", repo.branch); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "fetch", "--quiet", "upstream", repo.branch); err != nil {
+		return err
+	}
+
+	upstreamRef := "upstream/" + repo.branch
+	behind, err := cfg.gitOutput(ctx, "-C", repo.dir, "rev-list", "--count", "origin/"+repo.branch+".."+upstreamRef)
+	if err != nil {
+		return err
+	}
+	if behind == "0" {
+		fmt.Printf("✓ %s: fork is up to date with upstream\n", repo.name)
+		return nil
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "merge-base", "--is-ancestor", "origin/"+repo.branch, upstreamRef); err != nil {
+		return fmt.Errorf("%s: fork branch %s has diverged from upstream; merge or rebase it by hand", repo.name, repo.branch)
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "checkout", "--quiet", repo.branch); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "merge", "--quiet", "--ff-only", upstreamRef); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "push", "--quiet", "origin", repo.branch); err != nil {
+		return fmt.Errorf("%s: push to fork: %w", repo.name, err)
+	}
+	fmt.Printf("✓ %s: fast-forwarded %s by %s commit(s) from upstream and pushed to the fork\n", repo.name, repo.branch, behind)
+	return nil
+}
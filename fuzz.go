@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// Fuzzing: mutate corpus decks to find inputs that crash or hang decksh or
+// pdfdeck, and keep a minimized reproducer of each distinct failure
+// (mutations in fuzzmutate.go, crashers in fuzzcrash.go, --go in fuzzgo.go)
+
+// fuzzSeed is a deck to mutate, run from the directory its data lives in.
+type fuzzSeed struct {
+	name string
+	dir  string
+	data []byte
+}
+
+// fuzzFailure is a crash or hang of one tool on one input.
+type fuzzFailure struct {
+	tool      string
+	kind      string // "crash" or "hang"
+	signature string // identifies the failure across inputs
+	output    string
+}
+
+type fuzzStats struct {
+	execs, failures, saved int
+}
+
+// fuzzSeeds returns the corpus and feature decks.
+func (cfg *config) fuzzSeeds() ([]fuzzSeed, error) {
+	var seeds []fuzzSeed
+	examples, err := cfg.listExamples()
+	if err != nil {
+		return nil, err
+	}
+	for _, example := range examples {
+		source, name := cfg.parseExample(example)
+		dir, err := cfg.getExampleDir(source, name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(cfg.getExampleDshPath(dir, name))
+		if err != nil {
+			continue // directory without a deck
+		}
+		seeds = append(seeds, fuzzSeed{name: example, dir: dir, data: data})
+	}
+	if err := cfg.writeFeatureDecks(); err != nil {
+		return nil, err
+	}
+	for _, name := range listFeatureDecks() {
+		data, err := featureDecks.ReadFile("features/" + name + ".dsh")
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, fuzzSeed{name: "features/" + name, dir: cfg.getFeatureDir(), data: data})
+	}
+	return seeds, nil
+}
+
+// fuzzDecks mutates seeds for duration, saving a minimized reproducer for
+// each distinct crash or hang.
+func (cfg *config) fuzzDecks(ctx context.Context, seeds []fuzzSeed, duration, timeout time.Duration, seed uint64) (fuzzStats, error) {
+	var stats fuzzStats
+	if len(seeds) == 0 {
+		return stats, errors.New("no seed decks found (run ensure first)")
+	}
+	runner := &fuzzRunner{cfg: cfg, timeout: timeout}
+	var err error
+	if runner.decksh, err = cfg.resolveBinary("decksh"); err != nil {
+		return stats, err
+	}
+	if runner.pdfdeck, err = cfg.resolveBinary("pdfdeck"); err != nil {
+		return stats, err
+	}
+	if runner.workDir, err = os.MkdirTemp("", "decktool-fuzz-"); err != nil {
+		return stats, err
+	}
+	defer os.RemoveAll(runner.workDir)
+
+	fmt.Printf("Fuzzing decksh and pdfdeck for %s with %d seed decks (seed %d)\n", duration, len(seeds), seed)
+	rng := rand.New(rand.NewPCG(seed, seed))
+	seen := make(map[string]bool)
+	deadline := time.Now().Add(duration)
+	lastReport := time.Now()
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		s := seeds[rng.IntN(len(seeds))]
+		input := mutateDeck(rng, s.data)
+		stats.execs++
+		if time.Since(lastReport) > 10*time.Second {
+			fmt.Printf("  %d inputs, %d failure(s), %s left\n", stats.execs, stats.failures, time.Until(deadline).Round(time.Second))
+			lastReport = time.Now()
+		}
+		failure := runner.run(ctx, s.dir, input)
+		if failure == nil {
+			continue
+		}
+		stats.failures++
+		if seen[failure.signature] {
+			continue
+		}
+		seen[failure.signature] = true
+		minimized := runner.minimizeInput(ctx, s.dir, input, failure, time.Minute)
+		path, saved, err := cfg.saveCrasher(s, minimized, failure)
+		if err != nil {
+			return stats, err
+		}
+		if saved {
+			stats.saved++
+			fmt.Printf("✗ %s %s from %s -> %s\n", failure.tool, failure.kind, s.name, relToCwd(path))
+		} else {
+			fmt.Printf("⊘ %s (already in %s)\n", failure.signature, relToCwd(path))
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Running fuzz inputs, and minimizing and saving the crashers they find
+
+// fuzzRunner runs inputs through decksh and pdfdeck with a per-tool timeout.
+type fuzzRunner struct {
+	cfg     *config
+	decksh  string
+	pdfdeck string
+	workDir string
+	timeout time.Duration
+}
+
+// run reports the first crash or hang of input; ordinary errors are expected
+// for malformed decks and are not failures.
+func (r *fuzzRunner) run(ctx context.Context, dir string, input []byte) *fuzzFailure {
+	script := filepath.Join(r.workDir, "input.dsh")
+	xmlPath := filepath.Join(r.workDir, "input.xml")
+	if err := os.WriteFile(script, input, 0o644); err != nil {
+		return nil
+	}
+	out, err := os.Create(xmlPath)
+	if err != nil {
+		return nil
+	}
+	var stderr bytes.Buffer
+	failure, ok := r.exec(ctx, dir, out, &stderr, "decksh", r.decksh, script)
+	out.Close()
+	if failure != nil || !ok {
+		return failure
+	}
+	stderr.Reset()
+	failure, _ = r.exec(ctx, dir, &stderr, &stderr, "pdfdeck", r.pdfdeck, "-outdir", r.workDir, xmlPath)
+	return failure
+}
+
+// exec runs one tool and classifies its exit; ok is false when it failed
+// without crashing.
+func (r *fuzzRunner) exec(ctx context.Context, dir string, stdout io.Writer, stderr *bytes.Buffer, tool, path string, args ...string) (*fuzzFailure, bool) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = dir
+	r.cfg.useEnv(cmd, envTools)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	err := cmd.Run()
+	if err == nil {
+		return nil, true
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &fuzzFailure{tool: tool, kind: "hang", signature: tool + ": no exit within " + r.timeout.String()}, false
+	}
+	if sig := panicSignature(stderr.String()); sig != "" {
+		return &fuzzFailure{tool: tool, kind: "crash", signature: tool + ": " + sig, output: stderr.String()}, false
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == -1 {
+		return &fuzzFailure{tool: tool, kind: "crash", signature: tool + ": " + exitErr.String(), output: stderr.String()}, false
+	}
+	return nil, false
+}
+
+var addressPattern = regexp.MustCompile(`0x[0-9a-f]+`)
+
+// panicSignature returns the panic or fatal error line of Go program output,
+// with addresses blanked so the same bug dedupes across inputs.
+func panicSignature(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line) // go test indents the fuzz target's output
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			return addressPattern.ReplaceAllString(line, "0x?")
+		}
+	}
+	return ""
+}
+
+// minimizeInput removes chunks of lines (halving the chunk size down to
+// single lines) while the input still fails with the same signature.
+func (r *fuzzRunner) minimizeInput(ctx context.Context, dir string, input []byte, failure *fuzzFailure, budget time.Duration) []byte {
+	deadline := time.Now().Add(budget)
+	lines := strings.Split(string(input), "\n")
+	for chunk := len(lines) / 2; chunk >= 1; chunk /= 2 {
+		for start := 0; start < len(lines) && time.Now().Before(deadline); {
+			end := min(start+chunk, len(lines))
+			candidate := append(append([]string{}, lines[:start]...), lines[end:]...)
+			if f := r.run(ctx, dir, []byte(strings.Join(candidate, "\n"))); f != nil && f.signature == failure.signature {
+				lines = candidate
+				continue
+			}
+			start = end
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// saveCrasher writes a reproducer and its report into the crashers
+// directory, returning false when the failure was already recorded.
+func (cfg *config) saveCrasher(seed fuzzSeed, input []byte, failure *fuzzFailure) (string, bool, error) {
+	sum := sha256.Sum256([]byte(failure.signature))
+	base := filepath.Join(cfg.getCrashersDir(), fmt.Sprintf("%s-%s-%s", failure.tool, failure.kind, hex.EncodeToString(sum[:4])))
+	if _, err := os.Stat(base + ".dsh"); err == nil {
+		return base + ".dsh", false, nil
+	}
+	if err := os.MkdirAll(cfg.getCrashersDir(), 0o755); err != nil {
+		return "", false, err
+	}
+	if err := os.WriteFile(base+".dsh", input, 0o644); err != nil {
+		return "", false, err
+	}
+	var report strings.Builder
+	fmt.Fprintf(&report, "%s\n\nseed:      %s\ndirectory: %s\ntoolchain: %s\nfound:     %s\n",
+		failure.signature, seed.name, relToCwd(seed.dir), cfg.toolchainID(), time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&report, "\nreproduce:\n  cd %s && decksh %s.dsh", seed.dir, base)
+	if failure.tool == "pdfdeck" {
+		report.WriteString(" > /tmp/input.xml && pdfdeck /tmp/input.xml")
+	}
+	if failure.output != "" {
+		fmt.Fprintf(&report, "\n\noutput:\n%s", failure.output)
+	}
+	return base + ".dsh", true, os.WriteFile(base+".txt", []byte(report.String()+"\n"), 0o644)
+}
+
+// listCrashers returns the saved reproducers.
+func (cfg *config) listCrashers() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(cfg.getCrashersDir(), "*.dsh"))
+	sort.Strings(matches)
+	return matches, err
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dev fuzz --go: Go's native fuzzer over decksh.Process, in the decksh checkout
+
+// goFuzzTarget is the fuzz test written into the decksh checkout for the
+// duration of a --go run.
+const goFuzzTarget = `package %s
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// Generated by decktool dev fuzz --go; removed when the run ends.
+func FuzzDecktool(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Process(io.Discard, bytes.NewReader(data)%s)
+	})
+}
+`
+
+// deckshProcess finds decksh's Process(w, r[, filename]) entry point and
+// returns the package name and the extra arguments a call needs.
+func deckshProcess(dir string) (pkg, extra string, err error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", "", err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", "", err
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name.Name != "Process" {
+				continue
+			}
+			switch fn.Type.Params.NumFields() {
+			case 2:
+				return f.Name.Name, "", nil
+			case 3:
+				return f.Name.Name, `, "fuzz.dsh"`, nil
+			}
+			return "", "", fmt.Errorf("unsupported decksh.Process signature in %s", relToCwd(file))
+		}
+	}
+	return "", "", fmt.Errorf("no Process function in %s; cannot fuzz decksh in-process", relToCwd(dir))
+}
+
+// goFuzzDecksh runs Go's native fuzzer over decksh.Process in the decksh
+// checkout, seeded with the corpus, and saves failing inputs as crashers.
+func (cfg *config) goFuzzDecksh(ctx context.Context, seeds []fuzzSeed, duration time.Duration) (fuzzStats, error) {
+	var stats fuzzStats
+	decksh := cfg.repos["decksh"]
+	if _, err := os.Stat(filepath.Join(decksh.dir, ".git")); err != nil {
+		if err := cfg.gitCloneOrUpdate(ctx, decksh); err != nil {
+			return stats, fmt.Errorf("check out decksh source: %w", err)
+		}
+	}
+	pkg, extra, err := deckshProcess(decksh.dir)
+	if err != nil {
+		return stats, err
+	}
+
+	// The harness and its seed corpus live in the checkout only while fuzzing
+	testFile := filepath.Join(decksh.dir, "zz_decktool_fuzz_test.go")
+	corpusDir := filepath.Join(decksh.dir, "testdata", "fuzz", "FuzzDecktool")
+	if err := os.WriteFile(testFile, []byte(fmt.Sprintf(goFuzzTarget, pkg, extra)), 0o644); err != nil {
+		return stats, err
+	}
+	defer os.Remove(testFile)
+	if err := os.MkdirAll(corpusDir, 0o755); err != nil {
+		return stats, err
+	}
+	defer removeEmptyParents(corpusDir, decksh.dir)
+	defer os.RemoveAll(corpusDir)
+	seeded := make(map[string]bool)
+	for _, s := range seeds {
+		sum := sha256.Sum256(s.data)
+		name := "seed-" + hex.EncodeToString(sum[:8])
+		seeded[name] = true
+		entry := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", s.data)
+		if err := os.WriteFile(filepath.Join(corpusDir, name), []byte(entry), 0o644); err != nil {
+			return stats, err
+		}
+	}
+
+	fmt.Printf("Fuzzing decksh.Process in-process for %s with %d seed decks\n", duration, len(seeds))
+	cmd := exec.CommandContext(ctx, cfg.goCmd, "test", "-run", "^$", "-fuzz", "^FuzzDecktool$", "-fuzztime", duration.String(), ".")
+	cmd.Dir = decksh.dir
+	cfg.useEnv(cmd, envGo)
+	var output bytes.Buffer
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = cmd.Stdout
+	runErr := cmd.Run()
+
+	// The fuzzer writes each failing input next to the seeds
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		return stats, err
+	}
+	for _, entry := range entries {
+		if seeded[entry.Name()] {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(corpusDir, entry.Name()))
+		if err != nil {
+			return stats, err
+		}
+		input, err := parseGoFuzzEntry(data)
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		stats.failures++
+		failure := &fuzzFailure{tool: "decksh", kind: "crash", output: output.String()}
+		if failure.signature = panicSignature(output.String()); failure.signature == "" {
+			failure.signature = "decksh.Process: " + entry.Name()
+		} else {
+			failure.signature = "decksh.Process: " + failure.signature
+		}
+		path, saved, err := cfg.saveCrasher(fuzzSeed{name: "go fuzz", dir: decksh.dir}, input, failure)
+		if err != nil {
+			return stats, err
+		}
+		if saved {
+			stats.saved++
+			fmt.Printf("✗ decksh.Process crash -> %s\n", relToCwd(path))
+		}
+	}
+	if runErr != nil && stats.failures == 0 {
+		return stats, fmt.Errorf("go test -fuzz: %w", runErr)
+	}
+	return stats, nil
+}
+
+// parseGoFuzzEntry decodes a single-[]byte entry of Go's fuzz corpus format.
+func parseGoFuzzEntry(data []byte) ([]byte, error) {
+	_, body, ok := strings.Cut(string(data), "\n")
+	body = strings.TrimSpace(body)
+	literal, found := strings.CutPrefix(body, "[]byte(")
+	if !ok || !found || !strings.HasSuffix(literal, ")") {
+		return nil, errors.New("not a go fuzz corpus entry")
+	}
+	value, err := strconv.Unquote(strings.TrimSuffix(literal, ")"))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+// removeEmptyParents removes dir's ancestors up to (not including) root
+// while they are empty.
+func removeEmptyParents(dir, root string) {
+	for parent := filepath.Dir(dir); parent != root && strings.HasPrefix(parent, root); parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			return
+		}
+	}
+}
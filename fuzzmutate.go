@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Mutation engine for dev fuzz: line and token edits of seed decks
+
+// fuzzKeywords are spliced into inputs so mutations reach more commands.
+var fuzzKeywords = []string{
+	"deck", "edeck", "slide", "eslide", "for", "efor", "if", "else", "eif", "def", "edef", "func",
+	"text", "ctext", "etext", "textblock", "list", "blist", "nlist", "elist", "li",
+	"rect", "circle", "ellipse", "polygon", "line", "arc", "curve", "arrow", "image", "dchart", "grid",
+}
+
+// fuzzValues replace numeric arguments with boundary cases.
+var fuzzValues = []string{"0", "-1", "-0", "1e308", "-1e308", "NaN", "Inf", "99999999999", "0.0000001", `""`, "[]", "x"}
+
+var numberPattern = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+// mutateDeck applies one to three random line- or token-level mutations.
+func mutateDeck(rng *rand.Rand, data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for n := 1 + rng.IntN(3); n > 0; n-- {
+		i := rng.IntN(len(lines))
+		switch rng.IntN(8) {
+		case 0: // delete a line
+			if len(lines) > 1 {
+				lines = slices.Delete(lines, i, i+1)
+			}
+		case 1: // duplicate a line
+			lines = slices.Insert(lines, i, lines[i])
+		case 2: // swap two lines
+			j := rng.IntN(len(lines))
+			lines[i], lines[j] = lines[j], lines[i]
+		case 3: // replace a number with a boundary value
+			matches := numberPattern.FindAllStringIndex(lines[i], -1)
+			if len(matches) > 0 {
+				m := matches[rng.IntN(len(matches))]
+				lines[i] = lines[i][:m[0]] + fuzzValues[rng.IntN(len(fuzzValues))] + lines[i][m[1]:]
+			}
+		case 4: // insert a keyword with arguments taken from another line
+			args := strings.Fields(lines[rng.IntN(len(lines))])
+			if len(args) > 0 {
+				args = args[1:]
+			}
+			kw := fuzzKeywords[rng.IntN(len(fuzzKeywords))]
+			lines = slices.Insert(lines, i, strings.Join(append([]string{kw}, args...), " "))
+		case 5: // drop or add a token
+			fields := strings.Fields(lines[i])
+			if len(fields) > 1 && rng.IntN(2) == 0 {
+				j := rng.IntN(len(fields))
+				fields = slices.Delete(fields, j, j+1)
+			} else {
+				fields = append(fields, fuzzValues[rng.IntN(len(fuzzValues))])
+			}
+			lines[i] = strings.Join(fields, " ")
+		case 6: // unbalance quotes or brackets
+			for _, c := range []string{`"`, "[", "]", "(", ")"} {
+				if k := strings.Index(lines[i], c); k >= 0 {
+					lines[i] = lines[i][:k] + lines[i][k+1:]
+					break
+				}
+			}
+		default: // truncate the input
+			lines = lines[:i+1]
+			if line := lines[i]; line != "" {
+				lines[i] = line[:rng.IntN(len(line))]
+			}
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Static example gallery, rebuilt incrementally
+//
+// Thumbnails are re-rendered only when an example's render cache key (its
+// deck directory plus the rendering binaries) changes, and HTML pages are
+// rewritten only when their content changes, so iterating on templates
+// never re-renders the corpus. Templates are in gallerytemplates.go,
+// thumbnails in gallerythumbs.go and cache keys in renderkey.go.
+
+const galleryStateFile = ".state.json"
+
+// galleryState remembers the render cache key each thumbnail was made from.
+type galleryState struct {
+	Thumbnails map[string]string `json:"thumbnails"` // example id -> render cache key
+}
+
+type galleryStats struct {
+	rendered, reused, failed, pagesWritten int
+	a11y                                   *a11yReport
+}
+
+func (cfg *config) loadGalleryState(dir string) galleryState {
+	state := galleryState{Thumbnails: make(map[string]string)}
+	data, err := os.ReadFile(filepath.Join(dir, galleryStateFile))
+	if err == nil {
+		json.Unmarshal(data, &state)
+	}
+	if state.Thumbnails == nil {
+		state.Thumbnails = make(map[string]string)
+	}
+	return state
+}
+
+func saveGalleryState(dir string, state galleryState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, galleryStateFile), append(data, '\n'), 0o644)
+}
+
+// buildGallery renders changed thumbnails and writes changed pages into dir.
+func (cfg *config) buildGallery(ctx context.Context, dir, templateDir string) (galleryStats, error) {
+	var stats galleryStats
+	tmpl, err := loadGalleryTemplates(templateDir)
+	if err != nil {
+		return stats, fmt.Errorf("gallery templates: %w", err)
+	}
+	entries, err := cfg.exampleCatalog()
+	if err != nil {
+		return stats, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "thumbs"), 0o755); err != nil {
+		return stats, err
+	}
+	state := cfg.loadGalleryState(dir)
+
+	for i := range entries {
+		e := &entries[i]
+		thumb := filepath.Join(dir, "thumbs", e.ID+".png")
+		exampleDir, err := cfg.getExampleDir(cfg.parseExample(e.Name))
+		if err != nil {
+			return stats, err
+		}
+		key, err := cfg.renderCacheKey(exampleDir, "decksh", "pngdeck")
+		if err != nil {
+			return stats, err
+		}
+		if _, statErr := os.Stat(thumb); statErr == nil && state.Thumbnails[e.ID] == key {
+			stats.reused++
+			e.Thumbnail = "thumbs/" + e.ID + ".png"
+			continue
+		}
+		if err := cfg.renderThumbnail(ctx, e.Name, thumb); err != nil {
+			fmt.Printf("✗ %s: %v\n", e.Name, err)
+			delete(state.Thumbnails, e.ID)
+			os.Remove(thumb)
+			e.Thumbnail = ""
+			stats.failed++
+			continue
+		}
+		state.Thumbnails[e.ID] = key
+		e.Thumbnail = "thumbs/" + e.ID + ".png"
+		stats.rendered++
+	}
+
+	for _, e := range entries {
+		written, err := writePageIfChanged(filepath.Join(dir, e.ID+".html"), tmpl, "example.html.tmpl", e)
+		if err != nil {
+			return stats, err
+		}
+		if written {
+			stats.pagesWritten++
+		}
+	}
+	written, err := writePageIfChanged(filepath.Join(dir, "index.html"), tmpl, "index.html.tmpl", map[string]any{"Examples": entries})
+	if err != nil {
+		return stats, err
+	}
+	if written {
+		stats.pagesWritten++
+	}
+	pruneGallery(dir, entries, state)
+	if stats.a11y, err = cfg.writeAccessibilityReport(dir, entries); err != nil {
+		return stats, fmt.Errorf("accessibility report: %w", err)
+	}
+	recordArtifact(dir)
+	return stats, saveGalleryState(dir, state)
+}
+
+// pruneGallery removes pages and thumbnails of examples that no longer exist.
+func pruneGallery(dir string, entries []catalogEntry, state galleryState) {
+	keep := make(map[string]bool)
+	for _, e := range entries {
+		keep[e.ID] = true
+	}
+	for id := range state.Thumbnails {
+		if !keep[id] {
+			delete(state.Thumbnails, id)
+		}
+	}
+	pages, _ := filepath.Glob(filepath.Join(dir, "*.html"))
+	thumbs, _ := filepath.Glob(filepath.Join(dir, "thumbs", "*.png"))
+	for _, path := range append(pages, thumbs...) {
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if id != "index" && id != accessibilityID && !keep[id] {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"os"
+)
+
+// Gallery page templates: the built-in ones or a directory of *.tmpl, and
+// pages written only when their content changes
+
+//go:embed gallery/*.tmpl
+var galleryTemplates embed.FS
+
+// loadGalleryTemplates parses the page templates from templateDir, or the
+// built-in ones when it is empty.
+func loadGalleryTemplates(templateDir string) (*template.Template, error) {
+	var fsys fs.FS = galleryTemplates
+	pattern := "gallery/*.tmpl"
+	if templateDir != "" {
+		fsys, pattern = os.DirFS(templateDir), "*.tmpl"
+	}
+	return template.ParseFS(fsys, pattern)
+}
+
+// writePageIfChanged executes the named template and writes path only if
+// the output differs from what is already there.
+func writePageIfChanged(path string, tmpl *template.Template, name string, data any) (bool, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return false, err
+	}
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, buf.Bytes()) {
+		return false, nil
+	}
+	return true, os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Gallery thumbnails and slide images, rendered with pngdeck
+
+// renderThumbnail renders an example to PNG and copies its first slide to thumb.
+func (cfg *config) renderThumbnail(ctx context.Context, name, thumb string) error {
+	pages, err := cfg.renderPages(ctx, name)
+	if err != nil {
+		return err
+	}
+	return copyFile(pages[0], thumb)
+}
+
+// renderPages renders an example to one PNG per slide, in slide order.
+func (cfg *config) renderPages(ctx context.Context, name string) ([]string, error) {
+	xmlPath, err := cfg.renderExample(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.convertDeck(ctx, filepath.Dir(xmlPath), xmlPath, "png"); err != nil {
+		return nil, err
+	}
+	pages, _ := filepath.Glob(strings.TrimSuffix(xmlPath, ".xml") + "-*.png")
+	if len(pages) == 0 {
+		return nil, errors.New("no slides rendered")
+	}
+	sort.Strings(pages)
+	return pages, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Go module environment for corporate proxies and private modules
+//
+// Settings from the "go" section of the config file are added to the
+// environment of every go invocation (builds, installs, go list), taking
+// precedence over inherited variables, so an internal proxy works without
+// exporting anything by hand.
+
+// goConfig maps to the Go module environment variables, e.g.
+//
+//	"go": {"proxy": "https://goproxy.corp.example,direct", "private": "git.corp.example/*"}
+type goConfig struct {
+	Proxy     string `json:"proxy"`     // GOPROXY
+	SumDB     string `json:"sumdb"`     // GOSUMDB ("off" disables checksum verification)
+	Private   string `json:"private"`   // GOPRIVATE: no proxy, no sumdb
+	NoProxy   string `json:"noproxy"`   // GONOPROXY
+	NoSumDB   string `json:"nosumdb"`   // GONOSUMDB
+	Insecure  string `json:"insecure"`  // GOINSECURE: allow plain HTTP fetches
+	Toolchain string `json:"toolchain"` // GOTOOLCHAIN, e.g. go1.25.1 or local
+}
+
+var goToolchainRE = regexp.MustCompile(`^(auto|local|path|go\d+\.\d+(\.\d+|rc\d+)?(\+auto|\+path)?)$`)
+
+func (g goConfig) validate() error {
+	if g.Toolchain != "" && !goToolchainRE.MatchString(g.Toolchain) {
+		return fmt.Errorf("go.toolchain: %q is not a GOTOOLCHAIN value (auto, local, path, go1.N.M[+auto|+path])", g.Toolchain)
+	}
+	if g.Proxy != "" {
+		for _, entry := range strings.FieldsFunc(g.Proxy, func(r rune) bool { return r == ',' || r == '|' }) {
+			if entry == "direct" || entry == "off" {
+				continue
+			}
+			u, err := url.Parse(entry)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "file") {
+				return fmt.Errorf("go.proxy: %q is not a proxy URL, direct or off", entry)
+			}
+		}
+	}
+	for key, patterns := range map[string]string{"private": g.Private, "noproxy": g.NoProxy, "nosumdb": g.NoSumDB, "insecure": g.Insecure} {
+		for _, p := range strings.Split(patterns, ",") {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("go.%s: bad pattern %q: %w", key, p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// env returns the configured variables as KEY=value pairs.
+func (g goConfig) env() []string {
+	var env []string
+	for _, kv := range []struct{ key, value string }{
+		{"GOPROXY", g.Proxy}, {"GOSUMDB", g.SumDB}, {"GOPRIVATE", g.Private},
+		{"GONOPROXY", g.NoProxy}, {"GONOSUMDB", g.NoSumDB}, {"GOINSECURE", g.Insecure},
+		{"GOTOOLCHAIN", g.Toolchain},
+	} {
+		if kv.value != "" {
+			env = append(env, kv.key+"="+kv.value)
+		}
+	}
+	return env
+}
+
+// goEnvValues runs go env for keys in the environment builds get, so the
+// "go" section (a pinned toolchain in particular) applies as it does there.
+func (cfg *config) goEnvValues(ctx context.Context, keys ...string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, cfg.goCmd, append([]string{"env"}, keys...)...)
+	cfg.useEnv(cmd, envGoWork)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go env: %w", err)
+	}
+	values := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(values) != len(keys) {
+		return nil, fmt.Errorf("go env: got %d values for %d keys", len(values), len(keys))
+	}
+	return values, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Golden image comparison of rendered slides
+
+const pixelTolerance = 2 * 257 // per-channel difference ignored (16-bit scale)
+
+// visualDiff is a slide whose rendering no longer matches its golden image.
+type visualDiff struct {
+	Deck   string `json:"deck"`
+	Slide  string `json:"slide"`
+	Golden string `json:"golden"`
+	Actual string `json:"actual"`
+	Diff   string `json:"diff"`
+	Pixels int    `json:"pixels"` // differing pixel count, -1 when sizes differ
+}
+
+// compareGolden renders the deck to PNG and compares each slide with its
+// golden image. Decks without goldens have their slides recorded as goldens.
+func (cfg *config) compareGolden(ctx context.Context, deck, xmlPath string) ([]visualDiff, error) {
+	if err := cfg.convertDeck(ctx, filepath.Dir(xmlPath), xmlPath, "png"); err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(xmlPath, filepath.Ext(xmlPath))
+	slides, err := filepath.Glob(base + "-*.png")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(slides)
+
+	goldenDir, diffDir := cfg.getGoldenDir(deck), cfg.getDiffDir(deck)
+	if err := os.RemoveAll(diffDir); err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(goldenDir)
+	bootstrap := errors.Is(statErr, os.ErrNotExist)
+	var diffs []visualDiff
+	for _, actual := range slides {
+		slide := strings.TrimPrefix(filepath.Base(actual), filepath.Base(base)+"-")
+		golden := filepath.Join(goldenDir, slide)
+		if bootstrap {
+			if err := copyFile(actual, golden); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Keep a copy of the new rendering so a later run cannot change what gets approved
+		d := visualDiff{Deck: deck, Slide: slide, Golden: golden,
+			Actual: filepath.Join(diffDir, slide),
+			Diff:   filepath.Join(diffDir, strings.TrimSuffix(slide, ".png")+"-diff.png")}
+		if d.Pixels, err = diffImages(golden, actual, d.Diff); err != nil {
+			return nil, err
+		}
+		if d.Pixels != 0 {
+			if err := copyFile(actual, d.Actual); err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, d)
+		}
+	}
+	if bootstrap && len(slides) > 0 {
+		fmt.Printf("Recorded %d golden image(s) for %s\n", len(slides), deck)
+	}
+	return diffs, nil
+}
+
+// diffImages counts differing pixels and writes a diff image highlighting
+// them in red. A missing golden or a size change counts as -1.
+func diffImages(goldenPath, actualPath, diffPath string) (int, error) {
+	actual, err := readPNG(actualPath)
+	if err != nil {
+		return 0, err
+	}
+	golden, err := readPNG(goldenPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, copyFile(actualPath, diffPath)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if golden.Bounds() != actual.Bounds() {
+		return -1, copyFile(actualPath, diffPath)
+	}
+
+	b := actual.Bounds()
+	out := image.NewRGBA(b)
+	count := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r1, g1, b1, a1 := golden.At(x, y).RGBA()
+			r2, g2, b2, a2 := actual.At(x, y).RGBA()
+			if absDiff(r1, r2) > pixelTolerance || absDiff(g1, g2) > pixelTolerance ||
+				absDiff(b1, b2) > pixelTolerance || absDiff(a1, a2) > pixelTolerance {
+				out.Set(x, y, color.RGBA{R: 255, A: 255})
+				count++
+				continue
+			}
+			gray := uint8(((r2+g2+b2)/3)>>8)/4 + 191 // faded context
+			out.Set(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return count, writePNG(diffPath, out)
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
+
+func (cfg *config) loadPendingDiffs() ([]visualDiff, error) {
+	data, err := os.ReadFile(cfg.getPendingDiffsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var diffs []visualDiff
+	return diffs, json.Unmarshal(data, &diffs)
+}
+
+func (cfg *config) savePendingDiffs(diffs []visualDiff) error {
+	data, err := json.MarshalIndent(diffs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.getPendingDiffsPath()), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.getPendingDiffsPath(), append(data, '\n'), 0o644)
+}
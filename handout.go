@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// Printable handouts: slide thumbnails in a grid on portrait letter pages,
+// each page footed with a QR code linking to the hosted deck. Like og-image
+// the layout is a generated deck, rendered with decksh and pdfdeck.
+
+const (
+	handoutWidth  = 612
+	handoutHeight = 792
+)
+
+// renderHandout writes the handout PDF for an example to out.
+func (cfg *config) renderHandout(ctx context.Context, name, title, url string, columns int, out string) error {
+	if columns < 1 {
+		return errors.New("--columns must be at least 1")
+	}
+	qr, err := encodeQR([]byte(url))
+	if err != nil {
+		return fmt.Errorf("QR code for %s: %w", url, err)
+	}
+	pages, err := cfg.renderPages(ctx, name)
+	if err != nil {
+		return err
+	}
+	width, height, err := pngSize(pages[0])
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp("", "decktool-handout-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	qrPath := filepath.Join(tmp, "qr.png")
+	f, err := os.Create(qrPath)
+	if err != nil {
+		return err
+	}
+	err = qr.writePNG(f, 4)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	deck := handoutDeck(pages, width, height, columns, title, url, qrPath)
+	if err := os.WriteFile(filepath.Join(tmp, "handout.dsh"), []byte(deck), 0o644); err != nil {
+		return err
+	}
+	xmlPath := filepath.Join(tmp, "handout.xml")
+	if err := cfg.renderDeck(ctx, tmp, "handout.dsh", xmlPath); err != nil {
+		return err
+	}
+	if err := cfg.convertDeck(ctx, tmp, xmlPath, "pdf"); err != nil {
+		return err
+	}
+	if err := copyFile(render.ConvertedPath(xmlPath, "pdf"), out); err != nil {
+		return err
+	}
+	recordArtifact(out)
+	return nil
+}
+
+func pngSize(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	img, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", path, err)
+	}
+	if img.Width == 0 || img.Height == 0 {
+		return 0, 0, fmt.Errorf("%s: empty image", path)
+	}
+	return img.Width, img.Height, nil
+}
+
+// handoutDeck lays the slides out columns wide and columns+1 rows deep per
+// page, between a title header and the QR footer.
+func handoutDeck(slides []string, width, height, columns int, title, url, qrPath string) string {
+	rows := columns + 1
+	cellW, cellH := 90/float64(columns), 70/float64(rows) // percent of the page
+	scale := min(cellW/100*handoutWidth*0.92/float64(width), cellH/100*handoutHeight*0.92/float64(height))
+	imgW, imgH := int(float64(width)*scale), int(float64(height)*scale)
+	quote := func(s string) string { return fmt.Sprintf("%q", strings.ReplaceAll(s, `"`, "'")) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "deck\ncanvas %d %d\n", handoutWidth, handoutHeight)
+	perPage := columns * rows
+	for start := 0; start < len(slides); start += perPage {
+		b.WriteString("slide \"white\"\n")
+		fmt.Fprintf(&b, "ctext %s 50 94 2.5\n", quote(title))
+		for i, slide := range slides[start:min(start+perPage, len(slides))] {
+			x := 5 + cellW*(float64(i%columns)+0.5)
+			y := 88 - cellH*(float64(i/columns)+0.5)
+			fmt.Fprintf(&b, "image %s %.2f %.2f %d %d\n", quote(slide), x, y, imgW, imgH)
+			fmt.Fprintf(&b, "ctext \"%d\" %.2f %.2f 1\n", start+i+1, x, y-cellH/2+0.5)
+		}
+		fmt.Fprintf(&b, "image %s 88 9 100 100\n", quote(qrPath))
+		fmt.Fprintf(&b, "text %s 5 8 1.2\n", quote(url))
+		fmt.Fprintf(&b, "text \"page %d of %d\" 5 5 1\n", start/perPage+1, (len(slides)+perPage-1)/perPage)
+		b.WriteString("eslide\n")
+	}
+	b.WriteString("edeck\n")
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command history, appended to ~/.decktool/history.jsonl after every run
+
+// maxHistoryArtifacts caps the artifacts listed per entry; the rest are
+// counted.
+const maxHistoryArtifacts = 50
+
+type historyEntry struct {
+	Time          time.Time `json:"time"`
+	Dir           string    `json:"dir"`
+	Args          []string  `json:"args"`
+	DurationMS    int64     `json:"duration_ms"`
+	Error         string    `json:"error,omitempty"`
+	Toolchain     string    `json:"toolchain,omitempty"` // <release tag>@<binary fingerprint>, when tools ran
+	Artifacts     []string  `json:"artifacts,omitempty"` // files and directories written
+	MoreArtifacts int       `json:"more_artifacts,omitempty"`
+}
+
+// runRecord collects what the running command produced for its entry.
+var runRecord struct {
+	sync.Mutex
+	artifacts []string
+	more      int
+	usedTools bool
+}
+
+// recordArtifact notes an output of the running command; intermediates in
+// decktool's own temp directories are left out.
+func recordArtifact(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if rel, err := filepath.Rel(os.TempDir(), path); err == nil && strings.HasPrefix(rel, "decktool-") {
+		return
+	}
+	runRecord.Lock()
+	defer runRecord.Unlock()
+	switch {
+	case slices.Contains(runRecord.artifacts, path):
+	case len(runRecord.artifacts) >= maxHistoryArtifacts:
+		runRecord.more++
+	default:
+		runRecord.artifacts = append(runRecord.artifacts, path)
+	}
+}
+
+func recordToolUse() {
+	runRecord.Lock()
+	runRecord.usedTools = true
+	runRecord.Unlock()
+}
+
+// historyToolchain identifies the toolchain the command ran with: the
+// installed release tag plus a fingerprint of the render binaries (size and
+// modification time), which also tells local dev builds apart. It is empty
+// for commands that ran no tools.
+func (cfg *config) historyToolchain() string {
+	runRecord.Lock()
+	used := runRecord.usedTools
+	runRecord.Unlock()
+	if !used {
+		return ""
+	}
+	tag := cfg.loadToolchainState().Current
+	if tag == "" {
+		tag = "unreleased"
+	}
+	h := sha256.New()
+	for _, tool := range []string{"decksh", "pdfdeck", "pngdeck"} {
+		path, err := cfg.resolveBinary(tool)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", tool, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return tag + "@" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// recordHistory appends an invocation; failures to record are ignored so
+// history never breaks a command. Shell completion requests are skipped.
+func recordHistory(args []string, started time.Time, runErr error, toolchain string) {
+	if len(args) > 0 && strings.HasPrefix(args[0], "__complete") {
+		return
+	}
+	path, err := decktoolHomePath("history.jsonl")
+	if err != nil {
+		return
+	}
+	entry := historyEntry{Time: started.UTC(), Args: args, DurationMS: time.Since(started).Milliseconds(), Toolchain: toolchain}
+	entry.Dir, _ = os.Getwd()
+	if runErr != nil {
+		entry.Error = runErr.Error()
+	}
+	runRecord.Lock()
+	entry.Artifacts, entry.MoreArtifacts = runRecord.artifacts, runRecord.more
+	runRecord.Unlock()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// recentHistory returns up to n of the latest entries run in dir ("" for
+// any directory), oldest first.
+func recentHistory(dir string, n int) ([]historyEntry, error) {
+	return readHistory(func(e historyEntry) bool { return dir == "" || e.Dir == dir }, n)
+}
+
+// readHistory returns up to n (all when n <= 0) of the latest entries keep
+// accepts, oldest first.
+func readHistory(keep func(historyEntry) bool, n int) ([]historyEntry, error) {
+	path, err := decktoolHomePath("history.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry historyEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || !keep(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		if n > 0 && len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	return entries, scanner.Err()
+}
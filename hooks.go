@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Repo hooks: commands run before and after a repo syncs, for repos that
+// need more than a checkout (an extra LFS fetch, a patch, code generation),
+// configured per repo in the config file:
+//
+//	"hooks": {"decksh": {"post_sync": [{"run": ["go", "generate", "./..."], "changed": true}]}}
+//
+// A hook is a git command (run like decktool's own git, so clone.bandwidth
+// and tracing apply) or a program, started in the checkout. Hooks see the
+// sync through DECKTOOL_* variables, can be limited to clones, updates or
+// pinned checkouts with "on", and post-sync hooks with "changed" only run
+// when the checkout moved. A failing hook stops the sync.
+
+type hooksConfig map[string]repoHooks
+
+type repoHooks struct {
+	PreSync  []repoHook `json:"pre_sync"`
+	PostSync []repoHook `json:"post_sync"`
+}
+
+type repoHook struct {
+	Git     []string `json:"git"`     // git arguments, run with -C <checkout>
+	Run     []string `json:"run"`     // program and arguments, run in the checkout
+	On      []string `json:"on"`      // sync kinds: clone, update, pin (default: all)
+	Changed bool     `json:"changed"` // post_sync only: skip when HEAD did not move
+}
+
+var syncKinds = []string{"clone", "update", "pin"}
+
+func (h hooksConfig) validate() error {
+	for repo, hooks := range h {
+		for _, stage := range []struct {
+			name  string
+			hooks []repoHook
+		}{{"pre_sync", hooks.PreSync}, {"post_sync", hooks.PostSync}} {
+			for i, hook := range stage.hooks {
+				where := fmt.Sprintf("hooks[%q].%s[%d]", repo, stage.name, i)
+				if (len(hook.Git) == 0) == (len(hook.Run) == 0) {
+					return fmt.Errorf("%s: set exactly one of git or run", where)
+				}
+				for _, kind := range hook.On {
+					if !slices.Contains(syncKinds, kind) {
+						return fmt.Errorf("%s: unknown sync kind %q in on (supported: %s)", where, kind, strings.Join(syncKinds, ", "))
+					}
+				}
+				if hook.Changed && stage.name == "pre_sync" {
+					return fmt.Errorf("%s: changed only applies to post_sync hooks", where)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkHookRepos rejects hooks for repos decktool does not manage.
+func (cfg *config) checkHookRepos() error {
+	for name := range cfg.file.Hooks {
+		if _, ok := cfg.repos[name]; !ok {
+			var names []string
+			for n := range cfg.repos {
+				names = append(names, n)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("%s: hooks: unknown repo %q (%s)", configFile, name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// withSyncHooks runs sync between repo's pre- and post-sync hooks.
+func (cfg *config) withSyncHooks(ctx context.Context, repo *repoConfig, kind string, sync func() error) error {
+	hooks, ok := cfg.file.Hooks[repo.name]
+	if !ok {
+		return sync()
+	}
+	before := ""
+	if repo.cloned() {
+		before, _ = cfg.gitHead(ctx, repo)
+	}
+	for _, hook := range hooks.PreSync {
+		if err := cfg.runHook(ctx, repo, "pre_sync", kind, before, hook); err != nil {
+			return err
+		}
+	}
+	if err := sync(); err != nil {
+		return err
+	}
+	after, _ := cfg.gitHead(ctx, repo)
+	for _, hook := range hooks.PostSync {
+		if hook.Changed && before != "" && before == after {
+			continue
+		}
+		if err := cfg.runHook(ctx, repo, "post_sync", kind, after, hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *config) runHook(ctx context.Context, repo *repoConfig, stage, kind, head string, hook repoHook) (err error) {
+	if len(hook.On) > 0 && !slices.Contains(hook.On, kind) {
+		return nil
+	}
+	args := hook.Run
+	if len(hook.Git) > 0 {
+		args = append([]string{"git"}, hook.Git...)
+	}
+	ctx, span := startSpan(ctx, "hook", "repo", repo.name, "hook.stage", stage, "hook.args", strings.Join(args, " "))
+	defer func() { span.end(err) }()
+
+	if len(hook.Git) > 0 && !repo.cloned() {
+		fmt.Printf("⊘ %s %s hook skipped, nothing checked out yet: %s\n", repo.name, stage, strings.Join(args, " "))
+		return nil
+	}
+	fmt.Printf("→ %s %s hook: %s\n", repo.name, stage, strings.Join(args, " "))
+	var cmd *exec.Cmd
+	if len(hook.Git) > 0 {
+		cmd = cfg.gitCommand(ctx, append([]string{"-C", repo.dir}, hook.Git...)...)
+	} else {
+		cmd = exec.CommandContext(ctx, hook.Run[0], hook.Run[1:]...)
+		if repo.cloned() {
+			cmd.Dir = repo.dir
+		}
+	}
+	cfg.useEnv(cmd, envShell,
+		"DECKTOOL_REPO="+repo.name,
+		"DECKTOOL_REPO_DIR="+repo.dir,
+		"DECKTOOL_REPO_URL="+repo.url,
+		"DECKTOOL_REPO_BRANCH="+repo.branch,
+		"DECKTOOL_REPO_HEAD="+head,
+		"DECKTOOL_HOOK="+stage,
+		"DECKTOOL_SYNC="+kind,
+	)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%s %s hook %q failed (exit %d); fix or remove it in %s",
+				repo.name, stage, strings.Join(args, " "), exitErr.ExitCode(), configFile)
+		}
+		return fmt.Errorf("%s %s hook %q: %w", repo.name, stage, strings.Join(args, " "), err)
+	}
+	return nil
+}
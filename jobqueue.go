@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+)
+
+// Workers that run stored jobs for serve, one render at a time each.
+// run and finish (jobrun.go) render a job and record how it ended.
+
+type jobQueue struct {
+	cfg     *config
+	metrics *serveMetrics
+	cache   *renderCache
+
+	mu        sync.Mutex
+	queue     []string
+	running   map[string]context.CancelFunc
+	cancelled map[string]bool
+	wake      chan struct{}
+}
+
+// newJobQueue loads the job store, re-queues interrupted and pending jobs,
+// and starts workers that run until ctx is cancelled. Job renders and the
+// queue depth are reported to metrics.
+func (cfg *config) newJobQueue(ctx context.Context, workers int, metrics *serveMetrics) (*jobQueue, error) {
+	if err := os.MkdirAll(cfg.jobsDir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &jobQueue{
+		cfg:       cfg,
+		metrics:   metrics,
+		cache:     &renderCache{cfg: cfg, metrics: metrics},
+		running:   make(map[string]context.CancelFunc),
+		cancelled: make(map[string]bool),
+		wake:      make(chan struct{}, 1),
+	}
+	jobs, err := cfg.listJobs()
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		switch j.Status {
+		case jobRunning:
+			log.Printf("recovering interrupted job %s", j.ID)
+			j.Status = jobPending
+			if err := cfg.saveJob(j); err != nil {
+				return nil, err
+			}
+			q.queue = append(q.queue, j.ID)
+		case jobPending:
+			q.queue = append(q.queue, j.ID)
+		}
+	}
+	metrics.jobsQueued(len(q.queue))
+	for range workers {
+		go q.work(ctx)
+	}
+	q.signal()
+	return q, nil
+}
+
+func (q *jobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *jobQueue) submit(source []byte, format string) (*job, error) {
+	j, err := q.cfg.createJob(source, format)
+	if err != nil {
+		return nil, err
+	}
+	q.mu.Lock()
+	q.queue = append(q.queue, j.ID)
+	q.metrics.jobsQueued(len(q.queue))
+	q.mu.Unlock()
+	q.signal()
+	return j, nil
+}
+
+// cancel stops a running job or drops a pending one.
+func (q *jobQueue) cancel(id string) error {
+	q.mu.Lock()
+	if stop, ok := q.running[id]; ok {
+		q.cancelled[id] = true
+		q.mu.Unlock()
+		stop()
+		return nil
+	}
+	for i, queued := range q.queue {
+		if queued == id {
+			q.queue = append(q.queue[:i], q.queue[i+1:]...)
+			break
+		}
+	}
+	q.metrics.jobsQueued(len(q.queue))
+	q.mu.Unlock()
+	return q.cfg.cancelStoredJob(id)
+}
+
+// next takes the first queued job and registers it as running in the same
+// critical section, so from then on cancel stops the run instead of writing
+// the stored job behind the worker's back.
+func (q *jobQueue) next(ctx context.Context) (string, context.Context, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.queue) > 0 {
+			id := q.queue[0]
+			q.queue = q.queue[1:]
+			q.metrics.jobsQueued(len(q.queue))
+			jobCtx, stop := context.WithCancel(ctx)
+			q.running[id] = stop
+			more := len(q.queue) > 0
+			q.mu.Unlock()
+			if more {
+				q.signal() // let another idle worker pick up the rest
+			}
+			return id, jobCtx, true
+		}
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return "", nil, false
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *jobQueue) work(ctx context.Context) {
+	for {
+		id, jobCtx, ok := q.next(ctx)
+		if !ok {
+			return
+		}
+		if err := q.run(ctx, jobCtx, id); err != nil {
+			log.Printf("job %s: %v", id, err)
+		}
+		q.mu.Lock()
+		q.release(id) // run returned before finish
+		q.mu.Unlock()
+	}
+}
+
+// release forgets a job the worker no longer runs; q.mu must be held.
+func (q *jobQueue) release(id string) {
+	if stop, ok := q.running[id]; ok {
+		stop()
+		delete(q.running, id)
+	}
+	delete(q.cancelled, id)
+}
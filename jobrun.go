@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// finish records how a run ended. It holds q.mu while saving, so a cancel
+// either lands before (and wins) or finds the job no longer running.
+func (q *jobQueue) finish(ctx context.Context, j *job, output string, err error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j.Finished = time.Now().UTC()
+	switch {
+	case q.cancelled[j.ID]:
+		j.Status = jobCancelled
+	case ctx.Err() != nil:
+		// serve is shutting down; leave the job for recovery on restart
+		j.Status, j.Finished = jobPending, time.Time{}
+	case err != nil:
+		j.Status, j.Error = jobFailed, err.Error()
+	default:
+		j.Status, j.Artifact = jobDone, filepath.Base(output)
+	}
+	q.release(j.ID)
+	return q.cfg.saveJob(j)
+}
+
+// run renders one job inside its directory, capturing tool output in job.log.
+// jobCtx is cancelled by cancel(id), ctx when serve shuts down.
+func (q *jobQueue) run(ctx, jobCtx context.Context, id string) error {
+	cfg := q.cfg
+	j, err := cfg.loadJob(id)
+	if err != nil {
+		return err
+	}
+	if j.Status != jobPending {
+		return nil // cancelled while queued
+	}
+	dir := cfg.getJobDir(id)
+	source, err := os.ReadFile(filepath.Join(dir, jobSourceFile))
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(filepath.Join(dir, jobLogFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	j.Status, j.Error, j.Started, j.Finished = jobRunning, "", time.Now().UTC(), time.Time{}
+	j.Attempts++
+	if err := cfg.saveJob(j); err != nil {
+		return err
+	}
+	fmt.Fprintf(logFile, "--- attempt %d at %s\n", j.Attempts, j.Started.Format(time.RFC3339))
+
+	start := time.Now()
+	q.metrics.renderStarted()
+	jobCtx, span := startSpan(withToolOutput(jobCtx, logFile), "job", "job.id", id, "job.format", j.Format)
+	ws := &renderWorkspace{dir: dir, keep: true}
+	output, err := q.cache.render(jobCtx, ws, strings.TrimSuffix(jobSourceFile, ".dsh"), source, j.Format)
+	span.end(err)
+	q.metrics.renderFinished(j.Format, err, time.Since(start))
+
+	if err := q.finish(ctx, j, output, err); err != nil {
+		return err
+	}
+	fmt.Fprintf(logFile, "--- %s\n", j.Status)
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Job store for serve's render queue (queue in jobqueue.go)
+//
+// Each job lives in .jobs/<id>/: job.json (state), deck.dsh (source),
+// job.log (tool output) and the rendered artifact. State survives restarts:
+// jobs left running by a crash are queued again when serve starts.
+
+type jobStatus string
+
+const (
+	jobPending   jobStatus = "pending"
+	jobRunning   jobStatus = "running"
+	jobDone      jobStatus = "done"
+	jobFailed    jobStatus = "failed"
+	jobCancelled jobStatus = "cancelled"
+)
+
+const (
+	jobStateFile  = "job.json"
+	jobSourceFile = "deck.dsh"
+	jobLogFile    = "job.log"
+)
+
+type job struct {
+	ID       string    `json:"id"`
+	Format   string    `json:"format"`
+	Status   jobStatus `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Artifact string    `json:"artifact,omitempty"` // file name inside the job directory
+	Attempts int       `json:"attempts"`
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started,omitzero"`
+	Finished time.Time `json:"finished,omitzero"`
+}
+
+func (j *job) duration() time.Duration {
+	if j.Started.IsZero() {
+		return 0
+	}
+	if j.Finished.IsZero() {
+		return time.Since(j.Started)
+	}
+	return j.Finished.Sub(j.Started)
+}
+
+// validJobID rejects IDs that could escape the job store.
+func validJobID(id string) bool {
+	return id != "" && filepath.Base(id) == id && !strings.HasPrefix(id, ".")
+}
+
+func (cfg *config) loadJob(id string) (*job, error) {
+	if !validJobID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(cfg.getJobDir(id), jobStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var j job
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("parse job %s: %w", id, err)
+	}
+	return &j, nil
+}
+
+// saveJob writes the job state atomically so a crash never leaves it half written.
+func (cfg *config) saveJob(j *job) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(cfg.getJobDir(j.ID), jobStateFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// listJobs returns all stored jobs, oldest first.
+func (cfg *config) listJobs() ([]*job, error) {
+	entries, err := os.ReadDir(cfg.jobsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*job
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		j, err := cfg.loadJob(entry.Name())
+		if err != nil {
+			log.Printf("skipping job %s: %v", entry.Name(), err)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].Created.Before(jobs[k].Created) })
+	return jobs, nil
+}
+
+// createJob stores source as a new pending job.
+func (cfg *config) createJob(source []byte, format string) (*job, error) {
+	now := time.Now().UTC()
+	j := &job{
+		ID:      now.Format("20060102-150405") + "-" + randomHex(3),
+		Format:  format,
+		Status:  jobPending,
+		Created: now,
+	}
+	dir := cfg.getJobDir(j.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, jobSourceFile), source, 0o644); err != nil {
+		return nil, err
+	}
+	return j, cfg.saveJob(j)
+}
+
+// cancelStoredJob cancels a pending job directly in the store, for when
+// serve is not running.
+func (cfg *config) cancelStoredJob(id string) error {
+	j, err := cfg.loadJob(id)
+	if err != nil {
+		return err
+	}
+	if j.Status != jobPending && j.Status != jobRunning {
+		return fmt.Errorf("job %s is already %s", id, j.Status)
+	}
+	j.Status = jobCancelled
+	j.Finished = time.Now().UTC()
+	return cfg.saveJob(j)
+}
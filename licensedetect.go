@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Detecting a module's license from the license file in its root
+
+// licenseFileNames are matched case-insensitively against a module's root.
+var licenseFileNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "LICENCE.md", "COPYING", "COPYING.md", "UNLICENSE"}
+
+// detectLicense finds the license file in a module directory and
+// classifies its text.
+func detectLicense(dir string) (id, file string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "unknown", ""
+	}
+	for _, entry := range entries {
+		for _, name := range licenseFileNames {
+			if entry.Type().IsRegular() && strings.EqualFold(entry.Name(), name) {
+				path := filepath.Join(dir, entry.Name())
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return "unknown", ""
+				}
+				return classifyLicense(string(data)), path
+			}
+		}
+	}
+	return "unknown", ""
+}
+
+// classifyLicense recognizes common license texts by their distinctive
+// phrases; anything else is "unknown" and needs a human look.
+func classifyLicense(text string) string {
+	t := strings.Join(strings.Fields(text), " ")
+	has := func(phrases ...string) bool {
+		for _, p := range phrases {
+			if !strings.Contains(t, p) {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case has("GNU AFFERO GENERAL PUBLIC LICENSE"):
+		return "AGPL-3.0"
+	case has("GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"):
+		return "LGPL-3.0"
+	case has("GNU LESSER GENERAL PUBLIC LICENSE"), has("GNU LIBRARY GENERAL PUBLIC LICENSE"):
+		return "LGPL-2.1"
+	case has("GNU GENERAL PUBLIC LICENSE", "Version 3"):
+		return "GPL-3.0"
+	case has("GNU GENERAL PUBLIC LICENSE"):
+		return "GPL-2.0"
+	case has("Mozilla Public License Version 2.0"), has("Mozilla Public License, version 2.0"):
+		return "MPL-2.0"
+	case has("Apache License", "Version 2.0"):
+		return "Apache-2.0"
+	case has("Permission is hereby granted, free of charge"):
+		return "MIT"
+	case has("Permission to use, copy, modify, and/or distribute this software for any purpose"),
+		has("Permission to use, copy, modify, and distribute this software for any purpose"):
+		return "ISC"
+	case has("Redistribution and use in source and binary forms", "Neither the name"),
+		has("Redistribution and use in source and binary forms", "names of its contributors may not be used"):
+		return "BSD-3-Clause"
+	case has("Redistribution and use in source and binary forms"):
+		return "BSD-2-Clause"
+	case has("This is free and unencumbered software released into the public domain"):
+		return "Unlicense"
+	case has("CC0 1.0 Universal"):
+		return "CC0-1.0"
+	}
+	return "unknown"
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// License inventory of shipped binaries
+//
+// Every module linked into a toolchain binary (plus the Go standard
+// library) is listed with its detected license; the license texts are
+// collected into a NOTICES file that dev release ships with the binaries
+// (detection in licensedetect.go, the file in notices.go).
+
+// licensesConfig lists licenses that block a release, e.g.
+//
+//	"licenses": {"deny": ["GPL-3.0", "AGPL-3.0", "unknown"]}
+type licensesConfig struct {
+	Deny []string `json:"deny"`
+}
+
+// licenseIDs are the licenses detectLicense recognizes, plus "unknown".
+var licenseIDs = []string{
+	"MIT", "Apache-2.0", "BSD-2-Clause", "BSD-3-Clause", "ISC", "MPL-2.0",
+	"LGPL-2.1", "LGPL-3.0", "GPL-2.0", "GPL-3.0", "AGPL-3.0", "Unlicense", "CC0-1.0", "unknown",
+}
+
+func (l licensesConfig) validate() error {
+	for _, id := range l.Deny {
+		if !slices.Contains(licenseIDs, id) {
+			return fmt.Errorf("licenses.deny: unknown license %q (known: %s)", id, strings.Join(licenseIDs, ", "))
+		}
+	}
+	return nil
+}
+
+// moduleLicense is one module linked into the shipped binaries.
+type moduleLicense struct {
+	Path, Version string
+	License       string   // SPDX id or "unknown"
+	File          string   // license file, "" when none was found
+	Binaries      []string // binaries that link the module
+}
+
+// licenseInventory lists the modules of every checked-out toolchain
+// binary, resolved through the srcDir workspace as dev build does.
+func (cfg *config) licenseInventory(ctx context.Context) ([]moduleLicense, error) {
+	if _, err := os.Stat(filepath.Join(srcDir, "go.work")); err != nil {
+		return nil, fmt.Errorf("no workspace in %s (run dev build first)", srcDir)
+	}
+	byPath := make(map[string]*moduleLicense)
+	for _, spec := range cfg.checkedOutSpecs() {
+		cmd := exec.CommandContext(ctx, cfg.goCmd, "list", "-deps",
+			"-f", "{{with .Module}}{{.Path}}\t{{.Version}}\t{{.Dir}}{{end}}", spec.pkg)
+		cmd.Dir = srcDir
+		cfg.useEnv(cmd, envGoWork)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("list modules of %s: %w: %s", spec.name, err, strings.TrimSpace(stderr.String()))
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) != 3 {
+				continue // package in the standard library
+			}
+			m, ok := byPath[fields[0]]
+			if !ok {
+				m = &moduleLicense{Path: fields[0], Version: fields[1]}
+				m.License, m.File = detectLicense(fields[2])
+				byPath[m.Path] = m
+			}
+			if !slices.Contains(m.Binaries, spec.name) {
+				m.Binaries = append(m.Binaries, spec.name)
+			}
+		}
+	}
+	if len(byPath) == 0 {
+		return nil, errors.New("no toolchain binaries checked out (run dev build first)")
+	}
+
+	// The runtime and standard library are linked into every binary
+	values, err := cfg.goEnvValues(ctx, "GOROOT", "GOVERSION")
+	if err != nil {
+		return nil, err
+	}
+	goroot, goversion := values[0], values[1]
+	std := moduleLicense{Path: "std", Version: goversion, Binaries: []string{"(all)"}}
+	std.License, std.File = detectLicense(goroot)
+
+	modules := []moduleLicense{std}
+	for _, m := range byPath {
+		sort.Strings(m.Binaries)
+		modules = append(modules, *m)
+	}
+	sort.Slice(modules[1:], func(i, j int) bool { return modules[1+i].Path < modules[1+j].Path })
+	return modules, nil
+}
+
+// deniedLicenses returns the modules whose license is denied by config.
+func (cfg *config) deniedLicenses(modules []moduleLicense) []moduleLicense {
+	var denied []moduleLicense
+	for _, m := range modules {
+		if slices.Contains(cfg.file.Licenses.Deny, m.License) {
+			denied = append(denied, m)
+		}
+	}
+	return denied
+}
+
+// checkLicenses builds the inventory, writes NOTICES and fails when a
+// module's license is denied.
+func (cfg *config) checkLicenses(ctx context.Context, verbose bool) (string, error) {
+	modules, err := cfg.licenseInventory(ctx)
+	if err != nil {
+		return "", err
+	}
+	path, err := cfg.writeNotices(modules)
+	if err != nil {
+		return "", fmt.Errorf("write %s: %w", noticesFile, err)
+	}
+	counts := make(map[string]int)
+	for _, m := range modules {
+		counts[m.License]++
+		if verbose {
+			fmt.Printf("  %-12s %s %s  (%s)\n", m.License, m.Path, m.Version, strings.Join(m.Binaries, ", "))
+		}
+	}
+	var summary []string
+	for _, id := range licenseIDs {
+		if counts[id] > 0 {
+			summary = append(summary, fmt.Sprintf("%s %d", id, counts[id]))
+		}
+	}
+	fmt.Printf("%d modules: %s\n", len(modules), strings.Join(summary, ", "))
+	denied := cfg.deniedLicenses(modules)
+	for _, m := range denied {
+		fmt.Printf("✗ %s %s is %s (used by %s)\n", m.Path, m.Version, m.License, strings.Join(m.Binaries, ", "))
+	}
+	if len(denied) > 0 {
+		return path, fmt.Errorf("%d module(s) have denied licenses (licenses.deny in %s)", len(denied), configFile)
+	}
+	return path, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Lockfile (decktool.lock): pinned repo SHAs, the corpus test baseline, the
+// toolchain channel and the Go module graph of the build.
+// It is meant to be committed so CI runs test against a known corpus.
+
+type lockFile struct {
+	Repos     map[string]lockedRepo `json:"repos"`
+	Baseline  corpusBaseline        `json:"baseline"`
+	Toolchain *toolchainPin         `json:"toolchain,omitempty"`
+	Modules   []moduleVersion       `json:"modules,omitempty"` // resolved by dev build, enforced by --frozen
+}
+
+type lockedRepo struct {
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+	SHA    string `json:"sha"`
+}
+
+// corpusBaseline records the examples already failing when the corpus was pinned.
+type corpusBaseline struct {
+	Updated time.Time `json:"updated"`
+	Failing []string  `json:"failing"`
+}
+
+// loadLockFile returns the lockfile, or an empty one if none exists.
+func loadLockFile() (*lockFile, error) {
+	lock := &lockFile{Repos: make(map[string]lockedRepo)}
+	data, err := os.ReadFile(lockFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", lockFileName, err)
+	}
+	if lock.Repos == nil {
+		lock.Repos = make(map[string]lockedRepo)
+	}
+	return lock, nil
+}
+
+func (lock *lockFile) save() error {
+	sort.Strings(lock.Baseline.Failing)
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lockFileName, append(data, '\n'), 0o644)
+}
+
+func (lock *lockFile) knownFailures() map[string]bool {
+	known := make(map[string]bool)
+	for _, name := range lock.Baseline.Failing {
+		known[name] = true
+	}
+	return known
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -13,7 +18,19 @@ func main() {
 	}
 
 	root := newRootCommand(cfg)
-	if err := root.Execute(); err != nil {
+	started := time.Now()
+	// Ctrl-C and SIGTERM cancel the context, so commands shut down cleanly and
+	// the span and history below are still recorded
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, span := startSpan(ctx, "decktool", "command", strings.Join(os.Args[1:], " "))
+	err = root.ExecuteContext(ctx)
+	stop()
+	span.end(err)
+	recordHistory(os.Args[1:], started, err, cfg.historyToolchain())
+	if ferr := flushSpans(context.Background()); ferr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", ferr)
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
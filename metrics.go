@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// Render output size and complexity metrics
+
+type renderMetrics struct {
+	XMLBytes   int64          `json:"xml_bytes"`
+	Slides     int            `json:"slides"`
+	Elements   map[string]int `json:"elements"` // deck markup element counts by tag
+	Fonts      []string       `json:"fonts"`    // font names referenced by the deck
+	PDFBytes   int64          `json:"pdf_bytes,omitempty"`
+	PDFPages   int            `json:"pdf_pages,omitempty"`
+	FontEmbeds int            `json:"font_embeds,omitempty"` // font objects in the PDF
+	RenderMS   int64          `json:"render_ms,omitempty"`   // lint, render and PDF conversion time
+}
+
+func (m renderMetrics) totalElements() int {
+	total := 0
+	for _, n := range m.Elements {
+		total += n
+	}
+	return total
+}
+
+var (
+	pdfPageRe = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfFontRe = regexp.MustCompile(`/Type\s*/Font\b`)
+)
+
+// measureRender collects metrics for a deck XML and its PDF, if present.
+func measureRender(xmlPath string) (renderMetrics, error) {
+	m := renderMetrics{Elements: make(map[string]int)}
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return m, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil {
+		m.XMLBytes = info.Size()
+	}
+
+	fonts := make(map[string]struct{})
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return m, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		m.Elements[start.Name.Local]++
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "font" && attr.Value != "" {
+				fonts[attr.Value] = struct{}{}
+			}
+		}
+	}
+	m.Slides = m.Elements["slide"]
+	for font := range fonts {
+		m.Fonts = append(m.Fonts, font)
+	}
+	sort.Strings(m.Fonts)
+
+	if data, err := os.ReadFile(render.ConvertedPath(xmlPath, "pdf")); err == nil {
+		m.PDFBytes = int64(len(data))
+		m.PDFPages = len(pdfPageRe.FindAll(data, -1))
+		m.FontEmbeds = len(pdfFontRe.FindAll(data, -1))
+	}
+	return m, nil
+}
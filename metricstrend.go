@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Render metrics history: compare output against the previous toolchain
+
+const (
+	metricsGrowthPct  = 10 // flag outputs growing by more than this percent
+	metricsHistoryMax = 10 // toolchain versions kept in the history
+)
+
+type metricsRun struct {
+	Toolchain string                   `json:"toolchain"`
+	Time      time.Time                `json:"time"`
+	Decks     map[string]renderMetrics `json:"decks"`
+}
+
+// toolchainID fingerprints the rendering binaries so metrics can be trended per toolchain.
+func (cfg *config) toolchainID() string {
+	h := sha256.New()
+	for _, tool := range []string{"decksh", "pdfdeck"} {
+		path, err := cfg.resolveBinary(tool)
+		if err != nil {
+			continue
+		}
+		if f, err := os.Open(path); err == nil {
+			io.Copy(h, f)
+			f.Close()
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+func (cfg *config) loadMetricsHistory() ([]metricsRun, error) {
+	data, err := os.ReadFile(cfg.getMetricsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var runs []metricsRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", cfg.getMetricsPath(), err)
+	}
+	return runs, nil
+}
+
+// recordMetrics stores run as the latest entry for its toolchain and returns
+// the most recent run from a different toolchain, if any.
+func (cfg *config) recordMetrics(run metricsRun) (*metricsRun, error) {
+	runs, err := cfg.loadMetricsHistory()
+	if err != nil {
+		return nil, err
+	}
+	var previous *metricsRun
+	var kept []metricsRun
+	for i := range runs {
+		if runs[i].Toolchain == run.Toolchain {
+			continue
+		}
+		kept = append(kept, runs[i])
+		previous = &runs[i]
+	}
+	kept = append(kept, run)
+	if len(kept) > metricsHistoryMax {
+		kept = kept[len(kept)-metricsHistoryMax:]
+	}
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.getMetricsPath()), 0o755); err != nil {
+		return nil, err
+	}
+	return previous, os.WriteFile(cfg.getMetricsPath(), append(data, '\n'), 0o644)
+}
+
+// compareMetrics lists decks whose output grew or changed shape between toolchains.
+func compareMetrics(prev, cur metricsRun) []string {
+	var names []string
+	for name := range cur.Decks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	grew := func(old, cur int64) bool { return old > 0 && cur > old*(100+metricsGrowthPct)/100 }
+	var findings []string
+	for _, name := range names {
+		c := cur.Decks[name]
+		p, ok := prev.Decks[name]
+		if !ok {
+			continue
+		}
+		if grew(p.XMLBytes, c.XMLBytes) {
+			findings = append(findings, fmt.Sprintf("▲ %s: XML %s -> %s", name, formatSize(p.XMLBytes), formatSize(c.XMLBytes)))
+		}
+		if grew(p.PDFBytes, c.PDFBytes) {
+			findings = append(findings, fmt.Sprintf("▲ %s: PDF %s -> %s", name, formatSize(p.PDFBytes), formatSize(c.PDFBytes)))
+		}
+		if p.FontEmbeds > 0 && c.FontEmbeds > p.FontEmbeds {
+			findings = append(findings, fmt.Sprintf("▲ %s: PDF font objects %d -> %d", name, p.FontEmbeds, c.FontEmbeds))
+		}
+		if p.PDFPages > 0 && c.PDFPages != p.PDFPages {
+			findings = append(findings, fmt.Sprintf("≠ %s: PDF pages %d -> %d", name, p.PDFPages, c.PDFPages))
+		}
+		if pe, ce := p.totalElements(), c.totalElements(); pe != ce {
+			findings = append(findings, fmt.Sprintf("≠ %s: elements %d -> %d", name, pe, ce))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Repo mirrors: a bare mirror of each upstream repo, refreshed by fetch,
+// that checkouts are cloned from and borrow objects from. dev clean and a
+// fresh ensure then only fetch what changed upstream, and every project,
+// profile and worktree of a user shares one object store per repo.
+//
+// Mirrors hold full history, so only repos cloned with full history
+// (<REPO>_DEPTH=0) use one: git cannot borrow objects from a shallow
+// repository, and a full mirror would turn the default depth-1 clone into a
+// download of every commit. Other repos clone shallowly from upstream.
+//
+// Mirrors live in ~/.decktool/mirrors, in DECKTOOL_CACHE/mirrors when the
+// multi-user cache is set (group-writable, see cache.go), or wherever
+// DECKTOOL_MIRRORS points; DECKTOOL_MIRRORS=off clones straight from
+// upstream as before.
+//
+//	<repo>.git          bare mirror (gc.auto=0: checkouts borrow its objects)
+//	.locks/<key>.lock   advisory locks serialising writers
+
+// resolveMirrorsDir returns where mirrors are kept, "" when disabled.
+func (cfg *config) resolveMirrorsDir() (string, error) {
+	switch dir := os.Getenv("DECKTOOL_MIRRORS"); dir {
+	case "off":
+		return "", nil
+	case "":
+		if cfg.cacheDir != "" {
+			return filepath.Join(cfg.cacheDir, "mirrors"), nil
+		}
+		return decktoolHomePath("mirrors")
+	default:
+		path, err := expandPath(dir)
+		if err != nil {
+			return "", fmt.Errorf("resolve DECKTOOL_MIRRORS: %w", err)
+		}
+		return path, nil
+	}
+}
+
+// mirrored reports whether repo is cloned from a mirror.
+func (cfg *config) mirrored(repo *repoConfig) bool {
+	return cfg.mirrorsDir != "" && repo.depth == 0
+}
+
+func (cfg *config) getMirrorDir(repo *repoConfig) string {
+	return filepath.Join(cfg.mirrorsDir, repo.name+".git")
+}
+
+// sharedMirrors reports whether the mirrors are in the multi-user cache,
+// where everything written must stay group-writable.
+func (cfg *config) sharedMirrors() bool {
+	return cfg.cacheDir != "" && strings.HasPrefix(cfg.mirrorsDir, cfg.cacheDir+string(filepath.Separator))
+}
+
+// ensureMirror creates or refreshes the bare mirror of repo.
+func (cfg *config) ensureMirror(ctx context.Context, repo *repoConfig) (string, error) {
+	stdout, _ := toolOutput(ctx)
+	mirror := cfg.getMirrorDir(repo)
+	err := withLock(filepath.Join(cfg.mirrorsDir, ".locks"), "mirror-"+repo.name, func() error {
+		if _, err := os.Stat(mirror); err == nil {
+			fmt.Fprintf(stdout, "Updating mirror %s\n", mirror)
+			args := append([]string{"-C", mirror, "fetch", "--prune"}, repo.filter...)
+			return cfg.runGit(ctx, append(args, "origin")...)
+		}
+		if cfg.sharedMirrors() {
+			if err := mkdirShared(cfg.mirrorsDir); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(stdout, "Creating mirror %s\n", mirror)
+		// gc.auto=0: checkouts borrow objects from the mirror, so it must never prune them
+		args := []string{"clone", "--mirror", "--config", "gc.auto=0"}
+		if cfg.sharedMirrors() {
+			args = append(args, "--config", "core.sharedRepository=group")
+		}
+		args = append(args, repo.filter...)
+		if err := cfg.runGit(ctx, append(args, repo.url, mirror)...); err != nil {
+			return err
+		}
+		if !cfg.sharedMirrors() {
+			return nil
+		}
+		// sharedRepository covers later writes; fix up what clone created under our umask
+		return filepath.WalkDir(mirror, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			mode := info.Mode().Perm() | 0o060
+			if d.IsDir() {
+				mode |= 0o010 | fs.ModeSetgid
+			}
+			return os.Chmod(path, mode)
+		})
+	})
+	return mirror, err
+}
+
+// usesMirror reports whether repo's checkout borrows objects from its
+// mirror (it was cloned with mirrors enabled).
+func (cfg *config) usesMirror(repo *repoConfig) bool {
+	if cfg.mirrorsDir == "" {
+		return false
+	}
+	alternates, err := os.ReadFile(filepath.Join(repo.dir, ".git", "objects", "info", "alternates"))
+	return err == nil && strings.Contains(string(alternates), cfg.getMirrorDir(repo))
+}
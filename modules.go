@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Module graph pinning
+//
+// dev build records the Go modules (versions and go.sum hashes) resolved
+// for every toolchain package in the build summary and in the lockfile;
+// --frozen refuses to build when resolution differs from the lockfile, so
+// a repo sync cannot silently pull in new transitive dependencies.
+// Workspace modules (the code repos themselves) are pinned by SHA instead.
+
+type moduleVersion struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"` // go.sum h1: hash
+}
+
+// checkedOutSpecs returns the toolchain binaries whose repo is cloned.
+func (cfg *config) checkedOutSpecs() []binSpec {
+	var specs []binSpec
+	for _, spec := range cfg.toolchain {
+		repo, ok := cfg.repos[spec.repo]
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err == nil {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// resolveModuleGraph lists the non-workspace modules the toolchain
+// packages build against, over every target they are built for.
+func (cfg *config) resolveModuleGraph(ctx context.Context) ([]moduleVersion, error) {
+	byPath := make(map[string]moduleVersion)
+	for _, target := range []buildTarget{targetNative, targetWASM, targetWASI} {
+		var pkgs []string
+		for _, spec := range cfg.checkedOutSpecs() {
+			if target == targetNative || (target == targetWASM && spec.wasmSupport) || (target == targetWASI && spec.wasiSupport) {
+				pkgs = append(pkgs, spec.pkg)
+			}
+		}
+		if len(pkgs) == 0 {
+			continue
+		}
+		args := append([]string{"list", "-deps", "-e", "-f",
+			"{{with .Module}}{{if not .Main}}{{.Path}}\t{{.Version}}\t{{.Sum}}{{end}}{{end}}"}, pkgs...)
+		cmd := exec.CommandContext(ctx, cfg.goCmd, args...)
+		cmd.Dir = srcDir
+		var targetEnv []string
+		if goos, goarch := target.buildEnv(); goos != "" {
+			targetEnv = []string{"GOOS=" + goos, "GOARCH=" + goarch}
+		}
+		cfg.useEnv(cmd, envGoWork, targetEnv...)
+		out, err := cmd.Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return nil, fmt.Errorf("go list (%s): %w: %s", target, err, strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return nil, fmt.Errorf("go list (%s): %w", target, err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) == 3 {
+				byPath[fields[0]] = moduleVersion{Path: fields[0], Version: fields[1], Sum: fields[2]}
+			}
+		}
+	}
+	modules := make([]moduleVersion, 0, len(byPath))
+	for _, m := range byPath {
+		modules = append(modules, m)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Path < modules[j].Path })
+	return modules, nil
+}
+
+// diffModules describes how current differs from locked, one line per module.
+func diffModules(locked, current []moduleVersion) []string {
+	old := make(map[string]moduleVersion)
+	for _, m := range locked {
+		old[m.Path] = m
+	}
+	var changes []string
+	for _, m := range current {
+		prev, ok := old[m.Path]
+		delete(old, m.Path)
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("+ %s %s", m.Path, m.Version))
+		case prev.Version != m.Version:
+			changes = append(changes, fmt.Sprintf("≠ %s %s → %s", m.Path, prev.Version, m.Version))
+		case prev.Sum != m.Sum:
+			changes = append(changes, fmt.Sprintf("≠ %s %s: go.sum hash changed", m.Path, m.Version))
+		}
+	}
+	for _, m := range old {
+		changes = append(changes, fmt.Sprintf("- %s %s", m.Path, m.Version))
+	}
+	sort.Slice(changes, func(i, j int) bool { // by module path
+		return strings.Fields(changes[i])[1] < strings.Fields(changes[j])[1]
+	})
+	return changes
+}
+
+// pinModuleGraph resolves the module graph and checks it against the
+// lockfile. Frozen builds fail on any difference; otherwise changes are
+// reported and recorded.
+func (cfg *config) pinModuleGraph(ctx context.Context, frozen bool) ([]moduleVersion, error) {
+	modules, err := cfg.resolveModuleGraph(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolve modules: %w", err)
+	}
+	lock, err := loadLockFile()
+	if err != nil {
+		return nil, err
+	}
+	if frozen && lock.Modules == nil {
+		return nil, fmt.Errorf("%s records no module graph (run dev build without --frozen once)", lockFileName)
+	}
+	changes := diffModules(lock.Modules, modules)
+	if len(changes) == 0 {
+		fmt.Printf("✓ %d modules match %s\n", len(modules), lockFileName)
+		return modules, nil
+	}
+	if frozen {
+		fmt.Printf("Module resolution differs from %s:\n", lockFileName)
+	} else if lock.Modules != nil {
+		fmt.Printf("Module graph changed since %s was written:\n", lockFileName)
+	}
+	if lock.Modules != nil {
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	if frozen {
+		return nil, fmt.Errorf("refusing a frozen build: %d module change(s)", len(changes))
+	}
+	lock.Modules = modules
+	if err := lock.save(); err != nil {
+		return nil, err
+	}
+	fmt.Printf("✓ Recorded %d modules in %s\n", len(modules), lockFileName)
+	return modules, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NOTICES: the third-party license texts dev release ships with the binaries
+
+const noticesFile = "NOTICES"
+
+// writeNotices writes the third-party notices for the modules into the
+// attestations directory and returns its path.
+func (cfg *config) writeNotices(modules []moduleLicense) (string, error) {
+	var b bytes.Buffer
+	b.WriteString("Third-party software notices for the deck toolchain binaries\n")
+	b.WriteString("Generated by decktool; one section per Go module linked into the binaries.\n")
+	for _, m := range modules {
+		fmt.Fprintf(&b, "\n%s\n%s %s (%s)\nUsed by: %s\n\n", strings.Repeat("=", 78), m.Path, m.Version, m.License, strings.Join(m.Binaries, ", "))
+		if m.File == "" {
+			b.WriteString("No license file found in the module.\n")
+			continue
+		}
+		text, err := os.ReadFile(m.File)
+		if err != nil {
+			return "", err
+		}
+		b.Write(bytes.TrimRight(text, "\n"))
+		b.WriteString("\n")
+	}
+	path := filepath.Join(filepath.Dir(cfg.getProvenancePath()), noticesFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, b.Bytes(), 0o644)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Notifications: result summaries of automated runs (scheduled jobs, CI
+// test runs) posted to chat, webhook or email sinks configured in
+// decktool.json. A sink that cannot be reached is reported and never fails
+// the run. The message itself is built in notifymessage.go.
+
+const notifyTimeout = 15 * time.Second
+
+var notifyEvents = []string{"test", "schedule"}
+
+type notifyConfig struct {
+	Sinks []notifySink `json:"sinks"`
+}
+
+// notifySink is one destination for run summaries.
+type notifySink struct {
+	Type    string            `json:"type"`    // slack, discord, webhook or email
+	URL     string            `json:"url"`     // incoming webhook URL
+	URLEnv  string            `json:"url_env"` // environment variable holding the URL instead
+	Headers map[string]string `json:"headers"` // extra request headers (webhook)
+	On      string            `json:"on"`      // always (default) or failure
+	Events  []string          `json:"events"`  // runs to report (default all: test, schedule)
+
+	emailSink
+}
+
+func (c notifyConfig) validate() error {
+	for i, sink := range c.Sinks {
+		if err := sink.validate(); err != nil {
+			return fmt.Errorf("notify.sinks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s notifySink) validate() error {
+	switch s.Type {
+	case "slack", "discord", "webhook":
+		if (s.URL == "") == (s.URLEnv == "") {
+			return errors.New("exactly one of url and url_env is required")
+		}
+	case "email":
+		if err := s.emailSink.validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown type %q (slack, discord, webhook or email)", s.Type)
+	}
+	switch s.On {
+	case "", "always", "failure":
+	default:
+		return fmt.Errorf("on: %q is not always or failure", s.On)
+	}
+	for _, event := range s.Events {
+		if !slices.Contains(notifyEvents, event) {
+			return fmt.Errorf("unknown event %q (%s)", event, strings.Join(notifyEvents, ", "))
+		}
+	}
+	return nil
+}
+
+func (s notifySink) wants(n runNotification) bool {
+	if s.On == "failure" && n.OK {
+		return false
+	}
+	return len(s.Events) == 0 || slices.Contains(s.Events, n.Event)
+}
+
+func (s notifySink) url() (string, error) {
+	if s.URLEnv == "" {
+		return s.URL, nil
+	}
+	url := os.Getenv(s.URLEnv)
+	if url == "" {
+		return "", fmt.Errorf("%s is not set", s.URLEnv)
+	}
+	return url, nil
+}
+
+// payload encodes the notification for the sink's API.
+func (s notifySink) payload(n runNotification) ([]byte, error) {
+	switch s.Type {
+	case "slack":
+		return json.Marshal(map[string]string{"text": n.text(3000)})
+	case "discord":
+		return json.Marshal(map[string]string{"content": n.text(2000)})
+	default:
+		return json.Marshal(n)
+	}
+}
+
+func (s notifySink) send(ctx context.Context, n runNotification) error {
+	if s.Type == "email" {
+		return s.emailSink.send(ctx, n)
+	}
+	url, err := s.url()
+	if err != nil {
+		return err
+	}
+	body, err := s.payload(n)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return nil
+}
+
+// notify posts n to every sink that wants it.
+func (cfg *config) notify(ctx context.Context, n runNotification) {
+	n.Time = time.Now().UTC()
+	for _, sink := range cfg.file.Notify.Sinks {
+		if !sink.wants(n) {
+			continue
+		}
+		if err := sink.send(ctx, n); err != nil {
+			fmt.Printf("⚠ %s notification not sent: %v\n", sink.Type, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// Email notifications: the run summary as an HTML report (with a plain-text
+// alternative) sent over SMTP, for teams that follow nightly runs by mail.
+
+type emailSink struct {
+	SMTP        string   `json:"smtp"`         // server host:port; port 465 uses TLS, others STARTTLS when offered
+	From        string   `json:"from"`         // sender address
+	To          []string `json:"to"`           // recipients
+	Username    string   `json:"username"`     // SMTP login, if the server needs one
+	PasswordEnv string   `json:"password_env"` // environment variable holding the password
+}
+
+func (e emailSink) validate() error {
+	if _, _, err := net.SplitHostPort(e.SMTP); err != nil {
+		return fmt.Errorf("smtp: want host:port: %w", err)
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	if len(e.To) == 0 {
+		return errors.New("to: at least one recipient is required")
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("to: %w", err)
+		}
+	}
+	if e.Username != "" && e.PasswordEnv == "" {
+		return errors.New("username needs password_env")
+	}
+	return nil
+}
+
+var emailReport = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+<h2 style="color: {{if .OK}}#1a7f37{{else}}#cf222e{{end}}">{{if .OK}}&#10003;{{else}}&#10007;{{end}} {{.Title}}</h2>
+<p>{{.Summary}}</p>
+{{if .Details}}<ul>{{range .Details}}<li><code>{{.}}</code></li>{{end}}</ul>{{end}}
+{{if .URL}}<p><a href="{{.URL}}">Full report</a></p>{{end}}
+<p style="color: #666; font-size: small">decktool, {{.Time.Format "2006-01-02 15:04 MST"}}</p>
+</body></html>
+`))
+
+// message builds a multipart/alternative mail with text and HTML parts.
+func (e emailSink) message(n runNotification) ([]byte, error) {
+	var html bytes.Buffer
+	if err := emailReport.Execute(&html, n); err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, part := range [][2]string{{"text/plain", n.text(1 << 20)}, {"text/html", html.String()}} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part[0] + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		qp.Write([]byte(part[1]))
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	mark := "OK"
+	if !n.OK {
+		mark = "FAILED"
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", fmt.Sprintf("[decktool] %s %s: %s", mark, n.Title, n.Summary)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func (e emailSink) send(ctx context.Context, n runNotification) error {
+	msg, err := e.message(n)
+	if err != nil {
+		return err
+	}
+	host, port, _ := net.SplitHostPort(e.SMTP)
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", e.SMTP)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if port == "465" {
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && port != "465" {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		password := os.Getenv(e.PasswordEnv)
+		if password == "" {
+			return fmt.Errorf("%s is not set", e.PasswordEnv)
+		}
+		if err := client.Auth(smtp.PlainAuth("", e.Username, password, host)); err != nil {
+			return err
+		}
+	}
+	from, _ := mail.ParseAddress(e.From)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		addr, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Run notifications: the summary of an automated run and its text
+
+// runNotification summarizes one automated run; webhook sinks receive it
+// as JSON.
+type runNotification struct {
+	Event   string    `json:"event"` // test or schedule
+	Title   string    `json:"title"`
+	OK      bool      `json:"ok"`
+	Summary string    `json:"summary"`
+	Details []string  `json:"details,omitempty"` // failures and regressions
+	URL     string    `json:"url,omitempty"`     // report link
+	Time    time.Time `json:"time"`
+}
+
+// text renders the notification as a short plain-text message.
+func (n runNotification) text(limit int) string {
+	mark := "✓"
+	if !n.OK {
+		mark = "✗"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %s", mark, n.Title, n.Summary)
+	for i, d := range n.Details {
+		line := "\n• " + d
+		if b.Len()+len(line) > limit-60 {
+			fmt.Fprintf(&b, "\n… and %d more", len(n.Details)-i)
+			break
+		}
+		b.WriteString(line)
+	}
+	if n.URL != "" {
+		b.WriteString("\nReport: " + n.URL)
+	}
+	return b.String()
+}
+
+// testNotification summarizes a test run.
+func testNotification(summary deckTestSummary, runErr error, reportURL string) runNotification {
+	n := runNotification{
+		Event:   "test",
+		Title:   "decktool test",
+		OK:      runErr == nil,
+		Summary: summary.String(),
+		Details: summary.failures,
+		URL:     reportURL,
+	}
+	if summary.total == 0 && runErr != nil {
+		n.Summary = runErr.Error()
+	}
+	if len(summary.pending) > 0 {
+		n.Details = append(slices.Clone(n.Details), fmt.Sprintf("%d slide(s) differ from golden images", len(summary.pending)))
+	}
+	return n
+}
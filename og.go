@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Social preview (Open Graph) images
+//
+// The example's title slide is rendered as usual, then placed on a
+// 1200×630 canvas by a generated deck, so the overlay is typeset with the
+// deck fonts by the same decksh/pngdeck toolchain.
+
+const (
+	ogWidth  = 1200
+	ogHeight = 630
+)
+
+// renderOGImage writes the social card for an example to out. A non-empty
+// title is drawn in a band across the bottom.
+func (cfg *config) renderOGImage(ctx context.Context, name, title, out string) error {
+	tmp, err := os.MkdirTemp("", "decktool-og-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	slide := filepath.Join(tmp, "slide.png")
+	if err := cfg.renderThumbnail(ctx, name, slide); err != nil {
+		return err
+	}
+	f, err := os.Open(slide)
+	if err != nil {
+		return err
+	}
+	img, _, err := image.DecodeConfig(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("title slide: %w", err)
+	}
+	if img.Width == 0 || img.Height == 0 {
+		return errors.New("title slide is empty")
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, "og.dsh"), []byte(ogDeck(slide, img.Width, img.Height, title)), 0o644); err != nil {
+		return err
+	}
+	xmlPath := filepath.Join(tmp, "og.xml")
+	if err := cfg.renderDeck(ctx, tmp, "og.dsh", xmlPath); err != nil {
+		return err
+	}
+	if err := cfg.convertDeck(ctx, tmp, xmlPath, "png"); err != nil {
+		return err
+	}
+	pages, _ := filepath.Glob(filepath.Join(tmp, "og-*.png"))
+	if len(pages) == 0 {
+		return errors.New("no social card rendered")
+	}
+	if err := copyFile(pages[0], out); err != nil {
+		return err
+	}
+	recordArtifact(out)
+	return nil
+}
+
+// ogDeck scales the slide to cover the card, centred, cropping the overflow.
+func ogDeck(slide string, width, height int, title string) string {
+	scale := max(float64(ogWidth)/float64(width), float64(ogHeight)/float64(height))
+	var b strings.Builder
+	fmt.Fprintf(&b, "deck\ncanvas %d %d\nslide \"white\"\n", ogWidth, ogHeight)
+	fmt.Fprintf(&b, "image %q 50 50 %d %d\n", slide, int(float64(width)*scale+0.5), int(float64(height)*scale+0.5))
+	if title != "" {
+		fmt.Fprintf(&b, "rect 50 9 100 18 \"black\" 65\n")
+		fmt.Fprintf(&b, "ctext %q 50 6.5 5 \"sans\" \"white\"\n", strings.ReplaceAll(title, `"`, "'"))
+	}
+	b.WriteString("eslide\nedeck\n")
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Output naming. Examples from different sources share names (deckviz/bar,
+// dubois/bar), so results are keyed by the normalized source/name and files
+// written to a shared directory are named by source as well. The names a
+// command is about to write are checked for collisions before anything
+// renders, instead of one output silently replacing another.
+
+// exampleKey is how an example is keyed in results across run, view and
+// reports: the normalized source/name, or the argument itself for stdin
+// and URL decks.
+func (cfg *config) exampleKey(raw string) string {
+	if isAdHocDeck(raw) {
+		return raw
+	}
+	return cfg.normalizeExampleName(raw)
+}
+
+// adHocName is the name a stdin or URL deck renders under: the last path
+// element of the URL, without query or fragment.
+func adHocName(raw string) string {
+	if raw == "-" {
+		return "stdin"
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "url"
+	}
+	switch name := strings.TrimSuffix(path.Base(u.Path), ".dsh"); name {
+	case "", ".", "/":
+		if u.Hostname() != "" {
+			return u.Hostname()
+		}
+		return "url"
+	default:
+		return name
+	}
+}
+
+// outputName is where an example's files go in a shared output directory,
+// relative and without extension: <source>/<name>, or the ad-hoc name.
+func (cfg *config) outputName(raw string) string {
+	if isAdHocDeck(raw) {
+		return adHocName(raw)
+	}
+	return cfg.normalizeExampleName(raw)
+}
+
+// outputPath is the XML path of an example under the shared directory dir.
+func (cfg *config) outputPath(dir, raw string) string {
+	return filepath.Join(dir, filepath.FromSlash(cfg.outputName(raw))+".xml")
+}
+
+// uniqueExamples drops repeats of the same example ("bar" and "deckviz/bar"),
+// keeping the first.
+func (cfg *config) uniqueExamples(examples []string) []string {
+	var out, seen []string
+	for _, raw := range examples {
+		key := cfg.exampleKey(raw)
+		if slices.Contains(seen, key) {
+			continue
+		}
+		seen = append(seen, key)
+		out = append(out, raw)
+	}
+	return out
+}
+
+// checkCollisions fails when different keys map to the same output name.
+func checkCollisions(keys []string, name func(string) string) error {
+	byName := make(map[string][]string)
+	var names []string
+	for _, key := range keys {
+		n := name(key)
+		if !slices.Contains(byName[n], key) {
+			if len(byName[n]) == 0 {
+				names = append(names, n)
+			}
+			byName[n] = append(byName[n], key)
+		}
+	}
+	var clashes []string
+	for _, n := range names {
+		switch len(byName[n]) {
+		case 1:
+		case 2:
+			clashes = append(clashes, fmt.Sprintf("%s and %s would both be written as %q", byName[n][0], byName[n][1], n))
+		default:
+			clashes = append(clashes, fmt.Sprintf("%s would all be written as %q", strings.Join(byName[n], ", "), n))
+		}
+	}
+	if len(clashes) > 0 {
+		return fmt.Errorf("output name collision: %s; rename one or render them separately", strings.Join(clashes, "; "))
+	}
+	return nil
+}
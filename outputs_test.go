@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestAdHocName(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"-", "stdin"},
+		{"https://example.com/decks/chart.dsh", "chart"},
+		{"https://example.com/decks/chart.dsh?token=abc", "chart"},
+		{"https://example.com/decks/chart.dsh#slide2", "chart"},
+		{"https://example.com/raw/chart?ref=main&path=x/y", "chart"},
+		{"https://example.com/", "example.com"},
+		{"https://example.com?deck=1", "example.com"},
+	}
+	for _, tt := range tests {
+		if got := adHocName(tt.raw); got != tt.want {
+			t.Errorf("adHocName(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
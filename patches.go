@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Local patches applied to code repos before building, e.g.
+//
+//	"build": {"patches": {"decksh": ["patches/decksh-fix-arc.patch"]}}
+//
+// Patched checkouts are reset to their HEAD (dropping files earlier patches
+// added) and the patches applied again, so they are re-applied cleanly
+// after every update; one that no longer applies stops the build.
+
+func (b buildConfig) validate() error {
+	for repo, patches := range b.Patches {
+		for _, patch := range patches {
+			if _, err := os.Stat(patch); err != nil {
+				return fmt.Errorf("build.patches[%q]: %w", repo, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyPatches applies the configured patches to every code repo.
+func (cfg *config) applyPatches(ctx context.Context) error {
+	var names []string
+	for name := range cfg.file.Build.Patches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		repo, ok := cfg.repos[name]
+		if !ok || repo.isData {
+			return fmt.Errorf("build.patches: %q is not a code repo (%s)", name, strings.Join(cfg.codeRepoNames(), ", "))
+		}
+		if err := cfg.runGit(ctx, "-C", repo.dir, "reset", "--quiet", "--hard"); err != nil {
+			return err
+		}
+		if err := cfg.runGit(ctx, "-C", repo.dir, "clean", "--quiet", "-fd"); err != nil {
+			return err
+		}
+		for _, patch := range cfg.file.Build.Patches[name] {
+			if err := cfg.applyPatch(ctx, repo, patch); err != nil {
+				return err
+			}
+		}
+		if err := cfg.markPatched(ctx, repo); err != nil {
+			return fmt.Errorf("record patches in %s: %w", repo.name, err)
+		}
+	}
+	return nil
+}
+
+func (cfg *config) applyPatch(ctx context.Context, repo *repoConfig, patch string) error {
+	abs, err := filepath.Abs(patch)
+	if err != nil {
+		return err
+	}
+	check := cfg.gitCommand(ctx, "-C", repo.dir, "apply", "--check", abs)
+	if out, err := check.CombinedOutput(); err != nil {
+		head, _ := cfg.gitHead(ctx, repo)
+		return fmt.Errorf("patch %s no longer applies to %s at %s (upstream may have merged or changed it; update or remove it in %s):\n%s",
+			patch, repo.name, shortSHA(head), configFile, strings.TrimSpace(string(out)))
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "apply", abs); err != nil {
+		return fmt.Errorf("apply %s to %s: %w", patch, repo.name, err)
+	}
+	fmt.Printf("✓ Applied %s to %s\n", patch, repo.name)
+	return nil
+}
@@ -11,6 +11,114 @@ func (cfg *config) getDistGlob() string {
 	return filepath.Join(filepath.Base(cfg.distDir), "*")
 }
 
+func (cfg *config) getRefDistDir(repo, ref string) string {
+	return filepath.Join(cfg.distDir, "refs", repo+"@"+refDirName(ref))
+}
+
+func (cfg *config) getBuildLogDir() string {
+	return filepath.Join(cfg.distDir, "logs")
+}
+
+func (cfg *config) getBuildLogPath(artifact string) string {
+	return filepath.Join(cfg.getBuildLogDir(), artifact+".log")
+}
+
+func (cfg *config) getBuildSummaryPath() string {
+	return filepath.Join(cfg.getBuildLogDir(), "summary.json")
+}
+
+func (cfg *config) getProvenancePath() string {
+	return filepath.Join(cfg.distDir, "attestations", provenanceFile)
+}
+
+func (cfg *config) getToolchainsDir() string {
+	return filepath.Join(cfg.distDir, "toolchains")
+}
+
+func (cfg *config) getToolchainSetDir(tag string) string {
+	return filepath.Join(cfg.getToolchainsDir(), tag)
+}
+
+func (cfg *config) getToolchainStatePath() string {
+	return filepath.Join(cfg.getToolchainsDir(), "state.json")
+}
+
+func (cfg *config) getCLISurfacePath() string {
+	return filepath.Join(cfg.distDir, "attestations", cliSurfaceFile)
+}
+
+func (cfg *config) getGalleryDir() string {
+	return filepath.Join(cfg.distDir, "gallery")
+}
+
+func (cfg *config) getOGImagePath(example string) string {
+	return filepath.Join(cfg.distDir, "og", catalogID(example)+".png")
+}
+
+func (cfg *config) getHandoutPath(example string) string {
+	return filepath.Join(cfg.distDir, "handout", catalogID(example)+".pdf")
+}
+
+func (cfg *config) getDataCacheDir() string {
+	return filepath.Join(cfg.distDir, "data")
+}
+
+func (cfg *config) getRenderCachePath(key, format string) string {
+	return filepath.Join(cfg.distDir, "renders", key+"."+format)
+}
+
+func (cfg *config) getScheduleStatePath() string {
+	return filepath.Join(cfg.distDir, "schedule.json")
+}
+
+func (cfg *config) getShimDir() string {
+	return filepath.Join(cfg.distDir, "bin")
+}
+
+func (cfg *config) getFeatureDir() string {
+	return filepath.Join(cfg.testDir, "features")
+}
+
+func (cfg *config) getMetricsPath() string {
+	return filepath.Join(cfg.testDir, "metrics.json")
+}
+
+func (cfg *config) getTestSnapshotPath() string {
+	return filepath.Join(cfg.testDir, "snapshot.json")
+}
+
+func (cfg *config) getCrashersDir() string {
+	return filepath.Join(cfg.testDir, "crashers")
+}
+
+func (cfg *config) getGoldenDir(deck string) string {
+	return filepath.Join(cfg.testDir, "golden", filepath.FromSlash(deck))
+}
+
+func (cfg *config) getDiffDir(deck string) string {
+	return filepath.Join(cfg.testDir, "diff", filepath.FromSlash(deck))
+}
+
+func (cfg *config) getTriageReportPath(example string) string {
+	return filepath.Join(cfg.testDir, "triage", catalogID(example)+".md")
+}
+
+func (cfg *config) getPendingDiffsPath() string {
+	return filepath.Join(cfg.testDir, "pending.json")
+}
+
+func (cfg *config) getJobDir(id string) string {
+	return filepath.Join(cfg.jobsDir, id)
+}
+
+func (cfg *config) getArchiveObjectPath(sum string) string {
+	return filepath.Join(cfg.archiveDir, "objects", sum[:2], sum)
+}
+
+func (cfg *config) getSnapshotDir() string {
+	return filepath.Join(cfg.archiveDir, "snapshots")
+}
+
 func (cfg *config) getGoBinPath(name string) string {
 	return filepath.Join(cfg.goBinDir, name)
 }
@@ -22,7 +130,10 @@ func (cfg *config) getExampleDir(source, name string) (string, error) {
 	case "dubois":
 		return filepath.Join(cfg.repos["dubois"].dir, name), nil
 	default:
-		return "", fmt.Errorf("unknown example source %q", source)
+		if c, ok := registeredCorpus(source); ok {
+			return filepath.Join(c.Dir, name), nil
+		}
+		return "", fmt.Errorf("unknown example source %q (decktool registry lists the known ones)", source)
 	}
 }
 
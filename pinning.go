@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Corpus pinning: check data repos out at the SHAs recorded in the lockfile
+
+func (cfg *config) gitHead(ctx context.Context, repo *repoConfig) (string, error) {
+	return cfg.gitOutput(ctx, "-C", repo.dir, "rev-parse", "HEAD")
+}
+
+// ensurePinnedRepos syncs data repos, checking out pinned SHAs where the
+// lockfile has them and following the branch otherwise.
+func (cfg *config) ensurePinnedRepos(ctx context.Context, lock *lockFile) error {
+	for name, repo := range cfg.repos {
+		if !repo.isData {
+			continue
+		}
+		pin, ok := lock.Repos[name]
+		if !ok {
+			if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
+				return err
+			}
+			continue
+		}
+		err := cfg.withSyncHooks(ctx, repo, "pin", func() error { return cfg.gitCheckoutPinned(ctx, repo, pin.SHA) })
+		if err != nil {
+			return fmt.Errorf("pin %s to %s: %w", name, shortSHA(pin.SHA), err)
+		}
+	}
+	return nil
+}
+
+func (cfg *config) gitCheckoutPinned(ctx context.Context, repo *repoConfig, sha string) error {
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+		if err := cfg.gitClone(ctx, repo); err != nil {
+			return err
+		}
+	}
+	if head, err := cfg.gitHead(ctx, repo); err == nil && head == sha {
+		return nil
+	}
+
+	fmt.Printf("Checking out %s at pinned %s\n", repo.dir, shortSHA(sha))
+	args := []string{"-C", repo.dir, "fetch"}
+	if repo.depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+	}
+	args = append(args, repo.filter...)
+	args = append(args, "origin", sha)
+	if err := cfg.runGit(ctx, args...); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "checkout", "--detach", sha); err != nil {
+		return err
+	}
+	return cfg.syncRepoExtras(ctx, repo)
+}
+
+// recordCorpusPins stores the current HEAD of every data repo in the lockfile
+// and prints what moved.
+func (cfg *config) recordCorpusPins(ctx context.Context, lock *lockFile) error {
+	var names []string
+	for name, repo := range cfg.repos {
+		if repo.isData {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		repo := cfg.repos[name]
+		head, err := cfg.gitHead(ctx, repo)
+		if err != nil {
+			return fmt.Errorf("read %s HEAD: %w", name, err)
+		}
+		if old := lock.Repos[name].SHA; old != head {
+			fmt.Printf("⟳ %s: %s -> %s\n", name, shortSHA(old), shortSHA(head))
+		} else {
+			fmt.Printf("✓ %s: unchanged at %s\n", name, shortSHA(head))
+		}
+		lock.Repos[name] = lockedRepo{URL: repo.url, Branch: repo.branch, SHA: head}
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "(none)"
+	}
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
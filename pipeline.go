@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+)
+
+// Render/convert pipelining for runs over many examples. Rendering is
+// mostly decksh and conversion mostly the converters, so while one
+// example's outputs are converted the next example already renders.
+
+// convertPipeline runs the conversion stage of one example at a time in the
+// background, in order: add waits for the previous stage before starting
+// the next, and wait for the last. A stage's tool output is held back and
+// printed when it finishes, so it does not interleave with the render that
+// runs alongside.
+type convertPipeline struct {
+	done chan struct{}
+}
+
+// add starts stage once the previous one has finished.
+func (p *convertPipeline) add(ctx context.Context, stage func(context.Context)) {
+	p.wait()
+	done := make(chan struct{})
+	p.done = done
+	go func() {
+		defer close(done)
+		var buf bytes.Buffer
+		stage(withToolOutput(ctx, &buf))
+		stdout, _ := toolOutput(ctx)
+		buf.WriteTo(stdout)
+	}()
+}
+
+// wait blocks until the last stage added has finished.
+func (p *convertPipeline) wait() {
+	if p.done != nil {
+		<-p.done
+	}
+}
+
+// pipelineOutputs renders each example and converts it to formats, the
+// conversion overlapping the next render. done is called in order for each
+// example (by index), from the conversion stage, with the files written or
+// the error.
+func (cfg *config) pipelineOutputs(ctx context.Context, examples, formats []string, done func(ctx context.Context, i int, outputs []string, err error)) {
+	var p convertPipeline
+	for i, example := range examples {
+		xmlPath, err := cfg.renderChecked(ctx, example)
+		p.add(ctx, func(ctx context.Context) {
+			if err != nil {
+				done(ctx, i, nil, err)
+				return
+			}
+			outputs, err := cfg.convertOutputs(ctx, xmlPath, formats)
+			done(ctx, i, outputs, err)
+		})
+	}
+	p.wait()
+}
@@ -0,0 +1,200 @@
+// Package render runs the decksh pipeline with a toolchain: lint a .dsh
+// script, render it to deck XML and convert the XML to PDF, PNG or SVG.
+// It is what "decktool run" does for one deck, without the CLI's repo
+// syncing, data connectors and reporting.
+//
+//	tools, err := toolchain.Open(".")
+//	r := render.Renderer{Tools: tools, FontsDir: ".fonts"}
+//	xml, err := r.Deck(ctx, "decks/quarterly", "quarterly", "pdf")
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/deck-test/pkg/toolchain"
+)
+
+// Converters maps each output format to the tool that writes it.
+var Converters = map[string]string{
+	"pdf": "pdfdeck",
+	"png": "pngdeck",
+	"svg": "svgdeck",
+}
+
+// Formats returns the supported output formats, sorted.
+func Formats() []string {
+	var formats []string
+	for f := range Converters {
+		formats = append(formats, f)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// Renderer runs the deck tools of a toolchain. Relative paths in a deck
+// (images, data files, includes) resolve against the directory given to
+// each call.
+type Renderer struct {
+	Tools    toolchain.Toolchain
+	FontsDir string    // deckfonts checkout (DECKFONTS); "" keeps the one in Env
+	Env      []string  // environment for the tools; nil is the process environment
+	Stdout   io.Writer // tool progress; nil is os.Stdout
+	Stderr   io.Writer // tool errors; nil is os.Stderr
+}
+
+func (r Renderer) command(ctx context.Context, tool, dir string, args ...string) (*exec.Cmd, error) {
+	path, err := r.Tools.Resolve(tool)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	if r.Env != nil {
+		cmd.Env = slices.Clone(r.Env)
+	}
+	if r.FontsDir != "" {
+		cmd.Env = setEnv(cmd.Env, "DECKFONTS", r.FontsDir)
+	}
+	cmd.Stdout, cmd.Stderr = r.Stdout, r.Stderr
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return cmd, nil
+}
+
+// setEnv sets key in env, dropping any entries for it already there, so the
+// tool sees exactly one.
+func setEnv(env []string, key, value string) []string {
+	env = slices.DeleteFunc(env, func(kv string) bool {
+		k, _, _ := strings.Cut(kv, "=")
+		return k == key
+	})
+	return append(env, key+"="+value)
+}
+
+// Lint checks script (relative to dir) with dshlint.
+func (r Renderer) Lint(ctx context.Context, dir, script string) error {
+	cmd, err := r.command(ctx, "dshlint", dir, script)
+	if err != nil {
+		return err
+	}
+	return cmd.Run()
+}
+
+// Render runs decksh on script (relative to dir) and writes the deck XML
+// to output. With src set, decksh reads the script from it instead of the
+// file, e.g. after rewriting includes; dir still anchors relative paths.
+//
+// The XML is streamed to a temp file next to output and renamed into place
+// once decksh succeeds, so output is either the complete new deck or left
+// as it was; a failed render never leaves a partial file behind. A failure
+// is a *ToolError holding decksh's stderr.
+func (r Renderer) Render(ctx context.Context, dir, script string, src io.Reader, output string) error {
+	args := []string{script}
+	if src != nil {
+		args = nil
+	}
+	cmd, err := r.command(ctx, "decksh", dir, args...)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	cmd.Stdin = src
+	cmd.Stdout = tmp
+	if err := r.run(cmd, "decksh"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp makes the file private; the XML is as readable as any
+	// other file the user writes
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), output)
+}
+
+// Convert converts deck XML to format, next to the XML: one file for pdf
+// (see ConvertedPath), one per slide (<name>-00001.png, ...) for png and svg.
+// A failed conversion is a *ToolError.
+func (r Renderer) Convert(ctx context.Context, dir, xmlPath, format string) error {
+	tool, ok := Converters[format]
+	if !ok {
+		return fmt.Errorf("unknown output format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	cmd, err := r.command(ctx, tool, dir, "-outdir", filepath.Dir(xmlPath), xmlPath)
+	if err != nil {
+		return err
+	}
+	return r.run(cmd, tool)
+}
+
+// ToolError is a deck tool that failed, with what it wrote to stderr.
+type ToolError struct {
+	Tool   string
+	Err    error
+	Stderr string
+}
+
+func (e *ToolError) Error() string { return e.Tool + ": " + e.Err.Error() }
+func (e *ToolError) Unwrap() error { return e.Err }
+
+// run runs cmd, keeping a copy of its stderr for the *ToolError when it
+// fails.
+func (r Renderer) run(cmd *exec.Cmd, tool string) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, &stderr)
+	if err := cmd.Run(); err != nil {
+		return &ToolError{Tool: tool, Err: err, Stderr: stderr.String()}
+	}
+	return nil
+}
+
+// ConvertedPath returns where Convert writes single-file formats (pdf).
+func ConvertedPath(xmlPath, format string) string {
+	return strings.TrimSuffix(xmlPath, filepath.Ext(xmlPath)) + "." + format
+}
+
+// Deck lints and renders dir/<name>.dsh to dir/<name>.xml and converts it
+// to each format, returning the XML path.
+func (r Renderer) Deck(ctx context.Context, dir, name string, formats ...string) (string, error) {
+	script := name + ".dsh"
+	if err := r.Lint(ctx, dir, script); err != nil {
+		return "", fmt.Errorf("lint %s: %w", script, err)
+	}
+	xmlPath := filepath.Join(dir, name+".xml")
+	if err := r.Render(ctx, dir, script, nil, xmlPath); err != nil {
+		return "", fmt.Errorf("render %s: %w", script, err)
+	}
+	for _, format := range formats {
+		if err := r.Convert(ctx, dir, xmlPath, format); err != nil {
+			return "", err
+		}
+	}
+	return xmlPath, nil
+}
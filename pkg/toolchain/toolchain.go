@@ -0,0 +1,98 @@
+// Package toolchain finds the deck tools (decksh, dshlint, pdfdeck,
+// pngdeck, svgdeck, ...) of a decktool workspace, so Go programs can run
+// the same binaries the decktool CLI manages. Downloading and updating the
+// toolchain stays with "decktool ensure".
+package toolchain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DistDir is where a workspace keeps its downloaded release binaries.
+const DistDir = ".dist"
+
+// ErrNotFound is returned (wrapped) when a tool is not installed anywhere.
+var ErrNotFound = errors.New("tool not found")
+
+// Toolchain is a set of places to look for the tools, in order: the
+// downloaded release binaries, PATH, then the Go bin directory.
+type Toolchain struct {
+	DistDir  string // release binaries named <tool>-<goos>-<goarch>
+	GoBinDir string // where go install puts binaries; "" to skip
+}
+
+// Open returns the toolchain of the decktool workspace at root, using the
+// go command on PATH to find the Go bin directory.
+func Open(root string) (Toolchain, error) {
+	dist, err := filepath.Abs(filepath.Join(root, DistDir))
+	if err != nil {
+		return Toolchain{}, err
+	}
+	bin, err := GoBinDir("go")
+	if err != nil {
+		return Toolchain{}, err
+	}
+	return Toolchain{DistDir: dist, GoBinDir: bin}, nil
+}
+
+// BinaryPath is where a downloaded release binary of the tool lives.
+func (t Toolchain) BinaryPath(name string) string {
+	return filepath.Join(t.DistDir, name+"-"+runtime.GOOS+"-"+runtime.GOARCH)
+}
+
+// Resolve returns the path of the tool to run.
+func (t Toolchain) Resolve(name string) (string, error) {
+	if path := t.BinaryPath(name); fileExists(path) {
+		return path, nil
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	if t.GoBinDir != "" {
+		if path := filepath.Join(t.GoBinDir, name); fileExists(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s, PATH, or %s: %w", name, t.DistDir, t.GoBinDir, ErrNotFound)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// GoBinDir returns where goCmd installs binaries: GOBIN, else the bin
+// directory of the first GOPATH entry, else ~/go/bin.
+func GoBinDir(goCmd string) (string, error) {
+	if bin := GoEnv(goCmd, "GOBIN"); bin != "" {
+		return filepath.Abs(bin)
+	}
+	gopath := GoEnv(goCmd, "GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.New("unable to determine GOBIN; set GOBIN or GOPATH")
+		}
+		return filepath.Join(home, "go", "bin"), nil
+	}
+	first := strings.Split(gopath, string(os.PathListSeparator))[0]
+	if first == "" {
+		return "", errors.New("GOPATH is empty; set GOBIN explicitly")
+	}
+	return filepath.Join(first, "bin"), nil
+}
+
+// GoEnv returns a go env value, or "" if goCmd cannot report it.
+func GoEnv(goCmd, key string) string {
+	out, err := exec.Command(goCmd, "env", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Porcelain output: a line-oriented format for programs that embed
+// decktool (--porcelain on examples, run, ensure and which). The format is
+// versioned and only ever extended with new record types, so parsers keep
+// working across releases. Each line is a record type followed by fields,
+// separated by tabs; a tab, newline or backslash inside a field is written
+// as \t, \n or \\. Everything meant for people (progress, tool output, git)
+// goes to stderr instead, and failures still exit non-zero.
+//
+// v1 records:
+//
+//	example   <source/name> <dir>
+//	rendered  <example> <xml path>
+//	skipped   <example> <reason>
+//	tool      <name> <path>
+//	missing   <name>
+//	repo      <name> <dir> <fetched|unfetched> <commit or ->
+//	favorite  <example>
+//	alias     <name> <example>
+
+const porcelainV1 = "v1"
+
+type porcelain struct {
+	w io.Writer
+}
+
+// addPorcelainFlag adds --porcelain, which defaults to the current format
+// when given without a version.
+func addPorcelainFlag(cmd *cobra.Command, version *string) {
+	cmd.Flags().StringVar(version, "porcelain", "", "stable tab-separated output for scripts (format v1); progress goes to stderr")
+	cmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+}
+
+// startPorcelain switches to porcelain output when version is set: the
+// records go to stdout and everything else printed from here on to stderr.
+// It returns nil for normal output.
+func startPorcelain(version string) (*porcelain, error) {
+	switch version {
+	case "":
+		return nil, nil
+	case porcelainV1:
+	default:
+		return nil, fmt.Errorf("unknown porcelain format %q (supported: %s)", version, porcelainV1)
+	}
+	p := &porcelain{w: os.Stdout}
+	os.Stdout = os.Stderr
+	return p, nil
+}
+
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`)
+
+func (p *porcelain) record(kind string, fields ...string) {
+	line := kind
+	for _, f := range fields {
+		line += "\t" + porcelainEscaper.Replace(f)
+	}
+	fmt.Fprintln(p.w, line)
+}
+
+// porcelainTool records where a tool resolves to.
+func (cfg *config) porcelainTool(p *porcelain, name string) {
+	if path, err := cfg.resolveBinary(name); err == nil {
+		p.record("tool", name, path)
+	} else {
+		p.record("missing", name)
+	}
+}
+
+// porcelainRepos records the example repos and fonts.
+func (cfg *config) porcelainRepos(ctx context.Context, p *porcelain) {
+	var names []string
+	for name, repo := range cfg.repos {
+		if repo.isData {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		repo := cfg.repos[name]
+		if !repo.cloned() {
+			p.record("repo", name, repo.dir, "unfetched", "-")
+			continue
+		}
+		sha, err := cfg.gitOutput(ctx, "-C", repo.dir, "rev-parse", "HEAD")
+		if err != nil || sha == "" {
+			sha = "-"
+		}
+		p.record("repo", name, repo.dir, "fetched", sha)
+	}
+}
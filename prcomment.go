@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PR comments: post a test run's summary on the upstream pull request,
+// updating the bot's earlier comment instead of adding a new one per run
+
+const prCommentMarker = "<!-- decktool-pr-report -->"
+
+// prTarget is an upstream pull request, <repo>#<number>.
+type prTarget struct {
+	owner, name string
+	number      int
+}
+
+// resolvePRTarget parses <repo>#<number>. Comments go to the canonical
+// upstream repo, where PRs are opened, even when the repo is a fork.
+func (cfg *config) resolvePRTarget(arg string) (*prTarget, error) {
+	name, num, ok := strings.Cut(arg, "#")
+	number, err := strconv.Atoi(num)
+	if !ok || err != nil || number <= 0 {
+		return nil, fmt.Errorf("invalid PR %q (want <repo>#<number>)", arg)
+	}
+	repo, ok := cfg.repos[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown repo %q (see decktool repos)", name)
+	}
+	remote := repo.upstream
+	if remote == "" {
+		remote = repo.url
+	}
+	owner, ghRepo, ok := githubRepo(remote)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a GitHub repo (%s)", name, remote)
+	}
+	return &prTarget{owner: owner, name: ghRepo, number: number}, nil
+}
+
+// publishPRComment creates or updates the run's summary comment on the PR.
+func (cfg *config) publishPRComment(ctx context.Context, target prTarget, summary deckTestSummary, runErr error, reportURL string) error {
+	body := cfg.prCommentBody(summary, runErr, reportURL)
+	repo := fmt.Sprintf("repos/%s/%s", target.owner, target.name)
+	id, err := findPRComment(ctx, repo, target.number)
+	if err != nil {
+		return err
+	}
+	args := []string{"api", "--silent", "-X", "POST", fmt.Sprintf("%s/issues/%d/comments", repo, target.number), "-f", "body=" + body}
+	verb := "Posted"
+	if id != "" {
+		args = []string{"api", "--silent", "-X", "PATCH", fmt.Sprintf("%s/issues/comments/%s", repo, id), "-f", "body=" + body}
+		verb = "Updated"
+	}
+	if out, err := exec.CommandContext(ctx, "gh", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("gh api: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	fmt.Printf("✓ %s PR comment on %s/%s#%d\n", verb, target.owner, target.name, target.number)
+	return nil
+}
+
+// findPRComment returns the id of an earlier comment carrying the marker.
+func findPRComment(ctx context.Context, repo string, number int) (string, error) {
+	out, err := exec.CommandContext(ctx, "gh", "api", "--paginate",
+		fmt.Sprintf("%s/issues/%d/comments", repo, number),
+		"--jq", fmt.Sprintf(".[] | select(.body | startswith(%q)) | .id", prCommentMarker)).Output()
+	if err != nil {
+		return "", fmt.Errorf("list PR comments: %w", err)
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return "", nil
+	}
+	return ids[0], nil
+}
+
+// prCommentBody renders the summary as markdown: pass rate, failing decks,
+// visual regressions (with thumbnails when the diffs are published under
+// reportURL) and render time compared with the previous toolchain.
+func (cfg *config) prCommentBody(summary deckTestSummary, runErr error, reportURL string) string {
+	var b strings.Builder
+	b.WriteString(prCommentMarker + "\n")
+	icon := "✅"
+	if runErr != nil {
+		icon = "❌"
+	}
+	b.WriteString("### " + icon + " decktool render report\n\n")
+	if summary.total == 0 && runErr != nil {
+		fmt.Fprintf(&b, "The run did not complete: `%v`\n", runErr)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "**%d/%d decks rendered** (%.1f%%)", summary.passed, summary.total, 100*float64(summary.passed)/float64(max(summary.total, 1)))
+	if summary.known > 0 {
+		fmt.Fprintf(&b, ", %d known failure(s)", summary.known)
+	}
+	fmt.Fprintf(&b, " on toolchain `%s`", summary.run.Toolchain)
+	if reportURL != "" {
+		fmt.Fprintf(&b, " · [full report](%s)", reportURL)
+	}
+	b.WriteString("\n")
+
+	if len(summary.failures) > 0 {
+		b.WriteString("\n#### Failures\n\n")
+		for _, failure := range summary.failures {
+			name, msg, _ := strings.Cut(failure, ": ")
+			fmt.Fprintf(&b, "- `%s`: %s\n", name, strings.ReplaceAll(msg, "\n", " "))
+		}
+	}
+
+	if len(summary.pending) > 0 {
+		fmt.Fprintf(&b, "\n#### Visual changes (%d slide(s))\n\n", len(summary.pending))
+		if reportURL != "" {
+			b.WriteString("| Slide | Pixels | Diff |\n|---|---:|---|\n")
+		} else {
+			b.WriteString("| Slide | Pixels |\n|---|---:|\n")
+		}
+		for _, d := range summary.pending {
+			pixels := strconv.Itoa(d.Pixels)
+			if d.Pixels < 0 {
+				pixels = "size changed"
+			}
+			fmt.Fprintf(&b, "| `%s` %s | %s |", d.Deck, d.Slide, pixels)
+			if reportURL != "" {
+				fmt.Fprintf(&b, " <img src=%q width=\"200\"> |", cfg.reportAssetURL(reportURL, d.Diff))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if summary.previous != nil {
+		b.WriteString(renderTimeDeltas(summary.run, *summary.previous))
+	}
+	return b.String()
+}
+
+// reportAssetURL maps a file under the test directory to its URL under the
+// published report.
+func (cfg *config) reportAssetURL(reportURL, path string) string {
+	rel, err := filepath.Rel(cfg.testDir, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	return strings.TrimSuffix(reportURL, "/") + "/" + filepath.ToSlash(rel)
+}
+
+// renderTimeDeltas compares total render time with the previous run and
+// lists the decks that slowed down the most.
+func renderTimeDeltas(run, previous metricsRun) string {
+	type delta struct {
+		deck        string
+		before, now time.Duration
+	}
+	var deltas []delta
+	var before, now time.Duration
+	for deck, m := range run.Decks {
+		prev, ok := previous.Decks[deck]
+		if !ok || m.RenderMS == 0 || prev.RenderMS == 0 {
+			continue
+		}
+		d := delta{deck, time.Duration(prev.RenderMS) * time.Millisecond, time.Duration(m.RenderMS) * time.Millisecond}
+		before += d.before
+		now += d.now
+		deltas = append(deltas, d)
+	}
+	if len(deltas) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n#### Render time vs `%s`\n\n", previous.Toolchain)
+	fmt.Fprintf(&b, "Total %s → %s (%s)\n", before.Round(time.Millisecond), now.Round(time.Millisecond), percentChange(before, now))
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].now-deltas[i].before > deltas[j].now-deltas[j].before
+	})
+	var rows []string
+	for _, d := range deltas[:min(len(deltas), 5)] {
+		if d.now <= d.before {
+			break
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | %s | %s | %s |", d.deck, d.before, d.now, percentChange(d.before, d.now)))
+	}
+	if len(rows) > 0 {
+		b.WriteString("\n| Slowest changes | Before | After | |\n|---|---:|---:|---:|\n")
+		b.WriteString(strings.Join(rows, "\n") + "\n")
+	}
+	return b.String()
+}
+
+func percentChange(before, now time.Duration) string {
+	if before == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", 100*float64(now-before)/float64(before))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// CGO preflight checks for UI viewers (ebdeck, gcdeck)
+
+// pkgNames maps pkg-config modules to the dev package providing them, per package manager.
+var pkgNames = map[string]map[string]string{
+	"apt-get": {
+		"x11": "libx11-dev", "xcursor": "libxcursor-dev", "xrandr": "libxrandr-dev",
+		"xinerama": "libxinerama-dev", "xi": "libxi-dev", "xxf86vm": "libxxf86vm-dev",
+		"gl": "libgl1-mesa-dev", "egl": "libegl1-mesa-dev", "vulkan": "libvulkan-dev",
+		"wayland-client": "libwayland-dev", "wayland-egl": "libwayland-dev", "wayland-cursor": "libwayland-dev",
+		"xkbcommon": "libxkbcommon-dev", "xkbcommon-x11": "libxkbcommon-x11-dev",
+		"x11-xcb": "libx11-xcb-dev", "xfixes": "libxfixes-dev",
+	},
+	"dnf": {
+		"x11": "libX11-devel", "xcursor": "libXcursor-devel", "xrandr": "libXrandr-devel",
+		"xinerama": "libXinerama-devel", "xi": "libXi-devel", "xxf86vm": "libXxf86vm-devel",
+		"gl": "mesa-libGL-devel", "egl": "mesa-libEGL-devel", "vulkan": "vulkan-loader-devel",
+		"wayland-client": "wayland-devel", "wayland-egl": "wayland-devel", "wayland-cursor": "wayland-devel",
+		"xkbcommon": "libxkbcommon-devel", "xkbcommon-x11": "libxkbcommon-x11-devel",
+		"x11-xcb": "libX11-devel", "xfixes": "libXfixes-devel",
+	},
+	"pacman": {
+		"x11": "libx11", "xcursor": "libxcursor", "xrandr": "libxrandr",
+		"xinerama": "libxinerama", "xi": "libxi", "xxf86vm": "libxxf86vm",
+		"gl": "mesa", "egl": "mesa", "vulkan": "vulkan-headers",
+		"wayland-client": "wayland", "wayland-egl": "wayland", "wayland-cursor": "wayland",
+		"xkbcommon": "libxkbcommon", "xkbcommon-x11": "libxkbcommon-x11",
+		"x11-xcb": "libx11", "xfixes": "libxfixes",
+	},
+}
+
+var installCommands = map[string]string{
+	"apt-get": "sudo apt-get install -y",
+	"dnf":     "sudo dnf install -y",
+	"pacman":  "sudo pacman -S --needed",
+}
+
+// checkUIDeps verifies the system libraries a UI viewer links against are
+// present, printing the install command instead of letting cgo fail noisily.
+func (cfg *config) checkUIDeps(ctx context.Context, spec binSpec) error {
+	switch runtime.GOOS {
+	case "linux":
+		return checkLinuxDeps(ctx, spec)
+	case "darwin":
+		if err := exec.CommandContext(ctx, "xcode-select", "-p").Run(); err != nil {
+			fmt.Printf("⚠ %s needs the Xcode command line tools. Install with:\n    xcode-select --install\n", spec.name)
+			return fmt.Errorf("missing system dependencies: Xcode command line tools")
+		}
+	}
+	return nil
+}
+
+func checkLinuxDeps(ctx context.Context, spec binSpec) error {
+	var missing []string
+	if _, err := exec.LookPath("cc"); err != nil {
+		missing = append(missing, "cc")
+	}
+	if _, err := exec.LookPath("pkg-config"); err != nil {
+		// Without pkg-config nothing can be checked; assume every module is needed
+		missing = append(missing, "pkg-config")
+		missing = append(missing, spec.uiDeps...)
+	} else {
+		for _, mod := range spec.uiDeps {
+			if err := exec.CommandContext(ctx, "pkg-config", "--exists", mod).Run(); err != nil {
+				missing = append(missing, mod)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	fmt.Printf("⚠ %s is missing system dependencies: %s\n", spec.name, strings.Join(missing, ", "))
+	if pm := detectPackageManager(); pm != "" {
+		fmt.Printf("Install with:\n    %s %s\n", installCommands[pm], strings.Join(linuxPackages(pm, missing), " "))
+	} else {
+		fmt.Println("Install the development packages providing these pkg-config modules with your package manager.")
+	}
+	return fmt.Errorf("missing system dependencies: %s", strings.Join(missing, ", "))
+}
+
+func detectPackageManager() string {
+	for _, pm := range []string{"apt-get", "dnf", "pacman"} {
+		if _, err := exec.LookPath(pm); err == nil {
+			return pm
+		}
+	}
+	return ""
+}
+
+func linuxPackages(pm string, missing []string) []string {
+	seen := make(map[string]struct{})
+	for _, mod := range missing {
+		pkg := mod
+		switch mod {
+		case "cc":
+			pkg = map[string]string{"apt-get": "build-essential", "dnf": "gcc", "pacman": "base-devel"}[pm]
+		case "pkg-config":
+			pkg = map[string]string{"apt-get": "pkg-config", "dnf": "pkgconf-pkg-config", "pacman": "pkgconf"}[pm]
+		default:
+			if name, ok := pkgNames[pm][mod]; ok {
+				pkg = name
+			}
+		}
+		seen[pkg] = struct{}{}
+	}
+	var pkgs []string
+	for pkg := range seen {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// SLSA provenance for release artifacts
+//
+// dev release uploads an in-toto statement (provenanceFile) describing who
+// built the binaries, from which source SHAs and with which parameters;
+// ensure and release verify check downloaded binaries against its digests.
+
+const (
+	provenanceFile      = "provenance.intoto.jsonl"
+	provenanceBuildType = "https://github.com/joeblew999/deck-test/dev-release@v1"
+)
+
+type provenanceStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []provenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     provenancePredicate `json:"predicate"`
+}
+
+type provenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenancePredicate struct {
+	BuildDefinition struct {
+		BuildType            string               `json:"buildType"`
+		ExternalParameters   map[string]any       `json:"externalParameters"`
+		InternalParameters   map[string]any       `json:"internalParameters"`
+		ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Metadata struct {
+			StartedOn  time.Time `json:"startedOn"`
+			FinishedOn time.Time `json:"finishedOn"`
+		} `json:"metadata"`
+	} `json:"runDetails"`
+}
+
+type resourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// builderID identifies who ran the build: the CI run when in GitHub
+// Actions, otherwise the local user and host.
+func builderID() string {
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+	}
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("local:%s@%s", name, host)
+}
+
+// sourceDependencies lists the repos a release was built from, preferring
+// SHAs pinned in the lockfile and falling back to the checked-out HEAD.
+func (cfg *config) sourceDependencies(ctx context.Context) ([]resourceDescriptor, error) {
+	lock, err := loadLockFile()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range cfg.repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var deps []resourceDescriptor
+	for _, name := range names {
+		repo := cfg.repos[name]
+		sha := lock.Repos[name].SHA
+		if sha == "" {
+			if sha, err = cfg.gitHead(ctx, repo); err != nil {
+				continue // not checked out; not part of this build
+			}
+		}
+		deps = append(deps, resourceDescriptor{
+			URI:    fmt.Sprintf("git+%s@%s", repo.url, repo.branch),
+			Digest: map[string]string{"gitCommit": sha},
+		})
+	}
+	if sha, err := cfg.gitOutput(ctx, "rev-parse", "HEAD"); err == nil {
+		deps = append(deps, resourceDescriptor{URI: "git+decktool", Digest: map[string]string{"gitCommit": sha}})
+	}
+	return deps, nil
+}
+
+// writeProvenance records a provenance statement for artifacts and returns its path.
+func (cfg *config) writeProvenance(ctx context.Context, version string, artifacts []string, started time.Time) (string, error) {
+	st := provenanceStatement{
+		Type:          "https://in-toto.io/Statement/v1",
+		PredicateType: "https://slsa.dev/provenance/v1",
+	}
+	for _, path := range artifacts {
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return "", err
+		}
+		st.Subject = append(st.Subject, provenanceSubject{Name: filepath.Base(path), Digest: map[string]string{"sha256": sum}})
+	}
+
+	// The Go the build used: the "go" section may pin another toolchain
+	goVersion := ""
+	if values, err := cfg.goEnvValues(ctx, "GOVERSION"); err == nil {
+		goVersion = values[0]
+	}
+	def := &st.Predicate.BuildDefinition
+	def.BuildType = provenanceBuildType
+	def.ExternalParameters = map[string]any{
+		"version": version,
+		"targets": []buildTarget{targetNative, targetWASM, targetWASI},
+		"flags":   cfg.file.Build.flags(),
+	}
+	def.InternalParameters = map[string]any{
+		"go":       goVersion,
+		"hostOS":   runtime.GOOS,
+		"hostArch": runtime.GOARCH,
+	}
+	deps, err := cfg.sourceDependencies(ctx)
+	if err != nil {
+		return "", err
+	}
+	def.ResolvedDependencies = deps
+
+	run := &st.Predicate.RunDetails
+	run.Builder.ID = builderID()
+	run.Metadata.StartedOn = started.UTC()
+	run.Metadata.FinishedOn = time.Now().UTC()
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	path := cfg.getProvenancePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Checking binaries and releases against their provenance
+
+func loadProvenance(path string) (*provenanceStatement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st provenanceStatement
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse provenance: %w", err)
+	}
+	if st.Predicate.BuildDefinition.BuildType != provenanceBuildType {
+		return nil, fmt.Errorf("unexpected provenance build type %q", st.Predicate.BuildDefinition.BuildType)
+	}
+	return &st, nil
+}
+
+// verifyArtifact checks path against the digest recorded for its name.
+func (st *provenanceStatement) verifyArtifact(name, path string) error {
+	for _, subject := range st.Subject {
+		if subject.Name != name {
+			continue
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		if sum != subject.Digest["sha256"] {
+			return fmt.Errorf("%s: sha256 %s does not match provenance %s", name, shortSHA(sum), shortSHA(subject.Digest["sha256"]))
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not covered by the release provenance", name)
+}
+
+func (st *provenanceStatement) printSummary() {
+	fmt.Printf("Builder: %s\n", st.Predicate.RunDetails.Builder.ID)
+	fmt.Printf("Built:   %s\n", st.Predicate.RunDetails.Metadata.FinishedOn.Local().Format(time.DateTime))
+	for _, dep := range st.Predicate.BuildDefinition.ResolvedDependencies {
+		fmt.Printf("Source:  %s %s\n", dep.URI, shortSHA(dep.Digest["gitCommit"]))
+	}
+}
+
+// downloadProvenance fetches the provenance of tag into dir. A release
+// published without one yields os.ErrNotExist.
+func downloadProvenance(ctx context.Context, tag, dir string) (*provenanceStatement, error) {
+	os.Remove(filepath.Join(dir, signatureFile)) // never check a stale signature
+	cmd := exec.CommandContext(ctx, "gh", "release", "download", tag, "-p", provenanceFile, "-p", signatureFile, "-D", dir, "--clobber")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if strings.Contains(string(out), "no assets match") {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("download provenance: %s", strings.TrimSpace(string(out)))
+	}
+	return loadProvenance(filepath.Join(dir, provenanceFile))
+}
+
+// verifyRelease downloads the assets of tag and checks each one the
+// provenance names. Other attachments (signature, CLI surface, reports,
+// NOTICES) are not build outputs and are only listed.
+func (cfg *config) verifyRelease(ctx context.Context, tag string) error {
+	tmp, err := os.MkdirTemp("", "decktool-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	st, err := downloadProvenance(ctx, tag, tmp)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("release %s has no %s", tag, provenanceFile)
+	}
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkReleaseSigner(ctx, tag, tmp); err != nil {
+		return err
+	}
+	st.printSummary()
+
+	fmt.Printf("Downloading assets of %s...\n", tag)
+	download := exec.CommandContext(ctx, "gh", "release", "download", tag, "-D", tmp, "--skip-existing")
+	download.Stderr = os.Stderr
+	if err := download.Run(); err != nil {
+		return fmt.Errorf("download release: %w", err)
+	}
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		return err
+	}
+	var failed int
+	subjects := make(map[string]bool)
+	for _, subject := range st.Subject {
+		subjects[subject.Name] = true
+		path := filepath.Join(tmp, subject.Name)
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf("✗ %s: named in the provenance but not attached to the release\n", subject.Name)
+			failed++
+			continue
+		}
+		if err := st.verifyArtifact(subject.Name, path); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s\n", subject.Name)
+	}
+	for _, entry := range entries {
+		if !subjects[entry.Name()] && entry.Name() != provenanceFile {
+			fmt.Printf("⊘ %s: attachment, not a build output\n", entry.Name())
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d assets failed provenance verification", failed)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Minimal QR code encoder (byte mode, error correction level M, versions
+// 1-10, up to 213 bytes), enough for deck and gallery URLs without pulling
+// in a dependency. Follows ISO/IEC 18004: codewords in qrencode.go, module
+// placement in qrmatrix.go, masking in qrmask.go.
+
+type qrVersion struct {
+	ecPerBlock int
+	groups     [2][2]int // {blocks, data codewords per block}
+	alignment  []int
+}
+
+var qrVersions = []qrVersion{
+	1:  {10, [2][2]int{{1, 16}}, nil},
+	2:  {16, [2][2]int{{1, 28}}, []int{6, 18}},
+	3:  {26, [2][2]int{{1, 44}}, []int{6, 22}},
+	4:  {18, [2][2]int{{2, 32}}, []int{6, 26}},
+	5:  {24, [2][2]int{{2, 43}}, []int{6, 30}},
+	6:  {16, [2][2]int{{4, 27}}, []int{6, 34}},
+	7:  {18, [2][2]int{{4, 31}}, []int{6, 22, 38}},
+	8:  {22, [2][2]int{{2, 38}, {2, 39}}, []int{6, 24, 42}},
+	9:  {22, [2][2]int{{3, 36}, {2, 37}}, []int{6, 26, 46}},
+	10: {26, [2][2]int{{4, 43}, {1, 44}}, []int{6, 28, 50}},
+}
+
+func (v qrVersion) dataCodewords() int {
+	return v.groups[0][0]*v.groups[0][1] + v.groups[1][0]*v.groups[1][1]
+}
+
+// qrCode is the module matrix; true is dark.
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// encodeQR encodes data, choosing the smallest version and the mask with
+// the lowest penalty.
+func encodeQR(data []byte) (*qrCode, error) {
+	for ver := 1; ver < len(qrVersions); ver++ {
+		countBits := 8
+		if ver >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*qrVersions[ver].dataCodewords() {
+			return newQR(ver, data, countBits, -1), nil
+		}
+	}
+	return nil, errors.New("too long for a QR code")
+}
+
+// newQR builds the symbol; mask -1 picks the best mask.
+func newQR(ver int, data []byte, countBits, mask int) *qrCode {
+	v := qrVersions[ver]
+	size := 17 + 4*ver
+	q := &qrCode{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for i := range size {
+		q.modules[i] = make([]bool, size)
+		q.function[i] = make([]bool, size)
+	}
+	q.drawFunctionPatterns(ver, v)
+	q.drawCodewords(interleave(v, qrDataCodewords(v, data, countBits)))
+
+	if mask < 0 {
+		best := -1
+		for m := range 8 {
+			q.applyMask(m)
+			q.drawFormatBits(m)
+			if p := q.penalty(); best < 0 || p < best {
+				best, mask = p, m
+			}
+			q.applyMask(m) // undo
+		}
+	}
+	q.applyMask(mask)
+	q.drawFormatBits(mask)
+	return q
+}
+
+// writePNG draws the symbol with scale pixels per module and the standard
+// four-module quiet zone.
+func (q *qrCode) writePNG(w io.Writer, scale int) error {
+	dim := (q.size + 8) * scale
+	img := image.NewGray(image.Rect(0, 0, dim, dim))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for y := range q.size {
+		for x := range q.size {
+			if !q.modules[y][x] {
+				continue
+			}
+			for dy := range scale {
+				for dx := range scale {
+					img.SetGray((x+4)*scale+dx, (y+4)*scale+dy, color.Gray{})
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,103 @@
+package main
+
+// QR data encoding: byte-mode codewords and Reed-Solomon error correction
+
+// qrDataCodewords encodes data in byte mode and pads to capacity.
+func qrDataCodewords(v qrVersion, data []byte, countBits int) []byte {
+	var bits []bool
+	put := func(val, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, (val>>i)&1 != 0)
+		}
+	}
+	capacity := 8 * v.dataCodewords()
+	put(0b0100, 4)
+	put(len(data), countBits)
+	for _, b := range data {
+		put(int(b), 8)
+	}
+	put(0, min(4, capacity-len(bits)))
+	put(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		put(pad, 8)
+	}
+	out := make([]byte, len(bits)/8)
+	for i, b := range bits {
+		if b {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+// interleave splits data into blocks, appends error correction and
+// interleaves the codewords.
+func interleave(v qrVersion, data []byte) []byte {
+	divisor := rsDivisor(v.ecPerBlock)
+	var blocks, ecc [][]byte
+	for _, g := range v.groups {
+		for range g[0] {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			blocks = append(blocks, block)
+			ecc = append(ecc, rsRemainder(block, divisor))
+		}
+	}
+	var out []byte
+	for i := 0; ; i++ {
+		done := true
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+	}
+	for i := range v.ecPerBlock {
+		for _, e := range ecc {
+			out = append(out, e[i])
+		}
+	}
+	return out
+}
+
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMul(divisor[i], factor)
+		}
+	}
+	return result
+}
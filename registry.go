@@ -0,0 +1,146 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Example registry: deck directories anywhere on disk registered under a
+// name, so run, view and completion address their decks as <name>/<deck>
+// from any project next to the built-in deckviz and dubois repos. The
+// registry is per user (~/.decktool/registry.json, or DECKTOOL_REGISTRY).
+
+// corpus is one registered deck directory; each subdirectory is a deck.
+type corpus struct {
+	Name  string    `json:"name"`
+	Dir   string    `json:"dir"`
+	Added time.Time `json:"added"`
+}
+
+var corpusNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+func registryPath() (string, error) {
+	if path := os.Getenv("DECKTOOL_REGISTRY"); path != "" {
+		return path, nil
+	}
+	return decktoolHomePath("registry.json")
+}
+
+// loadRegistry returns the registered corpora sorted by name; a missing
+// registry is empty.
+func loadRegistry() ([]corpus, error) {
+	path, err := registryPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var corpora []corpus
+	if err := json.Unmarshal(data, &corpora); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	slices.SortFunc(corpora, func(a, b corpus) int { return cmp.Compare(a.Name, b.Name) })
+	return corpora, nil
+}
+
+func saveRegistry(corpora []corpus) error {
+	path, err := registryPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(corpora, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// registeredCorpus looks up a corpus by name.
+func registeredCorpus(name string) (corpus, bool) {
+	corpora, err := loadRegistry()
+	if err != nil {
+		return corpus{}, false
+	}
+	for _, c := range corpora {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return corpus{}, false
+}
+
+// registerCorpus adds dir under name, defaulting to the directory's base
+// name. Names must not shadow a built-in repo.
+func (cfg *config) registerCorpus(dir, name string) (corpus, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return corpus{}, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return corpus{}, err
+	}
+	if !info.IsDir() {
+		return corpus{}, fmt.Errorf("%s is not a directory", dir)
+	}
+	if name == "" {
+		name = filepath.Base(abs)
+	}
+	if !corpusNameRE.MatchString(name) {
+		return corpus{}, fmt.Errorf("corpus name %q must be lower case letters, digits, '.', '_' or '-' (set one with --name)", name)
+	}
+	if _, ok := cfg.repos[name]; ok {
+		return corpus{}, fmt.Errorf("%q is a built-in repo; pick another name with --name", name)
+	}
+	corpora, err := loadRegistry()
+	if err != nil {
+		return corpus{}, err
+	}
+	for _, c := range corpora {
+		if c.Name == name {
+			return corpus{}, fmt.Errorf("%q is already registered for %s", name, c.Dir)
+		}
+	}
+	c := corpus{Name: name, Dir: abs, Added: time.Now().UTC()}
+	return c, saveRegistry(append(corpora, c))
+}
+
+// unregisterCorpus removes a corpus from the registry; its files stay.
+func unregisterCorpus(name string) error {
+	corpora, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(corpora, func(c corpus) bool { return c.Name == name })
+	if i < 0 {
+		return fmt.Errorf("%q is not registered", name)
+	}
+	return saveRegistry(slices.Delete(corpora, i, i+1))
+}
+
+// deckNames lists the decks of a corpus: subdirectories holding a
+// <dir>/<dir>.dsh script.
+func (c corpus) deckNames() []string {
+	var names []string
+	for _, name := range collectExampleNames(c.Dir) {
+		if _, err := os.Stat(filepath.Join(c.Dir, name, name+".dsh")); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
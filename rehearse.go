@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rehearsal estimates: how long a deck takes to present, from the words on
+// each slide read at a speaking rate, plus a pause for each visual.
+
+// visualPause is the time added per image, chart or other non-text element
+// an audience needs to take in.
+const visualPause = 5 * time.Second
+
+type slideTiming struct {
+	Slide    int
+	Words    int
+	Visuals  int
+	Duration time.Duration
+}
+
+// estimateTimings times every slide of a rendered deck at wpm words per
+// minute.
+func estimateTimings(xmlPath string, wpm int) ([]slideTiming, error) {
+	deck, err := parseStyledDeck(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	timings := make([]slideTiming, len(deck.slides))
+	for i, slide := range deck.slides {
+		t := slideTiming{Slide: i + 1}
+		for _, el := range slide {
+			switch el.tag {
+			case "text", "list":
+				t.Words += len(strings.Fields(el.text))
+			case "image", "chart":
+				t.Visuals++
+			}
+		}
+		t.Duration = time.Duration(t.Words)*time.Minute/time.Duration(wpm) + time.Duration(t.Visuals)*visualPause
+		timings[i] = t
+	}
+	return timings, nil
+}
+
+// printTimings prints the per-slide breakdown, flagging slides over the
+// time box and a total over the talk length (either 0 to skip).
+func printTimings(example string, timings []slideTiming, wpm int, timeBox, length time.Duration) {
+	fmt.Printf("=== Rehearsal for %s (%d wpm) ===\n", example, wpm)
+	fmt.Printf("  %5s %6s %7s %6s\n", "slide", "words", "visuals", "time")
+	var total time.Duration
+	var words, over int
+	for _, t := range timings {
+		total += t.Duration
+		words += t.Words
+		note := ""
+		if timeBox > 0 && t.Duration > timeBox {
+			note = "  ⚠ over " + clock(timeBox)
+			over++
+		}
+		fmt.Printf("  %5d %6d %7d %6s%s\n", t.Slide, t.Words, t.Visuals, clock(t.Duration), note)
+	}
+	fmt.Printf("\nTotal: %d slide(s), %d words, ~%s\n", len(timings), words, clock(total))
+	if over > 0 {
+		fmt.Printf("⚠ %d slide(s) likely to exceed %s\n", over, clock(timeBox))
+	}
+	if length > 0 && total > length {
+		fmt.Printf("⚠ Estimated %s exceeds the %s talk length by %s\n", clock(total), clock(length), clock(total-length))
+	}
+}
+
+// clock formats a duration as m:ss.
+func clock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -13,7 +13,7 @@ import (
 
 // Release operations
 
-func (cfg *config) createGithubRelease(ctx context.Context, version string, prerelease bool) error {
+func (cfg *config) createGithubRelease(ctx context.Context, version string, prerelease bool, started time.Time) error {
 	// Ensure gh CLI is installed
 	if err := cfg.ensureGhCli(ctx); err != nil {
 		return err
@@ -33,12 +33,43 @@ func (cfg *config) createGithubRelease(ctx context.Context, version string, prer
 	}
 
 	// Find all binaries in dist directory
-	binaries, err := filepath.Glob(cfg.getDistGlob())
+	binaries, err := cfg.listDistArtifacts()
 	if err != nil {
-		return fmt.Errorf("failed to glob binaries: %w", err)
+		return err
 	}
 	if len(binaries) == 0 {
-		return fmt.Errorf("no binaries found in %s (run dev-build first)", cfg.distDir)
+		return fmt.Errorf("no binaries found in %s (run dev build first)", cfg.distDir)
+	}
+	if err := cfg.checkReleaseCompleteness(binaries); err != nil {
+		return fmt.Errorf("release blocked: %w", err)
+	}
+	if err := cfg.checkSizeBudgets(binaries); err != nil {
+		return fmt.Errorf("release blocked: %w", err)
+	}
+	notices, err := cfg.checkLicenses(ctx, false)
+	if err != nil {
+		return fmt.Errorf("release blocked: %w", err)
+	}
+	provenance, err := cfg.writeProvenance(ctx, version, binaries, started)
+	if err != nil {
+		return fmt.Errorf("write provenance: %w", err)
+	}
+	signature, err := signProvenance(provenance)
+	if err != nil {
+		return fmt.Errorf("sign provenance: %w", err)
+	}
+	surface := cfg.captureCLISurface(ctx)
+	surfacePath, err := cfg.writeCLISurface(surface)
+	if err != nil {
+		return fmt.Errorf("write CLI surface: %w", err)
+	}
+	notes := fmt.Sprintf("Release %s\n\nBuilt with decktool", version) + cliChangesSection(ctx, surface)
+	attachments := []string{provenance, signature, surfacePath, notices}
+	if report, reportPath, err := cfg.auditToolchain(ctx); err != nil {
+		fmt.Printf("⚠ Vulnerability report not attached: %v\n", err)
+	} else {
+		notes += vulnNotesSection(report)
+		attachments = append(attachments, reportPath)
 	}
 
 	// Create release
@@ -48,8 +79,9 @@ func (cfg *config) createGithubRelease(ctx context.Context, version string, prer
 		releaseArgs = append(releaseArgs, "--prerelease")
 	}
 	releaseArgs = append(releaseArgs, "--title", version)
-	releaseArgs = append(releaseArgs, "--notes", fmt.Sprintf("Release %s\n\nBuilt with decktool", version))
+	releaseArgs = append(releaseArgs, "--notes", notes)
 	releaseArgs = append(releaseArgs, binaries...)
+	releaseArgs = append(releaseArgs, attachments...)
 
 	releaseCmd := exec.CommandContext(ctx, "gh", releaseArgs...)
 	releaseCmd.Stdout = os.Stdout
@@ -58,10 +90,25 @@ func (cfg *config) createGithubRelease(ctx context.Context, version string, prer
 		return fmt.Errorf("release creation failed: %w", err)
 	}
 
-	fmt.Printf("✓ Release %s created with %d binaries\n", version, len(binaries))
+	fmt.Printf("✓ Release %s created with %d binaries and %s\n", version, len(binaries), provenanceFile)
 	return nil
 }
 
+// listDistArtifacts returns the release files in dist, skipping subdirectories such as build logs.
+func (cfg *config) listDistArtifacts() ([]string, error) {
+	matches, err := filepath.Glob(cfg.getDistGlob())
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob binaries: %w", err)
+	}
+	var artifacts []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+			artifacts = append(artifacts, match)
+		}
+	}
+	return artifacts, nil
+}
+
 func (cfg *config) generateReleaseVersion() string {
 	return fmt.Sprintf("dev-%s", time.Now().Format("20060102-150405"))
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Installing release assets into dist: each download goes through the
+// shared cache when DECKTOOL_CACHE is set, and is checked against the
+// release provenance before it is used or cached.
+
+// releaseProvenance downloads the provenance of tag and checks its signer.
+// A release without provenance yields nil, unless its repo has a pinned
+// signer.
+func (cfg *config) releaseProvenance(ctx context.Context, tag string) (*provenanceStatement, error) {
+	stdout, _ := toolOutput(ctx)
+	dir := filepath.Dir(cfg.getProvenancePath())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	provenance, err := downloadProvenance(ctx, tag, dir)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := refuseUnpinnable(ctx, tag, "provenance"); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(stdout, "⚠ Downloads of %s cannot be verified\n", tag)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return provenance, cfg.checkReleaseSigner(ctx, tag, dir)
+}
+
+// installReleaseAsset downloads filename of release tag into dist. A
+// download that does not match provenance is removed and returned as
+// verifyErr; err reports everything else.
+func (cfg *config) installReleaseAsset(ctx context.Context, tag, filename string, provenance *provenanceStatement) (verifyErr, err error) {
+	stdout, stderr := toolOutput(ctx)
+	destPath := filepath.Join(cfg.distDir, filename)
+	download := func(dir string) error {
+		fmt.Fprintf(stdout, "Downloading %s...\n", filename)
+		downloadCmd := exec.CommandContext(ctx, "gh", "release", "download", tag, "-p", filename, "-D", dir, "--clobber")
+		downloadCmd.Stdout = stdout
+		downloadCmd.Stderr = stderr
+		return downloadCmd.Run()
+	}
+	// Checked before a download enters the shared cache, so a file that
+	// fails provenance is never reused by later runs
+	verify := func(path string) error {
+		if provenance != nil {
+			verifyErr = provenance.verifyArtifact(filename, path)
+		}
+		return verifyErr
+	}
+	if cfg.cacheDir != "" {
+		err = cfg.cachedRelease(ctx, tag, filename, destPath, download, verify)
+	} else if err = download(cfg.distDir); err == nil {
+		err = verify(destPath)
+	}
+	if verifyErr != nil {
+		os.Remove(destPath)
+		return verifyErr, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Make executable (binaries linked from the shared cache already are)
+	if cfg.cacheDir == "" {
+		if err := os.Chmod(destPath, 0755); err != nil {
+			fmt.Fprintf(stdout, "⚠ Failed to chmod %s: %v\n", filename, err)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Remote builds on a builder host over ssh
+//
+// The workspace (decktool and the code repos in srcDir, without .git
+// directories or outputs) is streamed to the builder as a tarball, dev build
+// runs there with --no-sync, and the artifacts and build logs in its dist
+// directory are streamed back. The builder needs sh, tar, git and Go.
+
+// remoteMarker marks a builder directory as a decktool workspace, which
+// pushWorkspace may clear; other non-empty directories are refused.
+const remoteMarker = ".decktool-remote"
+
+type remoteBuilder struct {
+	host string // [user@]host for ssh
+	port string
+	dir  string // workspace on the builder, relative to its home unless absolute
+}
+
+// parseRemote parses ssh://[user@]host[:port][/dir]. The default dir is
+// decktool-build/<name of the local workspace>.
+func parseRemote(raw string) (*remoteBuilder, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "ssh" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid remote %q (want ssh://[user@]host[:port][/dir])", raw)
+	}
+	rb := &remoteBuilder{host: u.Hostname(), port: u.Port(), dir: strings.TrimPrefix(u.Path, "/")}
+	if u.User != nil {
+		rb.host = u.User.Username() + "@" + rb.host
+	}
+	if strings.HasPrefix(u.Path, "//") { // ssh://host//srv/build is absolute
+		rb.dir = u.Path[1:]
+	}
+	if rb.dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		rb.dir = path.Join("decktool-build", filepath.Base(wd))
+	}
+	return rb, nil
+}
+
+func (rb *remoteBuilder) String() string {
+	return fmt.Sprintf("%s:%s", rb.host, rb.dir)
+}
+
+// command runs script with sh on the builder.
+func (rb *remoteBuilder) command(ctx context.Context, script string) *exec.Cmd {
+	args := []string{"-o", "BatchMode=yes"}
+	if rb.port != "" {
+		args = append(args, "-p", rb.port)
+	}
+	// A login shell picks up the builder's PATH (Go is often in ~/.profile)
+	args = append(args, rb.host, "sh -lc "+shellQuote(script))
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// remoteBuild runs dev build on the builder. With existing, the clone
+// already on the builder is built as is (its repos synced there);
+// otherwise the local workspace is pushed first and built without syncing.
+func (cfg *config) remoteBuild(ctx context.Context, raw string, existing bool, flags []string) error {
+	rb, err := parseRemote(raw)
+	if err != nil {
+		return err
+	}
+	args := flags
+	if !existing {
+		fmt.Printf("Syncing workspace to %s...\n", rb)
+		if err := cfg.pushWorkspace(ctx, rb); err != nil {
+			return err
+		}
+		args = append(args, "--no-sync")
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	fmt.Printf("Building on %s...\n", rb)
+	cmd := rb.command(ctx, fmt.Sprintf("cd %s && go run . dev build %s", shellQuote(rb.dir), strings.Join(quoted, " ")))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	buildErr := cmd.Run()
+
+	// Pull back whatever was built, also after partial failures
+	fmt.Printf("Fetching artifacts from %s...\n", rb)
+	artifacts, err := cfg.pullArtifacts(ctx, rb)
+	if err != nil {
+		return fmt.Errorf("fetch artifacts: %w", err)
+	}
+	fmt.Printf("✓ Fetched %d artifacts into %s\n", len(artifacts), relToCwd(cfg.distDir))
+	if buildErr != nil {
+		return fmt.Errorf("remote build on %s: %w", rb.host, buildErr)
+	}
+	return nil
+}
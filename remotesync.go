@@ -0,0 +1,171 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Moving files to and from a builder: the workspace out, artifacts back
+
+// remoteSkipDirs are local state the builder does not need.
+func (cfg *config) remoteSkipDirs() map[string]bool {
+	skip := map[string]bool{".git": true, ".worktrees": true, dataDir: true}
+	for _, dir := range []string{cfg.distDir, cfg.fontsDir, cfg.testDir, cfg.jobsDir, cfg.archiveDir} {
+		skip[filepath.Base(dir)] = true
+	}
+	return skip
+}
+
+// pushWorkspace streams the workspace to the builder, replacing what was
+// there except its dist directory.
+func (cfg *config) pushWorkspace(ctx context.Context, rb *remoteBuilder) error {
+	dir := shellQuote(rb.dir)
+	cmd := rb.command(ctx, fmt.Sprintf(`mkdir -p %[1]s && cd %[1]s &&
+if [ -n "$(ls -A)" ] && [ ! -f %[2]s ]; then echo "%[1]s is not empty and not a decktool workspace" >&2; exit 3; fi &&
+find . -mindepth 1 -maxdepth 1 ! -name %[3]s ! -name %[2]s -exec rm -rf {} + && touch %[2]s && tar -xzf -`,
+		dir, remoteMarker, shellQuote(filepath.Base(cfg.distDir))))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	files, size, writeErr := cfg.writeWorkspaceTar(stdin)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ssh %s: %w", rb.host, err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	fmt.Printf("✓ Synced %d files (%s) to %s\n", files, formatSize(size), rb)
+	return nil
+}
+
+// writeWorkspaceTar writes the working directory as a tar.gz to w.
+func (cfg *config) writeWorkspaceTar(w io.Writer) (files int, size int64, err error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	skip := cfg.remoteSkipDirs()
+	err = filepath.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if skip[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil // sockets, symlinks into local paths
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(p)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		n, err := io.Copy(tw, f)
+		files++
+		size += n
+		return err
+	})
+	if err != nil {
+		return files, size, err
+	}
+	if err := tw.Close(); err != nil {
+		return files, size, err
+	}
+	return files, size, gz.Close()
+}
+
+// pullArtifacts streams the builder's dist artifacts and build logs into
+// the local dist directory and returns the artifact names.
+func (cfg *config) pullArtifacts(ctx context.Context, rb *remoteBuilder) ([]string, error) {
+	dist := shellQuote(path.Join(rb.dir, filepath.Base(cfg.distDir)))
+	cmd := rb.command(ctx, fmt.Sprintf(
+		"cd %s && { find . -maxdepth 1 -type f; find logs -type f 2>/dev/null; } | tar -czf - -T -", dist))
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	artifacts, readErr := cfg.extractDistTar(stdout)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", rb.host, err)
+	}
+	return artifacts, readErr
+}
+
+// extractDistTar unpacks artifacts (top level) and logs/ into dist.
+func (cfg *config) extractDistTar(r io.Reader) ([]string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	var artifacts []string
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return artifacts, nil
+		}
+		if err != nil {
+			return artifacts, err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		dir, file := path.Split(name)
+		if (dir != "" && dir != "logs/") || file == "" || strings.HasPrefix(file, ".") {
+			continue // only artifacts and build logs come back
+		}
+		dest := filepath.Join(cfg.distDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return artifacts, err
+		}
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0o755|0o644)
+		if err != nil {
+			return artifacts, err
+		}
+		_, err = io.Copy(f, tr)
+		f.Close()
+		if err != nil {
+			return artifacts, err
+		}
+		if dir == "" {
+			artifacts = append(artifacts, file)
+		}
+	}
+}
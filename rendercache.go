@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// Render cache for serve: the same source rendered to the same format by the
+// same tools gives the same deck, so /render and jobs reuse the artifact of
+// an earlier render instead of running the tools again. Lookups are counted
+// as hits and misses in /metrics.
+//
+//	.dist/renders/<key>.<format>
+
+type renderCache struct {
+	cfg     *config
+	metrics *serveMetrics
+}
+
+// key fingerprints source, format and the tools that render it.
+func (c *renderCache) key(source []byte, format string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", format)
+	h.Write(source)
+	tools := []string{"decksh"}
+	if format != "xml" {
+		tools = append(tools, render.Converters[format])
+	}
+	if err := c.cfg.hashTools(h, tools...); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// render renders source as name in ws and converts it to format, or copies
+// the cached artifact there; it returns the artifact's path.
+func (c *renderCache) render(ctx context.Context, ws *renderWorkspace, name string, source []byte, format string) (string, error) {
+	key, err := c.key(source, format)
+	if err != nil {
+		return "", err
+	}
+	cached := c.cfg.getRenderCachePath(key, format)
+	if _, err := os.Stat(cached); err == nil {
+		c.metrics.cacheLookup(true)
+		output := filepath.Join(ws.dir, name+"."+format)
+		return output, copyFile(cached, output)
+	}
+	c.metrics.cacheLookup(false)
+
+	output, err := c.cfg.renderInWorkspace(ctx, ws, name, source)
+	if err == nil && format != "xml" {
+		if err = c.cfg.convertDeck(ctx, ws.dir, output, format); err == nil {
+			output = render.ConvertedPath(output, format)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	c.store(cached, output)
+	return output, nil
+}
+
+// store copies a fresh artifact into the cache through a temp file, so a
+// concurrent lookup never sees it half written. A failure only costs a
+// later miss.
+func (c *renderCache) store(cached, output string) {
+	if err := os.MkdirAll(filepath.Dir(cached), 0o755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(filepath.Dir(cached), ".tmp-")
+	if err != nil {
+		return
+	}
+	f.Close()
+	if err := copyFile(output, f.Name()); err != nil || os.Rename(f.Name(), cached) != nil {
+		os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Render cache keys: fingerprints of everything that determines a rendered deck
+
+// renderCacheKey fingerprints everything that determines an example's
+// rendered output: the files in its directory and the given tool binaries.
+func (cfg *config) renderCacheKey(dir string, tools ...string) (string, error) {
+	h := sha256.New()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || isRenderOutput(name) {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", name)
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := cfg.hashTools(h, tools...); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func isRenderOutput(name string) bool {
+	for _, ext := range []string{".xml", ".pdf", ".png", ".svg"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashTools adds the digest of each tool binary to h.
+func (cfg *config) hashTools(h io.Writer, tools ...string) error {
+	for _, tool := range tools {
+		path, err := cfg.resolveBinary(tool)
+		if err != nil {
+			return err
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%s", tool, sum)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Local work in the managed clones. Updates hard-reset a checkout to its
+// branch, so a clone with changes to tracked files or local commits is left
+// alone unless DECKTOOL_DIRTY (or ensure --stash/--force) says otherwise:
+//
+//	stash  stash the changes and keep local commits on a backup branch
+//	force  discard them, as updates always used to
+//
+// Untracked files (rendered outputs, new decks) survive a reset and do not
+// count, nor do changes decktool made itself: build.patches and data
+// connector outputs.
+
+const (
+	dirtyRefuse = ""
+	dirtyStash  = "stash"
+	dirtyForce  = "force"
+)
+
+// patchMarker records the state applyPatches left a checkout in.
+const patchMarker = "decktool-patched"
+
+// repoChanges is the local work an update would lose.
+type repoChanges struct {
+	files   []string // git status --porcelain lines of tracked files
+	commits int      // commits on the branch not on origin
+}
+
+func (c repoChanges) empty() bool {
+	return len(c.files) == 0 && c.commits == 0
+}
+
+func (c repoChanges) String() string {
+	var parts []string
+	if len(c.files) > 0 {
+		parts = append(parts, fmt.Sprintf("%d uncommitted change(s)", len(c.files)))
+	}
+	if c.commits > 0 {
+		parts = append(parts, fmt.Sprintf("%d local commit(s)", c.commits))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// localChanges inspects a checkout before it is fetched into.
+func (cfg *config) localChanges(ctx context.Context, repo *repoConfig) (repoChanges, error) {
+	var changes repoChanges
+	status, err := cfg.gitOutput(ctx, "-C", repo.dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return changes, fmt.Errorf("%s: git status: %w", repo.name, err)
+	}
+	if status != "" && !cfg.onlyPatched(ctx, repo) {
+		generated := cfg.dataOutputs()
+		for _, line := range strings.Split(status, "\n") {
+			if len(line) > 3 && slices.Contains(generated, filepath.Join(repo.dir, line[3:])) {
+				continue
+			}
+			changes.files = append(changes.files, line)
+		}
+	}
+	// Only the branch counts: a pinned checkout is a detached fetched SHA
+	if cfg.gitQuiet(ctx, "-C", repo.dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+repo.branch) == nil &&
+		cfg.gitQuiet(ctx, "-C", repo.dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+repo.branch) == nil {
+		ahead, err := cfg.gitOutput(ctx, "-C", repo.dir, "rev-list", "--count", "origin/"+repo.branch+".."+repo.branch)
+		if err != nil {
+			return changes, fmt.Errorf("%s: count local commits: %w", repo.name, err)
+		}
+		fmt.Sscan(ahead, &changes.commits)
+	}
+	return changes, nil
+}
+
+// gitQuiet runs git for its exit status only.
+func (cfg *config) gitQuiet(ctx context.Context, args ...string) error {
+	return cfg.gitCommand(ctx, args...).Run()
+}
+
+// worktreeFingerprint hashes the uncommitted state of a checkout.
+func (cfg *config) worktreeFingerprint(ctx context.Context, repo *repoConfig) (string, error) {
+	h := sha256.New()
+	for _, args := range [][]string{{"status", "--porcelain", "--untracked-files=no"}, {"diff", "HEAD", "--binary"}} {
+		cmd := cfg.gitCommand(ctx, append([]string{"-C", repo.dir}, args...)...)
+		out, err := cmd.Output()
+		if err != nil {
+			return "", err
+		}
+		h.Write(out)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// markPatched records that the checkout's changes are decktool's patches.
+func (cfg *config) markPatched(ctx context.Context, repo *repoConfig) error {
+	sum, err := cfg.worktreeFingerprint(ctx, repo)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(repo.dir, ".git", patchMarker), []byte(sum+"\n"), 0o644)
+}
+
+// onlyPatched reports whether the checkout is exactly as applyPatches left it.
+func (cfg *config) onlyPatched(ctx context.Context, repo *repoConfig) bool {
+	want, err := os.ReadFile(filepath.Join(repo.dir, ".git", patchMarker))
+	if err != nil {
+		return false
+	}
+	sum, err := cfg.worktreeFingerprint(ctx, repo)
+	return err == nil && sum == strings.TrimSpace(string(want))
+}
+
+// protectLocalChanges runs before an update resets repo. It refuses when
+// there is local work, unless cfg.dirtyRepos says to stash or discard it.
+func (cfg *config) protectLocalChanges(ctx context.Context, repo *repoConfig) error {
+	changes, err := cfg.localChanges(ctx, repo)
+	if err != nil || changes.empty() {
+		return err
+	}
+	switch cfg.dirtyRepos {
+	case dirtyForce:
+		fmt.Printf("⚠ %s: discarding %s\n", repo.dir, changes)
+		return nil
+	case dirtyStash:
+		stamp := time.Now().Format("20060102-150405")
+		if len(changes.files) > 0 {
+			if err := cfg.runGit(ctx, "-C", repo.dir, "stash", "push", "--quiet", "-m", "decktool update "+stamp); err != nil {
+				return fmt.Errorf("%s: stash: %w", repo.name, err)
+			}
+			fmt.Printf("✓ %s: stashed %d change(s); git -C %s stash pop to get them back\n", repo.name, len(changes.files), repo.dir)
+		}
+		if changes.commits > 0 {
+			backup := "decktool-backup/" + stamp
+			if err := cfg.runGit(ctx, "-C", repo.dir, "branch", backup, repo.branch); err != nil {
+				return fmt.Errorf("%s: back up local commits: %w", repo.name, err)
+			}
+			fmt.Printf("✓ %s: kept %d local commit(s) on branch %s\n", repo.name, changes.commits, backup)
+		}
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s has %s that updating would discard:\n", repo.dir, changes)
+	for i, f := range changes.files {
+		if i == 10 {
+			fmt.Fprintf(&b, "  … and %d more\n", len(changes.files)-i)
+			break
+		}
+		fmt.Fprintf(&b, "  %s\n", f)
+	}
+	b.WriteString("Commit and push them, or rerun with --stash (keep them in git stash and a backup branch) or --force (discard them); other commands take DECKTOOL_DIRTY=stash|force")
+	return errors.New(b.String())
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Submodules and Git LFS in the managed clones. A plain clone leaves
+// submodules empty and, without git-lfs, LFS-tracked assets as small
+// pointer files that only fail later at render time, so both are
+// initialized after every clone, update and pinned checkout.
+// <NAME>_SUBMODULES=skip and <NAME>_LFS=skip opt a repo out.
+
+// usesLFS reports whether the checkout tracks files with Git LFS.
+func usesLFS(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	return err == nil && strings.Contains(string(data), "filter=lfs")
+}
+
+// syncRepoExtras checks out repo's submodules and LFS files.
+func (cfg *config) syncRepoExtras(ctx context.Context, repo *repoConfig) error {
+	if _, err := os.Stat(filepath.Join(repo.dir, ".gitmodules")); err == nil {
+		if repo.skipSubmodules {
+			fmt.Printf("⊘ %s: submodules skipped (%s_SUBMODULES=skip)\n", repo.name, strings.ToUpper(repo.name))
+		} else {
+			if err := cfg.runGit(ctx, "-C", repo.dir, "submodule", "sync", "--quiet", "--recursive"); err != nil {
+				return fmt.Errorf("%s: sync submodules: %w", repo.name, err)
+			}
+			args := []string{"-C", repo.dir, "submodule", "update", "--init", "--recursive"}
+			if repo.depth > 0 {
+				args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+			}
+			if err := cfg.runGit(ctx, args...); err != nil {
+				return fmt.Errorf("%s: update submodules: %w", repo.name, err)
+			}
+		}
+	}
+
+	if !usesLFS(repo.dir) {
+		return nil
+	}
+	if repo.skipLFS {
+		fmt.Printf("⊘ %s: LFS files left as pointers (%s_LFS=skip)\n", repo.name, strings.ToUpper(repo.name))
+		return nil
+	}
+	if cfg.gitQuiet(ctx, "lfs", "version") != nil {
+		return fmt.Errorf("%s tracks files with Git LFS but git-lfs is not installed; install it (https://git-lfs.com) or set %s_LFS=skip to keep the pointer files",
+			repo.name, strings.ToUpper(repo.name))
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "lfs", "install", "--local"); err != nil {
+		return fmt.Errorf("%s: lfs install: %w", repo.name, err)
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "lfs", "pull"); err != nil {
+		return fmt.Errorf("%s: lfs pull: %w", repo.name, err)
+	}
+	return nil
+}
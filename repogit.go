@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cloning and updating the managed repos, and running git in them
+
+// cloned reports whether the repo has been checked out.
+func (repo *repoConfig) cloned() bool {
+	_, err := os.Stat(filepath.Join(repo.dir, ".git"))
+	return err == nil
+}
+
+func (cfg *config) gitCloneOrUpdate(ctx context.Context, repo *repoConfig) error {
+	kind, sync := "clone", cfg.gitClone
+	if repo.cloned() {
+		kind, sync = "update", cfg.gitUpdate
+	}
+	return cfg.withSyncHooks(ctx, repo, kind, func() error {
+		if err := sync(ctx, repo); err != nil {
+			return err
+		}
+		if err := cfg.syncRepoExtras(ctx, repo); err != nil {
+			return err
+		}
+		return cfg.ensureUpstreamRemote(ctx, repo)
+	})
+}
+
+func (cfg *config) gitClone(ctx context.Context, repo *repoConfig) error {
+	stdout, _ := toolOutput(ctx)
+	args := []string{"clone"}
+	source := repo.url
+	if cfg.mirrored(repo) {
+		// Borrow objects from the mirror instead of keeping a private copy
+		mirror, err := cfg.ensureMirror(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if len(repo.filter) == 0 {
+			// Everything is in the mirror: clone it locally, then point origin upstream
+			args, source = append(args, "--shared"), mirror
+		} else {
+			// A filtered mirror lacks blobs the checkout needs; those come from upstream
+			args = append(args, "--reference", mirror)
+		}
+	} else if repo.depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+	}
+	args = append(args, repo.filter...)
+	if repo.sparseOnDemand && len(repo.sparse) == 0 {
+		args = append(args, "--sparse") // top-level files only; examples follow on demand
+	}
+	args = append(args, "--branch", repo.branch, source, repo.dir)
+
+	fmt.Fprintf(stdout, "Cloning %s into %s\n", source, repo.dir)
+	if err := cfg.runGit(ctx, args...); err != nil {
+		return err
+	}
+	if source != repo.url {
+		if err := cfg.runGit(ctx, "-C", repo.dir, "remote", "set-url", "origin", repo.url); err != nil {
+			return err
+		}
+	}
+
+	// Handle sparse checkout if configured
+	if len(repo.sparse) > 0 {
+		if err := cfg.runGit(ctx, "-C", repo.dir, "sparse-checkout", "init", "--cone"); err != nil {
+			return err
+		}
+		setArgs := append([]string{"-C", repo.dir, "sparse-checkout", "set"}, repo.sparse...)
+		if err := cfg.runGit(ctx, setArgs...); err != nil {
+			return err
+		}
+	} else if repo.sparseOnDemand {
+		return cfg.initOnDemand(ctx, repo)
+	}
+	return nil
+}
+
+func (cfg *config) gitUpdate(ctx context.Context, repo *repoConfig) error {
+	stdout, _ := toolOutput(ctx)
+	if cfg.usesMirror(repo) {
+		// New objects land in the mirror; the fetch below then only updates refs
+		if _, err := cfg.ensureMirror(ctx, repo); err != nil {
+			return err
+		}
+	}
+	if err := cfg.protectLocalChanges(ctx, repo); err != nil {
+		return err
+	}
+	args := []string{"-C", repo.dir, "fetch"}
+	if repo.depth > 0 && !cfg.usesMirror(repo) {
+		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+	}
+	args = append(args, repo.filter...)
+	args = append(args, "origin", repo.branch)
+
+	fmt.Fprintf(stdout, "Updating %s\n", repo.dir)
+	if err := cfg.runGit(ctx, args...); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "checkout", repo.branch); err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "reset", "--hard", "origin/"+repo.branch); err != nil {
+		return err
+	}
+
+	// Update sparse checkout if configured
+	if len(repo.sparse) > 0 {
+		setArgs := append([]string{"-C", repo.dir, "sparse-checkout", "set"}, repo.sparse...)
+		if err := cfg.runGit(ctx, setArgs...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *config) runGit(ctx context.Context, args ...string) (err error) {
+	stdout, stderr := toolOutput(ctx)
+	ctx, span := startSpan(ctx, "git", "git.args", strings.Join(args, " "))
+	defer func() { span.end(err) }()
+
+	cmd := cfg.gitCommand(ctx, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// gitOutput runs git and returns its trimmed stdout.
+func (cfg *config) gitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := cfg.gitCommand(ctx, args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
-// Repository management functions
+// Repository management functions (clone and update in repogit.go)
 
 func (cfg *config) initDataRepos() {
 	cfg.addDataRepo("deckviz", "deckviz", "master")
@@ -17,17 +17,20 @@ func (cfg *config) initDataRepos() {
 	dubois := cfg.addDataRepo("dubois", "dubois-data-portraits", "master")
 	dubois.filterRaw = getenvDefault("DUBOIS_FILTER", "--filter=blob:none")
 	dubois.sparseRaw = os.Getenv("DUBOIS_SPARSE")
+	dubois.sparseOnDemand = dubois.sparseRaw == "" && os.Getenv("DUBOIS_ON_DEMAND") != "0"
+	dubois.shared = strings.Fields(os.Getenv("DUBOIS_SHARED"))
 }
 
 func (cfg *config) initFontsRepo() error {
 	// Create fonts repo config (clone to .fonts directory)
 	cfg.fontsRepo = &repoConfig{
-		name:   "deckfonts",
-		url:    getenvDefault("DECKFONTS_REPO", "https://github.com/ajstarks/deckfonts.git"),
-		dir:    getenvDefault("DECKFONTS_DIR", fontsDir),
-		branch: getenvDefault("DECKFONTS_BRANCH", "master"),
-		depth:  getenvInt("DECKFONTS_DEPTH", 1),
-		isData: true,
+		name:     "deckfonts",
+		url:      getenvDefault("DECKFONTS_REPO", "https://github.com/ajstarks/deckfonts.git"),
+		upstream: "https://github.com/ajstarks/deckfonts.git",
+		dir:      getenvDefault("DECKFONTS_DIR", fontsDir),
+		branch:   getenvDefault("DECKFONTS_BRANCH", "master"),
+		depth:    getenvInt("DECKFONTS_DEPTH", 1),
+		isData:   true,
 	}
 	cfg.fontsDir = cfg.fontsRepo.dir // Store dir path (will be made absolute in finalize())
 
@@ -47,118 +50,110 @@ func (cfg *config) initCodeRepos() {
 }
 
 func (cfg *config) addDataRepo(name, dir, branch string) *repoConfig {
+	upstream := fmt.Sprintf("https://github.com/ajstarks/%s.git", dir)
 	repo := &repoConfig{
-		name:   name,
-		url:    getenvDefault(strings.ToUpper(name)+"_REPO", fmt.Sprintf("https://github.com/ajstarks/%s.git", dir)),
-		dir:    getenvDefault(strings.ToUpper(name)+"_DIR", filepath.Join(dataDir, dir)),
-		branch: getenvDefault(strings.ToUpper(name)+"_BRANCH", branch),
-		depth:  getenvInt(strings.ToUpper(name)+"_DEPTH", 1),
-		isData: true,
+		name:     name,
+		url:      getenvDefault(strings.ToUpper(name)+"_REPO", upstream),
+		upstream: upstream,
+		dir:      getenvDefault(strings.ToUpper(name)+"_DIR", filepath.Join(dataDir, dir)),
+		branch:   getenvDefault(strings.ToUpper(name)+"_BRANCH", branch),
+		depth:    getenvInt(strings.ToUpper(name)+"_DEPTH", 1),
+		isData:   true,
 	}
 	cfg.repos[name] = repo
 	return repo
 }
 
 func (cfg *config) addCodeRepo(name, branch string) *repoConfig {
+	upstream := fmt.Sprintf("https://github.com/ajstarks/%s.git", name)
 	repo := &repoConfig{
-		name:   name,
-		url:    getenvDefault(strings.ToUpper(name)+"_REPO", fmt.Sprintf("https://github.com/ajstarks/%s.git", name)),
-		dir:    getenvDefault(strings.ToUpper(name)+"_DIR", filepath.Join(srcDir, name)),
-		branch: getenvDefault(strings.ToUpper(name)+"_BRANCH", branch),
-		depth:  getenvInt(strings.ToUpper(name)+"_DEPTH", 1),
-		isData: false,
+		name:     name,
+		url:      getenvDefault(strings.ToUpper(name)+"_REPO", upstream),
+		upstream: upstream,
+		dir:      getenvDefault(strings.ToUpper(name)+"_DIR", filepath.Join(srcDir, name)),
+		branch:   getenvDefault(strings.ToUpper(name)+"_BRANCH", branch),
+		depth:    getenvInt(strings.ToUpper(name)+"_DEPTH", 1),
+		isData:   false,
 	}
 	cfg.repos[name] = repo
 	return repo
 }
 
+// ensureRepos syncs every data repo, cloning the ones not fetched yet.
 func (cfg *config) ensureRepos(ctx context.Context) error {
-	for _, repo := range cfg.repos {
-		if repo.isData {
-			if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return cfg.syncDataRepos(ctx, func(*repoConfig) bool { return true })
 }
 
-func (cfg *config) ensureBuildRepos(ctx context.Context) error {
-	for _, repo := range cfg.repos {
-		if !repo.isData {
-			if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
-				return err
-			}
-		}
+// ensureReposFor syncs the data repos the examples come from. Example repos
+// are cloned on first use, so setting up never pays for a corpus nobody
+// renders; with no examples every repo is needed.
+func (cfg *config) ensureReposFor(ctx context.Context, examples []string) error {
+	if len(examples) == 0 {
+		return cfg.ensureRepos(ctx)
 	}
-	return nil
-}
-
-func (cfg *config) gitCloneOrUpdate(ctx context.Context, repo *repoConfig) error {
-	if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err == nil {
-		return cfg.gitUpdate(ctx, repo)
+	var sources []string
+	for _, raw := range examples {
+		source, _ := cfg.parseExample(raw)
+		sources = append(sources, source)
 	}
-	return cfg.gitClone(ctx, repo)
+	return cfg.syncDataRepos(ctx, func(repo *repoConfig) bool { return slices.Contains(sources, repo.name) })
 }
 
-func (cfg *config) gitClone(ctx context.Context, repo *repoConfig) error {
-	args := []string{"clone"}
-	if repo.depth > 0 {
-		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
-	}
-	args = append(args, repo.filter...)
-	args = append(args, "--branch", repo.branch, repo.url, repo.dir)
-
-	fmt.Printf("Cloning %s into %s\n", repo.url, repo.dir)
-	if err := cfg.runGit(ctx, args...); err != nil {
-		return err
-	}
-
-	// Handle sparse checkout if configured
-	if len(repo.sparse) > 0 {
-		if err := cfg.runGit(ctx, "-C", repo.dir, "sparse-checkout", "init", "--cone"); err != nil {
-			return err
+// syncDataRepos updates the fonts and every example repo already cloned,
+// and clones the missing example repos want asks for.
+func (cfg *config) syncDataRepos(ctx context.Context, want func(*repoConfig) bool) error {
+	stdout, _ := toolOutput(ctx)
+	for _, repo := range cfg.repos {
+		if !repo.isData {
+			continue
+		}
+		if repo != cfg.fontsRepo && !repo.cloned() {
+			if !want(repo) {
+				continue
+			}
+			fmt.Fprintf(stdout, "Fetching %s on first use (%s)...\n", repo.name, repo.url)
 		}
-		setArgs := append([]string{"-C", repo.dir, "sparse-checkout", "set"}, repo.sparse...)
-		if err := cfg.runGit(ctx, setArgs...); err != nil {
+		if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (cfg *config) gitUpdate(ctx context.Context, repo *repoConfig) error {
-	args := []string{"-C", repo.dir, "fetch"}
-	if repo.depth > 0 {
-		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+// materializeSource clones an example repo the first time an example from
+// it is used.
+func (cfg *config) materializeSource(ctx context.Context, source string) error {
+	stdout, _ := toolOutput(ctx)
+	repo, ok := cfg.repos[source]
+	if !ok || repo.cloned() {
+		return nil
 	}
-	args = append(args, repo.filter...)
-	args = append(args, "origin", repo.branch)
-
-	fmt.Printf("Updating %s\n", repo.dir)
-	if err := cfg.runGit(ctx, args...); err != nil {
-		return err
-	}
-	if err := cfg.runGit(ctx, "-C", repo.dir, "checkout", repo.branch); err != nil {
-		return err
-	}
-	if err := cfg.runGit(ctx, "-C", repo.dir, "reset", "--hard", "origin/"+repo.branch); err != nil {
-		return err
+	fmt.Fprintf(stdout, "Fetching %s on first use (%s)...\n", repo.name, repo.url)
+	if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
+		return fmt.Errorf("fetch %s: %w", source, err)
 	}
+	return nil
+}
 
-	// Update sparse checkout if configured
-	if len(repo.sparse) > 0 {
-		setArgs := append([]string{"-C", repo.dir, "sparse-checkout", "set"}, repo.sparse...)
-		if err := cfg.runGit(ctx, setArgs...); err != nil {
-			return err
+// unfetchedRepos lists the example repos not cloned yet.
+func (cfg *config) unfetchedRepos() []string {
+	var names []string
+	for name, repo := range cfg.repos {
+		if repo.isData && repo != cfg.fontsRepo && !repo.cloned() {
+			names = append(names, name)
 		}
 	}
-	return nil
+	slices.Sort(names)
+	return names
 }
 
-func (cfg *config) runGit(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, cfg.gitCmd, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func (cfg *config) ensureBuildRepos(ctx context.Context) error {
+	for _, repo := range cfg.repos {
+		if !repo.isData {
+			if err := cfg.gitCloneOrUpdate(ctx, repo); err != nil {
+				return err
+			}
+		}
+	}
+	return cfg.applyPatches(ctx)
 }
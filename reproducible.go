@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reproducible build verification (double-build comparison)
+
+// reproducibleFlags make go build output independent of checkout paths and VCS state.
+var reproducibleFlags = []string{"-trimpath", "-buildvcs=false", "-ldflags=-buildid="}
+
+// normalizedBuildEnv removes settings that vary between machines and
+// points the build at its own cache so each pass really recompiles.
+func normalizedBuildEnv(cacheDir string) []string {
+	return []string{"GOOS", "GOARCH", "CGO_CFLAGS", "CGO_LDFLAGS", "GOCACHE=" + cacheDir, "GOFLAGS="}
+}
+
+// verifyReproducible builds every supported artifact twice with independent
+// caches and reports artifacts whose hashes differ.
+func (cfg *config) verifyReproducible(ctx context.Context, targets []buildTarget) error {
+	tmp, err := os.MkdirTemp("", "decktool-repro-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	var differing, failed []string
+	for _, spec := range cfg.toolchain {
+		for _, target := range targets {
+			if err := cfg.checkTargetSupport(ctx, spec, target); err != nil {
+				fmt.Printf("⊘ %s (%s): %v\n", spec.name, target, err)
+				continue
+			}
+			filename := cfg.buildFilename(spec.name, target)
+			fmt.Printf("Building %s twice...\n", filename)
+
+			var sums [2]string
+			for pass := range sums {
+				passDir := filepath.Join(tmp, fmt.Sprintf("pass%d", pass+1))
+				out := filepath.Join(passDir, filename)
+				env := normalizedBuildEnv(filepath.Join(passDir, "cache"))
+				cmd := cfg.goBuildCommand(ctx, spec, target, out, env, reproducibleFlags...)
+				if output, err := cmd.CombinedOutput(); err != nil {
+					fmt.Printf("✗ %s: build failed: %v\n", filename, err)
+					for _, line := range lastLines(string(output), logExcerptLines) {
+						fmt.Printf("    %s\n", line)
+					}
+					break
+				}
+				if sums[pass], err = fileSHA256(out); err != nil {
+					return err
+				}
+			}
+
+			switch {
+			case sums[0] == "" || sums[1] == "":
+				failed = append(failed, filename)
+			case sums[0] != sums[1]:
+				fmt.Printf("✗ %s is not reproducible (%s vs %s)\n", filename, sums[0][:12], sums[1][:12])
+				differing = append(differing, filename)
+			default:
+				fmt.Printf("✓ %s reproducible (sha256 %s)\n", filename, sums[0][:12])
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Printf("\nFailed to build: %s\n", strings.Join(failed, ", "))
+	}
+	if len(differing) > 0 {
+		return fmt.Errorf("%d artifact(s) not reproducible: %s", len(differing), strings.Join(differing, ", "))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d artifact(s) failed to build", len(failed))
+	}
+	fmt.Println("\n✓ All artifacts are reproducible")
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// Scheduled regeneration: jobs that re-pull data, re-render decks and
+// republish their outputs on a cron schedule, so decktool can run as a
+// small report service (decktool schedule).
+
+type scheduleConfig struct {
+	Jobs []scheduleJob `json:"jobs"`
+}
+
+type scheduleJob struct {
+	Name    string   `json:"name"`
+	Cron    string   `json:"cron"`    // five-field cron expression or @hourly/@daily/...
+	Decks   []string `json:"decks"`   // examples to re-render
+	Formats []string `json:"formats"` // outputs per deck (default pdf)
+	Export  string   `json:"export"`  // directory the outputs are copied to
+	Gallery bool     `json:"gallery"` // rebuild the gallery
+	Deploy  string   `json:"deploy"`  // gallery deploy target (gh-pages or dir://<path>)
+}
+
+func (c scheduleConfig) validate() error {
+	var names []string
+	for i, job := range c.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("schedule.jobs[%d]: name is required", i)
+		}
+		if slices.Contains(names, job.Name) {
+			return fmt.Errorf("schedule.jobs: duplicate name %q", job.Name)
+		}
+		names = append(names, job.Name)
+		if _, err := parseCron(job.Cron); err != nil {
+			return fmt.Errorf("schedule.%s: %w", job.Name, err)
+		}
+		if len(job.Decks) == 0 && !job.Gallery {
+			return fmt.Errorf("schedule.%s: nothing to do (set decks or gallery)", job.Name)
+		}
+		for _, format := range job.Formats {
+			if _, ok := render.Converters[format]; !ok {
+				return fmt.Errorf("schedule.%s: unknown format %q", job.Name, format)
+			}
+		}
+		if job.Deploy != "" && !job.Gallery {
+			return fmt.Errorf("schedule.%s: deploy publishes the gallery; set gallery too", job.Name)
+		}
+	}
+	return nil
+}
+
+func (j scheduleJob) formats() []string {
+	if len(j.Formats) == 0 {
+		return []string{"pdf"}
+	}
+	return j.Formats
+}
+
+// nextRun returns when the job is due after t; jobs are validated on load.
+func (j scheduleJob) nextRun(t time.Time) time.Time {
+	sched, err := parseCron(j.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	next, _ := sched.next(t)
+	return next
+}
+
+func (cfg *config) scheduleJob(name string) (scheduleJob, error) {
+	for _, job := range cfg.file.Schedule.Jobs {
+		if job.Name == name {
+			return job, nil
+		}
+	}
+	return scheduleJob{}, fmt.Errorf("no scheduled job %q (see decktool schedule list)", name)
+}
+
+// scheduleRun records the outcome of a job's last run.
+type scheduleRun struct {
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (cfg *config) loadScheduleState() map[string]scheduleRun {
+	state := make(map[string]scheduleRun)
+	if data, err := os.ReadFile(cfg.getScheduleStatePath()); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func (cfg *config) recordScheduleRun(name string, run scheduleRun) error {
+	state := cfg.loadScheduleState()
+	state[name] = run
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.getScheduleStatePath()), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.getScheduleStatePath(), data, 0o644)
+}
+
+// runScheduledJob runs a job once and records the outcome.
+func (cfg *config) runScheduledJob(ctx context.Context, job scheduleJob) error {
+	started := time.Now()
+	fmt.Printf("=== %s: job %s ===\n", started.Format(time.DateTime), job.Name)
+	failures, err := cfg.regenerate(ctx, job)
+	run := scheduleRun{Started: started, Duration: time.Since(started).Round(time.Second)}
+	n := runNotification{Event: "schedule", Title: "schedule " + job.Name, OK: err == nil, Details: failures}
+	if err != nil {
+		run.Error = err.Error()
+		n.Summary = fmt.Sprintf("failed after %s: %v", run.Duration, err)
+		fmt.Printf("✗ %s failed after %s: %v\n", job.Name, run.Duration, err)
+	} else {
+		n.Summary = fmt.Sprintf("%d deck(s) regenerated in %s", len(job.Decks), run.Duration)
+		if job.Gallery {
+			n.Summary += ", gallery rebuilt"
+		}
+		fmt.Printf("✓ %s done in %s\n", job.Name, run.Duration)
+	}
+	if rerr := cfg.recordScheduleRun(job.Name, run); rerr != nil {
+		fmt.Printf("⚠ record run: %v\n", rerr)
+	}
+	cfg.notify(ctx, n)
+	return err
+}
+
+// runSchedule runs the jobs as they fall due until ctx is done. Jobs run
+// one at a time; a slot missed while another job ran is skipped.
+func (cfg *config) runSchedule(ctx context.Context, jobs []scheduleJob) error {
+	if len(jobs) == 0 {
+		return errors.New(`no scheduled jobs (see the "schedule" section in decktool schedule --help)`)
+	}
+	for {
+		now := time.Now()
+		var due time.Time
+		var next []scheduleJob
+		for _, job := range jobs {
+			at := job.nextRun(now)
+			switch {
+			case at.IsZero():
+			case due.IsZero() || at.Before(due):
+				due, next = at, []scheduleJob{job}
+			case at.Equal(due):
+				next = append(next, job)
+			}
+		}
+		if due.IsZero() {
+			return errors.New("no job is ever due")
+		}
+		var names []string
+		for _, job := range next {
+			names = append(names, job.Name)
+		}
+		fmt.Printf("Next: %s at %s\n", strings.Join(names, ", "), due.Format(time.DateTime))
+		// Sleep in short steps so a suspended machine or clock change is noticed
+		for time.Now().Before(due) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(min(time.Until(due), time.Minute)):
+			}
+		}
+		for _, job := range next {
+			cfg.runScheduledJob(ctx, job)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// What a scheduled job runs: sync, pull data, render, convert and export
+
+// regenerate syncs the data repos, re-pulls the decks' data, renders and
+// exports every deck, and rebuilds the gallery. A failing deck does not
+// stop the others; their errors are returned as failures.
+func (cfg *config) regenerate(ctx context.Context, job scheduleJob) (failures []string, err error) {
+	if err := cfg.ensureRepos(ctx); err != nil {
+		return nil, err
+	}
+	refresh := cfg.refreshData
+	cfg.refreshData = true
+	defer func() { cfg.refreshData = refresh }()
+
+	var decks []string
+	for _, deck := range job.Decks {
+		decks = append(decks, cfg.normalizeExampleName(deck))
+	}
+	if job.Export != "" {
+		if err := checkCollisions(decks, catalogID); err != nil {
+			return nil, fmt.Errorf("export to %s: %w", job.Export, err)
+		}
+	}
+
+	var failed []string
+	cfg.pipelineOutputs(ctx, decks, job.formats(), func(ctx context.Context, i int, outputs []string, err error) {
+		deck := decks[i]
+		if err == nil && job.Export != "" {
+			err = exportOutputs(deck, outputs, job.Export)
+		}
+		out, _ := toolOutput(ctx)
+		if err != nil {
+			fmt.Fprintf(out, "✗ %s: %v\n", deck, err)
+			failed = append(failed, deck)
+			failures = append(failures, fmt.Sprintf("%s: %v", deck, err))
+			return
+		}
+		fmt.Fprintf(out, "✓ %s: %d file(s)\n", deck, len(outputs))
+	})
+	if job.Gallery {
+		dir := cfg.getGalleryDir()
+		stats, err := cfg.buildGallery(ctx, dir, "")
+		if err != nil {
+			return failures, fmt.Errorf("gallery: %w", err)
+		}
+		fmt.Printf("✓ Gallery: %d rendered, %d reused, %d failed\n", stats.rendered, stats.reused, stats.failed)
+		if job.Deploy != "" {
+			if err := cfg.deployGallery(ctx, dir, job.Deploy); err != nil {
+				return failures, fmt.Errorf("deploy gallery: %w", err)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return failures, fmt.Errorf("%d of %d deck(s) failed: %s", len(failed), len(job.Decks), strings.Join(failed, ", "))
+	}
+	return nil, nil
+}
+
+// renderChecked renders an example and runs the property checks on it.
+func (cfg *config) renderChecked(ctx context.Context, example string) (string, error) {
+	xmlPath, err := cfg.renderExample(ctx, example)
+	if err != nil {
+		return "", err
+	}
+	return xmlPath, cfg.propertyError(example, xmlPath)
+}
+
+// convertOutputs converts a rendered deck to each format, returning the
+// files written.
+func (cfg *config) convertOutputs(ctx context.Context, xmlPath string, formats []string) ([]string, error) {
+	if err := cfg.convertFormats(ctx, filepath.Dir(xmlPath), xmlPath, formats); err != nil {
+		return nil, err
+	}
+	var outputs []string
+	for _, format := range formats {
+		if format == "pdf" {
+			outputs = append(outputs, render.ConvertedPath(xmlPath, format))
+			continue
+		}
+		pages, _ := filepath.Glob(strings.TrimSuffix(xmlPath, ".xml") + "-*." + format)
+		if len(pages) == 0 {
+			return nil, fmt.Errorf("%s: no output written", format)
+		}
+		outputs = append(outputs, pages...)
+	}
+	return outputs, nil
+}
+
+// exportOutputs copies an example's outputs into dir as <id><suffix>, e.g.
+// deckviz-fire.pdf and deckviz-fire-00001.png.
+func exportOutputs(example string, outputs []string, dir string) error {
+	dir = strings.TrimPrefix(dir, "dir://")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	name := example[strings.LastIndex(example, "/")+1:]
+	for _, out := range outputs {
+		suffix := strings.TrimPrefix(filepath.Base(out), name)
+		dst := filepath.Join(dir, catalogID(example)+suffix)
+		if err := copyFile(out, dst); err != nil {
+			return err
+		}
+		recordArtifact(dst)
+	}
+	return nil
+}
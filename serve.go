@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Render API server: POST decksh source, receive the rendered deck
+
+var serveContentTypes = map[string]string{
+	"xml": "application/xml",
+	"pdf": "application/pdf",
+}
+
+func (cfg *config) newServeMux(guard *serveGuard, jobs *jobQueue, cache *renderCache) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("POST /render", guard.wrap(cache.handleRender))
+	mux.HandleFunc("GET /metrics", guard.authOnly(guard.metrics))
+	mux.HandleFunc("POST /corpus/shard", guard.wrap(cfg.handleShard))
+	jobs.register(mux, guard)
+	return mux
+}
+
+func renderFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	return "xml"
+}
+
+// handleRender renders the request body in its own temp workspace, or
+// serves the cached result of the same render. Query: format=xml (default) or pdf.
+func (c *renderCache) handleRender(w http.ResponseWriter, r *http.Request) {
+	ctx, span := startSpan(r.Context(), "POST /render", "http.remote_addr", r.RemoteAddr)
+	var err error
+	defer func() { span.end(err) }()
+
+	format := renderFormat(r)
+	contentType, ok := serveContentTypes[format]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
+	source, err := io.ReadAll(r.Body) // capped by serveGuard
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ws, err := c.cfg.newRenderWorkspace("api")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer ws.cleanup()
+
+	start := time.Now()
+	output, err := c.render(ctx, ws, "deck", source, format)
+	if err != nil {
+		log.Printf("render failed: %v", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	log.Printf("rendered %s (%d bytes in) in %s", format, len(source), time.Since(start).Round(time.Millisecond))
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", "deck."+format))
+	http.ServeFile(w, r, filepath.Clean(output))
+}
+
+// serve runs the render API until ctx is cancelled.
+func (cfg *config) serve(ctx context.Context, addr string) error {
+	if _, err := cfg.resolveBinary("decksh"); err != nil {
+		return err
+	}
+	guard, err := cfg.newServeGuard(addr)
+	if err != nil {
+		return err
+	}
+	jobs, err := cfg.newJobQueue(ctx, cap(guard.slots), guard.metrics)
+	if err != nil {
+		return fmt.Errorf("job queue: %w", err)
+	}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           cfg.newServeMux(guard, jobs, jobs.cache),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	go exportSpansPeriodically(ctx, 5*time.Second)
+	fmt.Fprintf(os.Stderr, "Serving render API on http://%s (POST /render, POST /jobs, GET /metrics)\n", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
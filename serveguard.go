@@ -0,0 +1,195 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Access control for the render API: tokens, rate limits, size and concurrency caps
+// (the limiter in serverate.go, render slots in serveslots.go)
+
+// serveConfig limits what API clients may do, e.g.
+//
+//	"serve": {"rate_per_minute": 30, "max_body": "1MB", "max_concurrent": 2}
+//
+// Tokens come from the SERVE_TOKENS environment variable (comma-separated) so
+// secrets stay out of the committed config file.
+type serveConfig struct {
+	RatePerMinute int    `json:"rate_per_minute"` // renders per client per minute
+	MaxBody       string `json:"max_body"`        // largest accepted request body
+	MaxConcurrent int    `json:"max_concurrent"`  // renders running at once
+}
+
+func (s serveConfig) validate() error {
+	if s.MaxBody != "" {
+		if _, err := parseSize(s.MaxBody); err != nil {
+			return fmt.Errorf("serve.max_body: %w", err)
+		}
+	}
+	if s.RatePerMinute < 0 || s.MaxConcurrent < 0 {
+		return errors.New("serve limits must not be negative")
+	}
+	return nil
+}
+
+type serveGuard struct {
+	tokens  []string
+	limiter *rateLimiter
+	maxBody int64
+	slots   renderSlots
+	metrics *serveMetrics
+}
+
+func (cfg *config) newServeGuard(addr string) (*serveGuard, error) {
+	s := cfg.file.Serve
+	concurrent := s.MaxConcurrent
+	if concurrent == 0 {
+		concurrent = 4
+	}
+	rate := s.RatePerMinute
+	if rate == 0 {
+		rate = 60
+	}
+	g := &serveGuard{
+		limiter: newRateLimiter(rate),
+		maxBody: maxDeckSourceBytes,
+		slots:   make(renderSlots, concurrent),
+		metrics: newServeMetrics(concurrent),
+	}
+	if s.MaxBody != "" {
+		g.maxBody, _ = parseSize(s.MaxBody)
+	}
+	for _, tok := range strings.Split(os.Getenv("SERVE_TOKENS"), ",") {
+		if tok = strings.TrimSpace(tok); tok != "" {
+			g.tokens = append(g.tokens, tok)
+		}
+	}
+	if len(g.tokens) == 0 && !isLoopbackAddr(addr) {
+		return nil, fmt.Errorf("refusing to serve on %s without authentication: set SERVE_TOKENS", addr)
+	}
+	return g, nil
+}
+
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// admit applies authentication, rate limiting and the body cap, in that
+// order, writing the rejection when the request is refused.
+func (g *serveGuard) admit(w http.ResponseWriter, r *http.Request) bool {
+	client, ok := g.authenticate(r)
+	if !ok {
+		g.metrics.rejected("unauthorized")
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if !g.limiter.allow(client) {
+		g.metrics.rejected("rate_limited")
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return false
+	}
+	if r.ContentLength > g.maxBody {
+		g.metrics.rejected("too_large")
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, g.maxBody)
+	return true
+}
+
+// admitOnly applies admit without the concurrency limit, for queued jobs
+// whose renders are bounded by the job workers instead.
+func (g *serveGuard) admitOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.admit(w, r) {
+			next(w, r)
+		}
+	}
+}
+
+// wrap applies admit and then the concurrency limit.
+func (g *serveGuard) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !g.admit(w, r) {
+			return
+		}
+		if !g.slots.acquire() {
+			g.metrics.rejected("busy")
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "too many renders in progress", http.StatusServiceUnavailable)
+			return
+		}
+		defer g.slots.release()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		g.metrics.renderStarted()
+		next(rec, r)
+		var err error
+		if rec.status >= 400 {
+			err = fmt.Errorf("HTTP %d", rec.status)
+		}
+		format := renderFormat(r)
+		if _, ok := serveContentTypes[format]; !ok {
+			format = "invalid" // keep label cardinality bounded
+		}
+		g.metrics.renderFinished(format, err, time.Since(start))
+	}
+}
+
+// authOnly applies authentication without rate or render limits.
+func (g *serveGuard) authOnly(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := g.authenticate(r); !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// authenticate returns the client identity used for rate limiting: the
+// token when tokens are configured, otherwise the remote IP.
+func (g *serveGuard) authenticate(r *http.Request) (string, bool) {
+	if len(g.tokens) == 0 {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		return host, true
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+	for i, tok := range g.tokens {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(tok)) == 1 {
+			return fmt.Sprintf("token#%d", i), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Job endpoints for the render API: submit, inspect, fetch and cancel queued renders
+
+func (q *jobQueue) register(mux *http.ServeMux, guard *serveGuard) {
+	mux.HandleFunc("POST /jobs", guard.admitOnly(q.handleSubmit))
+	mux.HandleFunc("GET /jobs", guard.authOnly(http.HandlerFunc(q.handleList)))
+	mux.HandleFunc("GET /jobs/{id}", guard.authOnly(http.HandlerFunc(q.handleGet)))
+	mux.HandleFunc("GET /jobs/{id}/artifact", guard.authOnly(http.HandlerFunc(q.handleArtifact)))
+	mux.HandleFunc("GET /jobs/{id}/logs", guard.authOnly(http.HandlerFunc(q.handleLogs)))
+	mux.HandleFunc("DELETE /jobs/{id}", guard.authOnly(http.HandlerFunc(q.handleCancel)))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleSubmit queues the request body; query: format=xml (default) or pdf.
+func (q *jobQueue) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	format := renderFormat(r)
+	if _, ok := serveContentTypes[format]; !ok {
+		http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
+	source, err := io.ReadAll(r.Body) // capped by serveGuard
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	j, err := q.submit(source, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+func (q *jobQueue) handleList(w http.ResponseWriter, r *http.Request) {
+	jobs, err := q.cfg.listJobs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (q *jobQueue) handleGet(w http.ResponseWriter, r *http.Request) {
+	j, err := q.cfg.loadJob(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, j)
+}
+
+func (q *jobQueue) handleArtifact(w http.ResponseWriter, r *http.Request) {
+	j, err := q.cfg.loadJob(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if j.Status != jobDone {
+		http.Error(w, fmt.Sprintf("job %s is %s", j.ID, j.Status), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", serveContentTypes[j.Format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", j.Artifact))
+	http.ServeFile(w, r, filepath.Join(q.cfg.getJobDir(j.ID), j.Artifact))
+}
+
+func (q *jobQueue) handleLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := q.cfg.loadJob(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(q.cfg.getJobDir(id), jobLogFile))
+	if err != nil && !os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(data)
+}
+
+func (q *jobQueue) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if err := q.cancel(r.PathValue("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prometheus metrics for the render API (text exposition format)
+
+var renderDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type serveMetrics struct {
+	mu         sync.Mutex
+	renders    map[[2]string]int // {format, status} -> count
+	rejections map[string]int    // reason -> count
+	lookups    map[string]int    // render cache result (hit, miss) -> count
+	durations  map[string]*histogram
+	inProgress int
+	capacity   int
+	queued     int // jobs waiting for a worker
+}
+
+type histogram struct {
+	counts []int // per bucket, cumulative on output
+	sum    float64
+	count  int
+}
+
+func newServeMetrics(capacity int) *serveMetrics {
+	return &serveMetrics{
+		renders:    make(map[[2]string]int),
+		rejections: make(map[string]int),
+		lookups:    make(map[string]int),
+		durations:  make(map[string]*histogram),
+		capacity:   capacity,
+	}
+}
+
+func (m *serveMetrics) renderStarted() {
+	m.mu.Lock()
+	m.inProgress++
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) renderFinished(format string, err error, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inProgress--
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.renders[[2]string{format, status}]++
+	h, ok := m.durations[format]
+	if !ok {
+		h = &histogram{counts: make([]int, len(renderDurationBuckets))}
+		m.durations[format] = h
+	}
+	for i, le := range renderDurationBuckets {
+		if d.Seconds() <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += d.Seconds()
+	h.count++
+}
+
+func (m *serveMetrics) jobsQueued(n int) {
+	m.mu.Lock()
+	m.queued = n
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) rejected(reason string) {
+	m.mu.Lock()
+	m.rejections[reason]++
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) cacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.mu.Lock()
+	m.lookups[result]++
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+
+	b.WriteString("# HELP decktool_renders_total Renders handled by the API, including jobs.\n# TYPE decktool_renders_total counter\n")
+	var keys [][2]string
+	for k := range m.renders {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i][0]+keys[i][1] < keys[j][0]+keys[j][1] })
+	for _, k := range keys {
+		fmt.Fprintf(&b, "decktool_renders_total{format=%q,status=%q} %d\n", k[0], k[1], m.renders[k])
+	}
+
+	b.WriteString("# HELP decktool_render_rejections_total Requests refused before rendering.\n# TYPE decktool_render_rejections_total counter\n")
+	for _, reason := range sortedKeys(m.rejections) {
+		fmt.Fprintf(&b, "decktool_render_rejections_total{reason=%q} %d\n", reason, m.rejections[reason])
+	}
+
+	b.WriteString("# HELP decktool_render_cache_lookups_total Render cache lookups by result; hit rate is hit over the total.\n# TYPE decktool_render_cache_lookups_total counter\n")
+	for _, result := range []string{"hit", "miss"} {
+		fmt.Fprintf(&b, "decktool_render_cache_lookups_total{result=%q} %d\n", result, m.lookups[result])
+	}
+
+	b.WriteString("# HELP decktool_render_duration_seconds Render duration including conversion.\n# TYPE decktool_render_duration_seconds histogram\n")
+	var formats []string
+	for f := range m.durations {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	for _, f := range formats {
+		h := m.durations[f]
+		for i, le := range renderDurationBuckets {
+			fmt.Fprintf(&b, "decktool_render_duration_seconds_bucket{format=%q,le=\"%g\"} %d\n", f, le, h.counts[i])
+		}
+		fmt.Fprintf(&b, "decktool_render_duration_seconds_bucket{format=%q,le=\"+Inf\"} %d\n", f, h.count)
+		fmt.Fprintf(&b, "decktool_render_duration_seconds_sum{format=%q} %g\n", f, h.sum)
+		fmt.Fprintf(&b, "decktool_render_duration_seconds_count{format=%q} %d\n", f, h.count)
+	}
+
+	fmt.Fprintf(&b, "# HELP decktool_renders_in_progress Renders currently running.\n# TYPE decktool_renders_in_progress gauge\ndecktool_renders_in_progress %d\n", m.inProgress)
+	fmt.Fprintf(&b, "# HELP decktool_render_capacity Maximum concurrent renders.\n# TYPE decktool_render_capacity gauge\ndecktool_render_capacity %d\n", m.capacity)
+	fmt.Fprintf(&b, "# HELP decktool_jobs_queued Render jobs waiting for a worker.\n# TYPE decktool_jobs_queued gauge\ndecktool_jobs_queued %d\n", m.queued)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+func sortedKeys(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Per-client rate limiting for the render API
+
+// rateLimiter keeps a token bucket per client, refilled at rate per minute.
+type rateLimiter struct {
+	rate int
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate int) *rateLimiter {
+	return &rateLimiter{rate: rate, buckets: make(map[string]*rateBucket)}
+}
+
+// allow takes a token from client's bucket, reporting false when it is empty.
+func (l *rateLimiter) allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &rateBucket{tokens: float64(l.rate), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(float64(l.rate), b.tokens+now.Sub(b.last).Minutes()*float64(l.rate))
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,21 @@
+package main
+
+// Concurrency cap for the render API
+
+// renderSlots holds one entry per render in progress; its capacity is the
+// most renders the API runs at once.
+type renderSlots chan struct{}
+
+// acquire takes a slot without waiting, reporting false when all are busy.
+func (s renderSlots) acquire() bool {
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s renderSlots) release() {
+	<-s
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/joeblew999/deck-test/pkg/toolchain"
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +28,7 @@ func (cfg *config) buildSelf(ctx context.Context, local string) (string, error)
 	}
 	fmt.Printf("Building decktool to %s\n", abs)
 	cmd := exec.CommandContext(ctx, cfg.goCmd, "build", "-o", abs, ".")
+	cfg.useEnv(cmd, envGo)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -42,6 +44,7 @@ func (cfg *config) installSelf(ctx context.Context, builtPath, local string) err
 	}
 	fmt.Println("Installing decktool into GOBIN")
 	cmd := exec.CommandContext(ctx, cfg.goCmd, "install", ".")
+	cfg.useEnv(cmd, envGo)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -105,7 +108,7 @@ func (cfg *config) writeCompletion(cmd *cobra.Command, shell, output string) err
 func detectShell() string {
 	env := strings.TrimSpace(os.Getenv("SHELL"))
 	if env == "" {
-		if out := strings.TrimSpace(runGoEnv("go", "SHELL")); out != "" {
+		if out := toolchain.GoEnv("go", "SHELL"); out != "" {
 			env = out
 		}
 	}
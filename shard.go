@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Distributed corpus rendering
+//
+// The coordinator (test --workers) splits the sorted corpus into one shard
+// per worker. Each worker - another decktool reached over ssh, a serve
+// daemon, or "local" - checks the data repos out at the coordinator's SHAs,
+// renders and measures its shard and returns the results as JSON; the
+// coordinator merges them into the usual report. Golden images stay with
+// the coordinator, so remote shards are not compared against them.
+
+type shardDeck struct {
+	Name       string         `json:"name"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+	Metrics    *renderMetrics `json:"metrics,omitempty"`
+}
+
+type shardResult struct {
+	Shard     int               `json:"shard"`
+	Of        int               `json:"of"`
+	Examples  int               `json:"examples"` // corpus size seen by the worker
+	Toolchain string            `json:"toolchain"`
+	Corpus    map[string]string `json:"corpus"` // data repo -> checked-out SHA
+	Decks     []shardDeck       `json:"decks"`
+}
+
+// parseShard parses "<i>/<n>" with 1 <= i <= n.
+func parseShard(s string) (shard, of int, err error) {
+	a, b, ok := strings.Cut(s, "/")
+	shard, err1 := strconv.Atoi(a)
+	of, err2 := strconv.Atoi(b)
+	if !ok || err1 != nil || err2 != nil || shard < 1 || shard > of {
+		return 0, 0, fmt.Errorf("invalid shard %q (want <i>/<n>, e.g. 2/4)", s)
+	}
+	return shard, of, nil
+}
+
+// corpusPins returns the SHA of every checked-out data repo, preferring
+// lockfile pins, so workers render exactly the coordinator's corpus.
+func (cfg *config) corpusPins(ctx context.Context) (map[string]string, error) {
+	lock, err := loadLockFile()
+	if err != nil {
+		return nil, err
+	}
+	pins := make(map[string]string)
+	for name, repo := range cfg.repos {
+		if !repo.isData {
+			continue
+		}
+		if pin, ok := lock.Repos[name]; ok {
+			pins[name] = pin.SHA
+		} else if sha, err := cfg.gitHead(ctx, repo); err == nil {
+			pins[name] = sha
+		}
+	}
+	return pins, nil
+}
+
+// encodePins renders pins as repeated repo@sha values (flags, query).
+func encodePins(pins map[string]string) []string {
+	var out []string
+	for name, sha := range pins {
+		out = append(out, name+"@"+sha)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func decodePins(values []string) (map[string]string, error) {
+	pins := make(map[string]string)
+	for _, v := range values {
+		name, sha, ok := strings.Cut(v, "@")
+		if !ok || name == "" || !commitPattern.MatchString(sha) {
+			return nil, fmt.Errorf("invalid pin %q (want <repo>@<40-hex sha>)", v)
+		}
+		pins[name] = sha
+	}
+	return pins, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Coordinator side of distributed corpus rendering: hand shards to workers
+// and merge what they return
+
+// fetchShard runs one shard on a worker: "local", ssh://host[/dir] (a
+// decktool clone there, run with go run) or http(s)://host:port (serve,
+// authenticated with DECKTOOL_WORKER_TOKEN).
+func (cfg *config) fetchShard(ctx context.Context, worker string, shard, of int, pins map[string]string) (*shardResult, error) {
+	switch {
+	case worker == "local":
+		return cfg.renderShard(ctx, shard, of, pins)
+	case strings.HasPrefix(worker, "ssh://"):
+		rb, err := parseRemote(worker)
+		if err != nil {
+			return nil, err
+		}
+		script := fmt.Sprintf("cd %s && go run . test shard %d/%d", shellQuote(rb.dir), shard, of)
+		for _, pin := range encodePins(pins) {
+			script += " --pin " + shellQuote(pin)
+		}
+		cmd := rb.command(ctx, script)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("ssh %s: %w", rb.host, err)
+		}
+		var result shardResult
+		if err := json.Unmarshal(out, &result); err != nil {
+			return nil, fmt.Errorf("parse shard from %s: %w", rb.host, err)
+		}
+		return &result, nil
+	case strings.HasPrefix(worker, "http://"), strings.HasPrefix(worker, "https://"):
+		q := url.Values{"shard": {fmt.Sprintf("%d/%d", shard, of)}, "pin": encodePins(pins)}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(worker, "/")+"/corpus/shard?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if token := os.Getenv("DECKTOOL_WORKER_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return nil, fmt.Errorf("%s: %s: %s", worker, resp.Status, strings.TrimSpace(string(body)))
+		}
+		var result shardResult
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, fmt.Errorf("parse shard from %s: %w", worker, err)
+		}
+		return &result, nil
+	}
+	return nil, fmt.Errorf("unknown worker %q (want local, ssh://host[/dir] or http(s)://host:port)", worker)
+}
+
+// distributedCorpus renders the corpus across workers and merges their
+// results. A failed worker's shard is rendered locally instead.
+func (cfg *config) distributedCorpus(ctx context.Context, workers []string) ([]deckTestResult, error) {
+	pins, err := cfg.corpusPins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	toolchain := cfg.toolchainID()
+	shards := make([]*shardResult, len(workers))
+	errs := make([]error, len(workers))
+	var wg sync.WaitGroup
+	for i, worker := range workers {
+		if worker == "local" {
+			continue // rendered below, not concurrently with the others' fallbacks
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shards[i], errs[i] = cfg.fetchShard(ctx, worker, i+1, len(workers), pins)
+		}()
+	}
+	wg.Wait()
+
+	var results []deckTestResult
+	examples := -1
+	for i, worker := range workers {
+		if worker == "local" || errs[i] != nil {
+			if errs[i] != nil {
+				fmt.Printf("⚠ Worker %s failed: %v; rendering shard %d/%d locally\n", worker, errs[i], i+1, len(workers))
+				worker = "local"
+			}
+			if shards[i], err = cfg.renderShard(ctx, i+1, len(workers), pins); err != nil {
+				return nil, fmt.Errorf("shard %d/%d: %w", i+1, len(workers), err)
+			}
+		}
+		shard := shards[i]
+		fmt.Printf("✓ Shard %d/%d from %s: %d decks\n", i+1, len(workers), worker, len(shard.Decks))
+		if shard.Toolchain != toolchain {
+			fmt.Printf("≠ %s renders with toolchain %s, this machine with %s\n", worker, shard.Toolchain, toolchain)
+		}
+		for name, sha := range pins {
+			if shard.Corpus[name] != sha {
+				fmt.Printf("≠ %s has %s at %s, expected %s\n", worker, name, shortSHA(shard.Corpus[name]), shortSHA(sha))
+			}
+		}
+		if examples >= 0 && shard.Examples != examples {
+			fmt.Printf("≠ %s sees %d examples, other workers %d; shards may overlap or miss decks\n", worker, shard.Examples, examples)
+		}
+		examples = shard.Examples
+		for _, deck := range shard.Decks {
+			r := deckTestResult{name: deck.Name, metrics: deck.Metrics, duration: time.Duration(deck.DurationMS) * time.Millisecond}
+			if deck.Error != "" {
+				r.err = errors.New(deck.Error)
+			}
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].name < results[j].name })
+	return results, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+)
+
+// Worker side of distributed corpus rendering: render one shard at the
+// coordinator's pins, locally or for POST /corpus/shard
+
+// renderShard checks the data repos out at pins, in worktrees of the
+// shard's own (see shardworkspace.go), and renders every of-th example of the
+// sorted corpus, starting at the shard-th.
+func (cfg *config) renderShard(ctx context.Context, shard, of int, pins map[string]string) (*shardResult, error) {
+	if err := cfg.ensureBins(ctx); err != nil {
+		return nil, err
+	}
+	sc, cleanup, err := cfg.shardConfig(ctx, pins)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return sc.renderShardExamples(ctx, shard, of)
+}
+
+// renderShardExamples renders the shard's examples with the checkouts as
+// they are.
+func (cfg *config) renderShardExamples(ctx context.Context, shard, of int) (*shardResult, error) {
+	examples, err := cfg.listExamples()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(examples)
+	result := &shardResult{Shard: shard, Of: of, Examples: len(examples), Toolchain: cfg.toolchainID(), Corpus: make(map[string]string)}
+	for name, repo := range cfg.repos {
+		if !repo.isData {
+			continue
+		}
+		if sha, err := cfg.gitHead(ctx, repo); err == nil {
+			result.Corpus[name] = sha
+		}
+	}
+	for i, example := range examples {
+		if i%of != shard-1 {
+			continue
+		}
+		start := time.Now()
+		xmlPath, err := cfg.renderExample(ctx, example)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // directory without a deck of the same name
+		}
+		r := deckTestResult{name: example, xml: xmlPath, err: err, duration: time.Since(start)}
+		if r.err == nil {
+			r.err = cfg.propertyError(r.name, r.xml)
+		}
+		if r.err == nil {
+			r.err = cfg.measureOutput(ctx, &r)
+		}
+		deck := shardDeck{Name: r.name, DurationMS: r.duration.Milliseconds(), Metrics: r.metrics}
+		if r.err != nil {
+			deck.Error = r.err.Error()
+		}
+		result.Decks = append(result.Decks, deck)
+	}
+	return result, nil
+}
+
+// handleShard serves POST /corpus/shard?shard=i/n&pin=repo@sha for a
+// coordinator.
+func (cfg *config) handleShard(w http.ResponseWriter, r *http.Request) {
+	shard, of, err := parseShard(r.URL.Query().Get("shard"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pins, err := decodePins(r.URL.Query()["pin"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := cfg.renderShard(r.Context(), shard, of, pins)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Shard workspaces: each shard renders in its own detached worktrees of the
+// data repos, so a serve daemon's checkouts never move under the renders and
+// jobs it runs meanwhile, and shards side by side do not share output files.
+//
+//	.src/.worktrees/.shards/shard-<random>/<repo>   one worktree per data repo
+//	.src/.worktrees/.shards/.locks/<repo>.lock      serialises fetches per repo
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// shardConfig returns a copy of cfg whose data repos are worktrees at pins
+// (unpinned repos at their checked-out HEAD), and a cleanup that removes them.
+func (cfg *config) shardConfig(ctx context.Context, pins map[string]string) (*config, func(), error) {
+	for name := range pins {
+		if repo, ok := cfg.repos[name]; !ok || !repo.isData {
+			return nil, nil, fmt.Errorf("unknown data repo %q", name)
+		}
+	}
+	root, err := absPath(filepath.Join(worktreeRoot(), ".shards"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, nil, err
+	}
+	dir, err := os.MkdirTemp(root, "shard-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shard := *cfg
+	shard.repos = make(map[string]*repoConfig, len(cfg.repos))
+	var added []*repoConfig
+	cleanup := func() {
+		for _, repo := range added {
+			cfg.runGit(context.Background(), "-C", repo.dir, "worktree", "remove", "--force", filepath.Join(dir, repo.name))
+		}
+		os.RemoveAll(dir)
+	}
+	for name, repo := range cfg.repos {
+		shard.repos[name] = repo
+		if !repo.isData {
+			continue
+		}
+		worktree := filepath.Join(dir, name)
+		err := withLock(filepath.Join(root, ".locks"), name, func() error {
+			sha, err := cfg.fetchShardCommit(ctx, repo, pins[name])
+			if err != nil {
+				return err
+			}
+			return cfg.runGit(ctx, "-C", repo.dir, "worktree", "add", "--quiet", "--detach", worktree, sha)
+		})
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		added = append(added, repo)
+		copied := *repo
+		copied.dir = worktree
+		shard.repos[name] = &copied
+		if repo == cfg.fontsRepo {
+			shard.fontsRepo, shard.fontsDir = &copied, worktree
+		}
+	}
+	return &shard, cleanup, nil
+}
+
+// fetchShardCommit makes sha available in repo's primary clone without
+// touching its checkout, cloning it first if needed. An empty sha means the
+// clone's HEAD.
+func (cfg *config) fetchShardCommit(ctx context.Context, repo *repoConfig, sha string) (string, error) {
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+		if err := cfg.gitClone(ctx, repo); err != nil {
+			return "", err
+		}
+	}
+	if sha == "" {
+		return cfg.gitHead(ctx, repo)
+	}
+	if cfg.gitCommand(ctx, "-C", repo.dir, "cat-file", "-e", sha+"^{commit}").Run() == nil {
+		return sha, nil
+	}
+	args := []string{"-C", repo.dir, "fetch", "--quiet"}
+	if repo.depth > 0 && !cfg.usesMirror(repo) {
+		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+	}
+	args = append(args, repo.filter...)
+	return sha, cfg.runGit(ctx, append(args, "origin", sha)...)
+}
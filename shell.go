@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Toolchain subshell: dist on PATH via plain-name shims, DECKFONTS set
+
+const shellPromptLabel = "(decktool) "
+
+// ensureShims links plain tool names (decksh) to the platform binaries in dist
+// (decksh-linux-amd64) so the toolchain can be used directly from a shell.
+func (cfg *config) ensureShims() (string, error) {
+	dir := cfg.getShimDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create shim dir: %w", err)
+	}
+	for _, spec := range cfg.toolchain {
+		target := filepath.Join(cfg.distDir, cfg.buildFilename(spec.name, targetNative))
+		if _, err := os.Stat(target); err != nil {
+			continue
+		}
+		if runtime.GOOS == "windows" {
+			shim := fmt.Sprintf("@\"%s\" %%*\r\n", target)
+			if err := os.WriteFile(filepath.Join(dir, spec.name+".cmd"), []byte(shim), 0755); err != nil {
+				return "", err
+			}
+			continue
+		}
+		link := filepath.Join(dir, spec.name)
+		if err := os.Remove(link); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if err := os.Symlink(target, link); err != nil {
+			return "", fmt.Errorf("link %s: %w", spec.name, err)
+		}
+	}
+	return dir, nil
+}
+
+// runToolchainShell starts the user's shell with the toolchain environment
+// and a prompt marker, returning when the shell exits.
+func (cfg *config) runToolchainShell(ctx context.Context) error {
+	if os.Getenv("DECKTOOL_SHELL") != "" {
+		return errors.New("already inside a decktool shell")
+	}
+	shimDir, err := cfg.ensureShims()
+	if err != nil {
+		return err
+	}
+
+	shellPath := os.Getenv("SHELL")
+	if runtime.GOOS == "windows" {
+		shellPath = getenvDefault("COMSPEC", "cmd.exe")
+	}
+	if shellPath == "" {
+		shellPath = "/bin/sh"
+	}
+
+	tmp, err := os.MkdirTemp("", "decktool-shell-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// The shim dir goes first on PATH so the plain tool names resolve there
+	env := []string{"PATH=" + shimDir + string(os.PathListSeparator) + os.Getenv("PATH"), "DECKTOOL_SHELL=1"}
+	var args []string
+	switch filepath.Base(shellPath) {
+	case "bash":
+		rc := filepath.Join(tmp, "bashrc")
+		content := fmt.Sprintf("[ -f ~/.bashrc ] && . ~/.bashrc\nPS1=%q\"$PS1\"\n", shellPromptLabel)
+		if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+			return err
+		}
+		args = []string{"--rcfile", rc, "-i"}
+	case "zsh":
+		home, _ := os.UserHomeDir()
+		zdot := getenvDefault("ZDOTDIR", home)
+		content := fmt.Sprintf("[ -f %q ] && source %q\nPROMPT=%q\"$PROMPT\"\n",
+			filepath.Join(zdot, ".zshrc"), filepath.Join(zdot, ".zshrc"), shellPromptLabel)
+		if err := os.WriteFile(filepath.Join(tmp, ".zshrc"), []byte(content), 0644); err != nil {
+			return err
+		}
+		env = append(env, "ZDOTDIR="+tmp)
+	case "fish":
+		args = []string{"-C", fmt.Sprintf("functions -c fish_prompt _decktool_prompt; function fish_prompt; echo -n %q; _decktool_prompt; end", shellPromptLabel)}
+	default:
+		env = append(env, "PS1="+shellPromptLabel+"$ ")
+	}
+
+	fmt.Printf("Entering decktool shell (%s on PATH, DECKFONTS=%s). Type 'exit' to leave.\n", shimDir, cfg.fontsDir)
+	cmd := exec.CommandContext(ctx, shellPath, args...)
+	cfg.useEnv(cmd, envShell, env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil // the shell's own exit status is the user's business
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Release signing with trust-on-first-use pinning of the signer
+//
+// dev release signs the provenance with an ed25519 key and uploads the
+// signature next to it. The first verified download records the signer's
+// key per release repository in ~/.decktool/trust.json; a release later
+// signed by a different key is refused until explicitly accepted (see
+// trust.go).
+
+const signatureFile = provenanceFile + ".sig"
+
+type releaseSignature struct {
+	PublicKey string `json:"public_key"` // base64 ed25519 public key
+	Signature string `json:"signature"`  // base64 signature over the provenance file
+}
+
+func keyFingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "ed25519:" + hex.EncodeToString(sum[:16])
+}
+
+func decktoolHomePath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".decktool", name), nil
+}
+
+// loadSigningKey reads the release signing key (DECKTOOL_SIGNING_KEY or
+// ~/.decktool/signing.key), generating one on first release.
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	path := os.Getenv("DECKTOOL_SIGNING_KEY")
+	if path == "" {
+		var err error
+		if path, err = decktoolHomePath("signing.key"); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createSigningKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: not a PEM private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an ed25519 key", path)
+	}
+	return priv, nil
+}
+
+func createSigningKey(path string) (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, err
+	}
+	fmt.Printf("⚠ Generated release signing key %s (%s)\n", path, keyFingerprint(priv.Public().(ed25519.PublicKey)))
+	fmt.Println("  Back it up: users pin this key on first download and will refuse releases signed by another.")
+	return priv, nil
+}
+
+// signProvenance writes the signature for the provenance at path and returns its path.
+func signProvenance(path string) (string, error) {
+	priv, err := loadSigningKey()
+	if err != nil {
+		return "", fmt.Errorf("signing key: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sig := releaseSignature{
+		PublicKey: base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)),
+	}
+	out, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	sigPath := filepath.Join(filepath.Dir(path), signatureFile)
+	return sigPath, os.WriteFile(sigPath, append(out, '\n'), 0o644)
+}
+
+// verifyProvenanceSignature checks the signature in dir and returns the signer's key.
+func verifyProvenanceSignature(dir string) (ed25519.PublicKey, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, signatureFile))
+	if err != nil {
+		return nil, err
+	}
+	var sig releaseSignature
+	if err := json.Unmarshal(raw, &sig); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", signatureFile, err)
+	}
+	pub, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%s: invalid public key", signatureFile)
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid signature encoding", signatureFile)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, provenanceFile))
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, data, signature) {
+		return nil, errors.New("provenance signature is invalid")
+	}
+	return pub, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Incremental corpus testing: remember the inputs and toolchain each example
+// last passed with, so "test --changed-only" re-renders only what changed
+
+// snapshotTools are the binaries whose change invalidates every pass.
+var snapshotTools = []string{"dshlint", "decksh", "pdfdeck", "pngdeck"}
+
+type testSnapshot struct {
+	Examples map[string]snapshotEntry `json:"examples"`
+}
+
+// snapshotEntry is an example's last passing run.
+type snapshotEntry struct {
+	Inputs    string         `json:"inputs"`    // render cache key of the example directory
+	Toolchain string         `json:"toolchain"` // snapshotToolchain at the time
+	Metrics   *renderMetrics `json:"metrics,omitempty"`
+}
+
+func (cfg *config) loadTestSnapshot() (testSnapshot, error) {
+	snapshot := testSnapshot{Examples: make(map[string]snapshotEntry)}
+	data, err := os.ReadFile(cfg.getTestSnapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("parse %s: %w", cfg.getTestSnapshotPath(), err)
+	}
+	if snapshot.Examples == nil {
+		snapshot.Examples = make(map[string]snapshotEntry)
+	}
+	return snapshot, nil
+}
+
+func (cfg *config) saveTestSnapshot(snapshot testSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.getTestSnapshotPath()), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.getTestSnapshotPath(), append(data, '\n'), 0o644)
+}
+
+// snapshotToolchain fingerprints the lint, render and conversion binaries.
+func (cfg *config) snapshotToolchain() (string, error) {
+	h := sha256.New()
+	for _, tool := range snapshotTools {
+		path, err := cfg.resolveBinary(tool)
+		if err != nil {
+			return "", err
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", tool, sum)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// exampleInputs returns the render cache key of an example's directory.
+func (cfg *config) exampleInputs(example string) (string, error) {
+	source, name := cfg.parseExample(example)
+	dir, err := cfg.getExampleDir(source, name)
+	if err != nil {
+		return "", err
+	}
+	return cfg.renderCacheKey(dir)
+}
+
+// unchangedSince reports whether example passed its last run with the same
+// inputs and toolchain, and why not otherwise.
+func (s testSnapshot) unchangedSince(example, inputs, toolchain string) (bool, string) {
+	entry, ok := s.Examples[example]
+	switch {
+	case !ok:
+		return false, "not passed before"
+	case entry.Inputs != inputs:
+		return false, "inputs changed"
+	case entry.Toolchain != toolchain:
+		return false, "toolchain changed"
+	}
+	return true, ""
+}
+
+// updateTestSnapshot records the corpus results of a run: passes with their
+// inputs, failures are dropped so the next --changed-only run retries them.
+func (cfg *config) updateTestSnapshot(results []deckTestResult) error {
+	var corpus []deckTestResult
+	for _, r := range results {
+		if r.inputs != "" && !r.skipped {
+			corpus = append(corpus, r)
+		}
+	}
+	if len(corpus) == 0 {
+		return nil
+	}
+	toolchain, err := cfg.snapshotToolchain()
+	if err != nil {
+		return err
+	}
+	snapshot, err := cfg.loadTestSnapshot()
+	if err != nil {
+		return err
+	}
+	for _, r := range corpus {
+		if r.err != nil {
+			delete(snapshot.Examples, r.name)
+			continue
+		}
+		snapshot.Examples[r.name] = snapshotEntry{Inputs: r.inputs, Toolchain: toolchain, Metrics: r.metrics}
+	}
+	return cfg.saveTestSnapshot(snapshot)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Snippet library: reusable decksh include files (title layouts, chart
+// styles) shared by decks. A deck includes one as
+//
+//	include "snippets/<name>.dsh"
+//
+// and renderDeck points the include at the library unless the deck's
+// directory has its own snippets/<name>.dsh.
+
+const snippetIncludeDir = "snippets"
+
+var snippetIncludeRE = regexp.MustCompile(`^(\s*include\s+")snippets/([^"/]+)\.dsh(".*)$`)
+
+type snippet struct {
+	Name        string
+	Description string // the file's first comment line
+	Path        string
+}
+
+func (cfg *config) getSnippetPath(name string) string {
+	return filepath.Join(cfg.snippetsDir, name+".dsh")
+}
+
+// snippetName validates a library name: a file name without .dsh.
+func snippetName(name string) (string, error) {
+	name = strings.TrimSuffix(name, ".dsh")
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `"\`) {
+		return "", fmt.Errorf("invalid snippet name %q", name)
+	}
+	return name, nil
+}
+
+func (cfg *config) listSnippets() ([]snippet, error) {
+	entries, err := os.ReadDir(cfg.snippetsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snippets []snippet
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".dsh" {
+			continue
+		}
+		s := snippet{Name: strings.TrimSuffix(e.Name(), ".dsh"), Path: filepath.Join(cfg.snippetsDir, e.Name())}
+		s.Description = snippetDescription(s.Path)
+		snippets = append(snippets, s)
+	}
+	sort.Slice(snippets, func(i, j int) bool { return snippets[i].Name < snippets[j].Name })
+	return snippets, nil
+}
+
+// snippetDescription returns the first // comment of a snippet file.
+func snippetDescription(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "//") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "//"))
+		}
+		if line != "" {
+			break
+		}
+	}
+	return ""
+}
+
+// addSnippet copies a decksh file into the library.
+func (cfg *config) addSnippet(src, name string, force bool) (string, error) {
+	if name == "" {
+		name = filepath.Base(src)
+	}
+	name, err := snippetName(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	dest := cfg.getSnippetPath(name)
+	if _, err := os.Stat(dest); err == nil && !force {
+		return "", fmt.Errorf("snippet %q already exists (use --force to replace it)", name)
+	}
+	if err := os.MkdirAll(cfg.snippetsDir, 0o755); err != nil {
+		return "", err
+	}
+	return name, os.WriteFile(dest, data, 0o644)
+}
+
+func snippetIncludeLine(name string) string {
+	return fmt.Sprintf(`include "%s/%s.dsh"`, snippetIncludeDir, name)
+}
+
+// insertSnippetInclude adds the include line for name to a deck script,
+// after its deck line (or first, if it has none). A script that already
+// includes the snippet is left alone.
+func insertSnippetInclude(script []byte, name string) ([]byte, bool) {
+	include := snippetIncludeLine(name)
+	lines := strings.SplitAfter(string(script), "\n")
+	at := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == include {
+			return script, false
+		}
+		if at == 0 && trimmed == "deck" {
+			at = i + 1
+		}
+	}
+	lines = append(lines[:at], append([]string{include + "\n"}, lines[at:]...)...)
+	return []byte(strings.Join(lines, "")), true
+}
+
+// resolveSnippetIncludes rewrites a script's library includes to absolute
+// paths. It reports false, with no error, for scripts without any.
+func (cfg *config) resolveSnippetIncludes(dir, script string) ([]byte, bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, script))
+	if err != nil {
+		return nil, false, err
+	}
+	if !bytes.Contains(data, []byte(snippetIncludeDir+"/")) {
+		return nil, false, nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	changed := false
+	for i, line := range lines {
+		m := snippetIncludeRE.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if m == nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, snippetIncludeDir, m[2]+".dsh")); err == nil {
+			continue // the deck's own copy wins
+		}
+		path := cfg.getSnippetPath(m[2])
+		if _, err := os.Stat(path); err != nil {
+			return nil, false, fmt.Errorf("%s:%d: snippet %q is not in the library (see decktool snippets list)", script, i+1, m[2])
+		}
+		lines[i] = m[1] + path + m[3] + line[len(strings.TrimRight(line, "\r\n")):]
+		changed = true
+	}
+	return []byte(strings.Join(lines, "")), changed, nil
+}
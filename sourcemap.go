@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source mapping: point a failed render or conversion at the .dsh line it
+// came from. decksh reports script lines, shifted when decktool piped in a
+// rewritten script (suite variables); converters report positions in the
+// XML, whose element is matched back to the script by the strings it holds.
+// Failing that, quoted strings and file names in the error are looked up in
+// the script.
+
+// sourceLocation is a script line and how it was found.
+type sourceLocation struct {
+	line int
+	how  string
+}
+
+var (
+	xmlLineRE    = regexp.MustCompile(`[Ll]ine (\d+)`)
+	xmlValueRE   = regexp.MustCompile(`="([^"]{2,})"|>([^<]{2,})<`)
+	errorTokenRE = regexp.MustCompile(`"([^"]{2,})"|'([^']{2,})'|([\w./-]+\.(?:png|jpe?g|gif|svg|csv|d|tsv|txt|dsh|ttf|otf))\b`)
+)
+
+// locateScriptLine maps tool output to a line of script. offset is the
+// number of lines decktool prepended to the script it piped to decksh; xml
+// is the rendered deck when a converter failed, nil for decksh itself.
+func locateScriptLine(script []byte, name, output string, offset int, xml []byte) (sourceLocation, bool) {
+	lines := strings.Split(strings.TrimRight(string(script), "\n"), "\n")
+	if xml == nil {
+		if n := failureLine(output, name); n > 0 {
+			if n -= offset; n >= 1 && n <= len(lines) {
+				return sourceLocation{n, "reported by decksh"}, true
+			}
+		}
+	} else if m := xmlLineRE.FindStringSubmatch(output); m != nil {
+		xmlLines := strings.Split(string(xml), "\n")
+		if n, _ := strconv.Atoi(m[1]); n >= 1 && n <= len(xmlLines) {
+			for _, v := range xmlValueRE.FindAllStringSubmatch(xmlLines[n-1], -1) {
+				value := strings.TrimSpace(v[1] + v[2])
+				if i := findInScript(lines, value); i > 0 {
+					return sourceLocation{i, fmt.Sprintf("XML line %d holds %q", n, value)}, true
+				}
+			}
+		}
+	}
+	for _, m := range errorTokenRE.FindAllStringSubmatch(output, -1) {
+		token := m[1] + m[2] + m[3]
+		if token == name {
+			continue
+		}
+		if i := findInScript(lines, token); i > 0 {
+			return sourceLocation{i, fmt.Sprintf("the error names %q", token)}, true
+		}
+	}
+	return sourceLocation{}, false
+}
+
+// findInScript returns the first line (1-based) containing s outside a
+// comment, or 0.
+func findInScript(lines []string, s string) int {
+	if len(s) < 2 {
+		return 0
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		if strings.Contains(line, s) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// annotateToolError points err at the script line the output leads to,
+// printing the lines around it to w. err is returned unchanged when no line
+// can be found.
+func annotateToolError(w io.Writer, err error, scriptPath, output string, offset int, xml []byte) error {
+	script, rerr := os.ReadFile(scriptPath)
+	if rerr != nil {
+		return err
+	}
+	name := filepath.Base(scriptPath)
+	loc, ok := locateScriptLine(script, name, output, offset, xml)
+	if !ok {
+		return err
+	}
+	fmt.Fprintf(w, "→ %s:%d (%s)\n", name, loc.line, loc.how)
+	printSourceContext(w, script, loc.line, 3)
+	return fmt.Errorf("%s:%d: %w", name, loc.line, err)
+}
+
+// printSourceContext prints the lines within radius of line, marking it.
+func printSourceContext(w io.Writer, script []byte, line, radius int) {
+	lines := strings.Split(strings.TrimRight(string(script), "\n"), "\n")
+	for i := max(line-radius, 1); i <= min(line+radius, len(lines)); i++ {
+		marker := " "
+		if i == line {
+			marker = "›"
+		}
+		fmt.Fprintf(w, "%s %4d  %s\n", marker, i, lines[i-1])
+	}
+}
+
+// lineOffset is how many lines a rewrite added ahead of the original.
+func lineOffset(original, rewritten []byte) int {
+	return max(bytes.Count(rewritten, []byte("\n"))-bytes.Count(original, []byte("\n")), 0)
+}
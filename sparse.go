@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// On-demand sparse checkouts: a repo marked sparseOnDemand (dubois, whose
+// plates run to gigabytes) is cloned with only its top-level files and the
+// shared directories checked out, and each example directory is added to
+// the sparse set the first time it is used, so disk usage grows with what
+// is rendered. DUBOIS_SPARSE fixes the set instead, DUBOIS_ON_DEMAND=0 turns
+// this off for new clones and DUBOIS_SHARED lists directories every plate
+// needs.
+
+// sparseEnabled reports whether the checkout is a sparse one.
+func (cfg *config) sparseEnabled(repo *repoConfig) bool {
+	out, err := exec.Command(cfg.gitCmd, "-C", repo.dir, "config", "--bool", "core.sparseCheckout").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// onDemand reports whether repo's example directories are checked out as
+// they are used.
+func (cfg *config) onDemand(repo *repoConfig) bool {
+	return repo.sparseOnDemand && cfg.sparseEnabled(repo)
+}
+
+// initOnDemand sets up the sparse set of a fresh on-demand clone.
+func (cfg *config) initOnDemand(ctx context.Context, repo *repoConfig) error {
+	if len(repo.shared) == 0 {
+		return nil
+	}
+	args := append([]string{"-C", repo.dir, "sparse-checkout", "add"}, repo.shared...)
+	if err := cfg.runGit(ctx, args...); err != nil {
+		return fmt.Errorf("%s: check out shared directories: %w", repo.name, err)
+	}
+	return nil
+}
+
+// materializeExample makes an example's files available: its repo is
+// cloned on first use and, for on-demand repos, its directory added to the
+// sparse checkout.
+func (cfg *config) materializeExample(ctx context.Context, raw string) error {
+	stdout, _ := toolOutput(ctx)
+	source, name := cfg.parseExample(raw)
+	if err := cfg.materializeSource(ctx, source); err != nil {
+		return err
+	}
+	repo, ok := cfg.repos[source]
+	if !ok || !cfg.onDemand(repo) {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, name)); err == nil {
+		return nil
+	}
+	// Only directories in the tree; a typo must not grow the sparse set
+	if cfg.gitCommand(ctx, "-C", repo.dir, "cat-file", "-e", "HEAD:"+name).Run() != nil {
+		return nil
+	}
+	fmt.Fprintf(stdout, "Checking out %s/%s on first use\n", source, name)
+	if err := cfg.runGit(ctx, "-C", repo.dir, "sparse-checkout", "add", name); err != nil {
+		return fmt.Errorf("check out %s/%s: %w", source, name, err)
+	}
+	return nil
+}
+
+// treeExampleNames lists the example directories in an on-demand repo's
+// tree, checked out or not.
+func (cfg *config) treeExampleNames(repo *repoConfig) []string {
+	out, err := exec.Command(cfg.gitCmd, "-C", repo.dir, "ls-tree", "-d", "--name-only", "HEAD").Output()
+	if err != nil {
+		return collectExampleNames(repo.dir)
+	}
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if name != "" && !strings.HasPrefix(name, ".") && !slices.Contains(repo.shared, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Environment state export/import for reproducing another user's setup
+//
+// The state file records the repos (URL, branch, checked-out SHA), the
+// toolchain binaries' digests, the project config and lockfile, and the
+// recent decktool commands run in the project. Import pins the data repos
+// to the recorded SHAs, restores the config, and reports where the local
+// toolchain differs. Secrets (serve tokens, OTLP headers, signing keys) are
+// never exported. Import is in stateimport.go.
+
+const stateVersion = 1
+
+type envState struct {
+	Version   int                   `json:"version"`
+	Exported  time.Time             `json:"exported"`
+	Host      hostState             `json:"host"`
+	Repos     map[string]lockedRepo `json:"repos"`
+	Toolchain map[string]string     `json:"toolchain"`          // binary name -> sha256
+	Config    json.RawMessage       `json:"config,omitempty"`   // decktool.json
+	Lock      json.RawMessage       `json:"lockfile,omitempty"` // decktool.lock
+	History   []historyEntry        `json:"history"`
+}
+
+type hostState struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Decktool string `json:"decktool"` // module version or VCS revision
+}
+
+func decktoolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return info.Main.Version
+}
+
+// exportState collects the current environment.
+func (cfg *config) exportState(ctx context.Context, historySize int) (*envState, error) {
+	st := &envState{
+		Version:   stateVersion,
+		Exported:  time.Now().UTC(),
+		Host:      hostState{OS: runtime.GOOS, Arch: runtime.GOARCH, Decktool: decktoolVersion()},
+		Repos:     make(map[string]lockedRepo),
+		Toolchain: make(map[string]string),
+	}
+	for name, repo := range cfg.repos {
+		pin := lockedRepo{URL: repo.url, Branch: repo.branch}
+		if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err == nil {
+			pin.SHA, _ = cfg.gitHead(ctx, repo)
+		}
+		st.Repos[name] = pin
+	}
+	for _, spec := range cfg.toolchain {
+		path, err := cfg.resolveBinary(spec.name)
+		if err != nil {
+			continue
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil, err
+		}
+		st.Toolchain[spec.name] = sum
+	}
+	for file, dest := range map[string]*json.RawMessage{configFile: &st.Config, lockFileName: &st.Lock} {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("%s is not valid JSON", file)
+		}
+		*dest = json.RawMessage(data)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if st.History, err = recentHistory(wd, historySize); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
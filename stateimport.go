@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Importing an exported environment state
+
+func loadState(path string) (*envState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st envState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if st.Version != stateVersion {
+		return nil, fmt.Errorf("%s: unsupported state version %d", path, st.Version)
+	}
+	return &st, nil
+}
+
+// importState recreates st in the current directory: it restores the config
+// and lockfile, points the repos at the recorded URLs and branches, pins
+// the data repos to the recorded SHAs and compares the toolchain.
+func (cfg *config) importState(ctx context.Context, st *envState, force bool) error {
+	for file, data := range map[string]json.RawMessage{configFile: st.Config, lockFileName: st.Lock} {
+		if len(data) == 0 {
+			continue
+		}
+		if _, err := os.Stat(file); err == nil && !force {
+			return fmt.Errorf("%s already exists; use --force to replace it", file)
+		}
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Restored %s\n", file)
+	}
+	fc, err := loadFileConfig(configFile)
+	if err != nil {
+		return err
+	}
+	cfg.file = fc
+
+	lock, err := loadLockFile()
+	if err != nil {
+		return err
+	}
+	var exports []string
+	for name, pin := range st.Repos {
+		repo, ok := cfg.repos[name]
+		if !ok {
+			fmt.Printf("⚠ Unknown repo %s in state file, skipped\n", name)
+			continue
+		}
+		env := strings.ToUpper(name)
+		if name == "deckfonts" {
+			env = "DECKFONTS"
+		}
+		if pin.URL != "" && pin.URL != repo.url {
+			repo.url = pin.URL
+			exports = append(exports, fmt.Sprintf("export %s_REPO=%s", env, pin.URL))
+		}
+		if pin.Branch != "" && pin.Branch != repo.branch {
+			repo.branch = pin.Branch
+			exports = append(exports, fmt.Sprintf("export %s_BRANCH=%s", env, pin.Branch))
+		}
+		if repo.isData && pin.SHA != "" {
+			lock.Repos[name] = pin
+		}
+	}
+	if err := lock.save(); err != nil {
+		return err
+	}
+	if err := cfg.ensureBins(ctx); err != nil {
+		return err
+	}
+	if err := cfg.ensurePinnedRepos(ctx, lock); err != nil {
+		return err
+	}
+
+	mismatched := 0
+	var names []string
+	for name := range st.Toolchain {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path, err := cfg.resolveBinary(name)
+		if err != nil {
+			fmt.Printf("⚠ %s: not installed here\n", name)
+			mismatched++
+			continue
+		}
+		if sum, err := fileSHA256(path); err != nil || sum != st.Toolchain[name] {
+			fmt.Printf("⚠ %s differs from the exported toolchain (%s)\n", name, shortSHA(st.Toolchain[name]))
+			mismatched++
+		}
+	}
+	if mismatched == 0 {
+		fmt.Println("✓ Toolchain matches the exported environment")
+	}
+	if len(exports) > 0 {
+		sort.Strings(exports)
+		fmt.Println("\nThe exported environment used other repos; keep using them with:")
+		for _, line := range exports {
+			fmt.Println("  " + line)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit statuses: report a test run against an upstream commit on GitHub
+
+const statusContext = "decktool/corpus"
+
+type commitStatusTarget struct {
+	repo, owner, name, sha string
+}
+
+// resolveStatusTarget parses <repo>[@<ref>] and resolves the commit in the
+// repo's checkout. Statuses go to the repo's configured URL (a fork when
+// one is set).
+func (cfg *config) resolveStatusTarget(ctx context.Context, arg string) (*commitStatusTarget, error) {
+	name, ref, _ := strings.Cut(arg, "@")
+	repo, ok := cfg.repos[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown repo %q (see decktool repos)", name)
+	}
+	owner, ghRepo, ok := githubRepo(repo.url)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a GitHub repo (%s)", name, repo.url)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	sha, err := cfg.gitOutput(ctx, "-C", repo.dir, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s in %s: %w", ref, name, err)
+	}
+	return &commitStatusTarget{repo: name, owner: owner, name: ghRepo, sha: sha}, nil
+}
+
+// publishCommitStatus posts the run's outcome as a commit status.
+func (cfg *config) publishCommitStatus(ctx context.Context, target commitStatusTarget, summary deckTestSummary, runErr error, reportURL string) error {
+	state, description := "success", summary.String()
+	if runErr != nil {
+		state = "failure"
+		if summary.total == 0 {
+			state, description = "error", runErr.Error()
+		}
+	}
+	if len(description) > 140 { // GitHub's limit
+		description = description[:137] + "..."
+	}
+	args := []string{"api", "--silent", "-X", "POST",
+		fmt.Sprintf("repos/%s/%s/statuses/%s", target.owner, target.name, target.sha),
+		"-f", "state=" + state,
+		"-f", "context=" + statusContext,
+		"-f", "description=" + description,
+	}
+	if reportURL != "" {
+		args = append(args, "-f", "target_url="+reportURL)
+	}
+	if out, err := exec.CommandContext(ctx, "gh", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("gh api: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	fmt.Printf("✓ Published %s status %q on %s/%s@%s\n", state, description, target.owner, target.name, shortSHA(target.sha))
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// Style lint: project style rules over rendered decks, complementing
+// dshlint's syntax checks. A rule is a function over the parsed deck; add
+// one to styleRules (stylerules.go) to extend the engine.
+
+// styleConfig tunes the rules per project.
+type styleConfig struct {
+	Disable  []string            `json:"disable"`   // rules not run
+	Skip     map[string][]string `json:"skip"`      // rule -> deck patterns (path.Match) it ignores
+	MaxWords int                 `json:"max_words"` // words per slide (default 60)
+	MaxFonts int                 `json:"max_fonts"` // distinct fonts per deck (default 3)
+}
+
+func (c styleConfig) validate() error {
+	for _, name := range c.Disable {
+		if styleRuleByName(name) == nil {
+			return fmt.Errorf("style.disable: unknown rule %q", name)
+		}
+	}
+	for name, patterns := range c.Skip {
+		if styleRuleByName(name) == nil {
+			return fmt.Errorf("style.skip: unknown rule %q", name)
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("style.skip.%s: bad pattern %q", name, pattern)
+			}
+		}
+	}
+	if c.MaxWords < 0 || c.MaxFonts < 0 {
+		return errors.New("style.max_words and style.max_fonts must not be negative")
+	}
+	return nil
+}
+
+func (c styleConfig) maxWords() int {
+	if c.MaxWords > 0 {
+		return c.MaxWords
+	}
+	return 60
+}
+
+func (c styleConfig) maxFonts() int {
+	if c.MaxFonts > 0 {
+		return c.MaxFonts
+	}
+	return 3
+}
+
+// ruleApplies reports whether a rule runs for deck under a disable list and
+// per-rule skip patterns.
+func ruleApplies(disable []string, skip map[string][]string, rule, deck string) bool {
+	if slices.Contains(disable, rule) {
+		return false
+	}
+	for _, pattern := range skip[rule] {
+		if ok, _ := path.Match(pattern, deck); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// deckElement is one markup element with its attributes and text content.
+type deckElement struct {
+	tag   string
+	attrs map[string]string
+	text  string // character data, including list items
+}
+
+func (e deckElement) float(name string) (float64, bool) {
+	v, err := strconv.ParseFloat(e.attrs[name], 64)
+	return v, err == nil
+}
+
+// styledDeck is a rendered deck as the rules see it.
+type styledDeck struct {
+	dir    string // resolves relative image names
+	slides [][]deckElement
+}
+
+type styleFinding struct {
+	rule    string
+	slide   int // 0 for deck-wide findings
+	message string
+}
+
+func (f styleFinding) String() string {
+	if f.slide == 0 {
+		return fmt.Sprintf("[%s] %s", f.rule, f.message)
+	}
+	return fmt.Sprintf("[%s] slide %d: %s", f.rule, f.slide, f.message)
+}
+
+// parseStyledDeck reads a rendered deck's slides and their elements.
+func parseStyledDeck(xmlPath string) (*styledDeck, error) {
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	deck := &styledDeck{dir: filepath.Dir(xmlPath)}
+	var open []*deckElement // elements whose text is being collected
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(xmlPath), err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "slide" {
+				deck.slides = append(deck.slides, nil)
+				continue
+			}
+			if len(deck.slides) == 0 || t.Name.Local == "li" {
+				continue // deck-level markup; list items add to their list's text
+			}
+			el := deckElement{tag: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				el.attrs[attr.Name.Local] = attr.Value
+			}
+			slide := &deck.slides[len(deck.slides)-1]
+			*slide = append(*slide, el)
+			open = append(open, &(*slide)[len(*slide)-1])
+		case xml.CharData:
+			if len(open) > 0 {
+				open[len(open)-1].text += string(t) + " "
+			}
+		case xml.EndElement:
+			if t.Name.Local != "li" && t.Name.Local != "slide" && len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return deck, nil
+}
+
+// lintStyle runs the applicable rules over a rendered deck.
+func (cfg *config) lintStyle(deck, xmlPath string) ([]styleFinding, error) {
+	parsed, err := parseStyledDeck(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	style := cfg.file.Style
+	var findings []styleFinding
+	for _, rule := range styleRules {
+		if ruleApplies(style.Disable, style.Skip, rule.name, deck) {
+			findings = append(findings, rule.check(parsed, style)...)
+		}
+	}
+	return findings, nil
+}
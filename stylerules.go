@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Style rules: each checks a parsed deck against the project's styleConfig
+
+type styleRule struct {
+	name    string
+	summary string
+	check   func(deck *styledDeck, cfg styleConfig) []styleFinding
+}
+
+var styleRules = []styleRule{
+	{"text-overflow", "text estimated to run past the slide edge", checkTextOverflow},
+	{"words-per-slide", "slides with more words than max_words", checkWordsPerSlide},
+	{"fonts", "decks using more fonts than max_fonts", checkFonts},
+	{"image-stretch", "images scaled beyond their native resolution or distorted", checkImageStretch},
+}
+
+func styleRuleByName(name string) *styleRule {
+	for i := range styleRules {
+		if styleRules[i].name == name {
+			return &styleRules[i]
+		}
+	}
+	return nil
+}
+
+// checkTextOverflow estimates a line's width from its length and size
+// (average glyph width about 0.55 em) and flags text crossing the left or
+// right edge for its alignment. Wrapped text blocks (with wp) are skipped.
+func checkTextOverflow(deck *styledDeck, cfg styleConfig) []styleFinding {
+	var findings []styleFinding
+	for i, slide := range deck.slides {
+		for _, el := range slide {
+			if el.tag != "text" && el.tag != "list" {
+				continue
+			}
+			if _, wrapped := el.attrs["wp"]; wrapped {
+				continue
+			}
+			xp, okX := el.float("xp")
+			sp, okS := el.float("sp")
+			if !okX || !okS || sp <= 0 {
+				continue
+			}
+			longest := ""
+			for _, line := range strings.Split(el.text, "\n") {
+				if line = strings.TrimSpace(line); len(line) > len(longest) {
+					longest = line
+				}
+			}
+			width := float64(len([]rune(longest))) * sp * 0.55
+			left, right := xp, xp+width
+			switch el.attrs["align"] {
+			case "center", "c", "middle", "mid":
+				left, right = xp-width/2, xp+width/2
+			case "end", "e", "right":
+				left, right = xp-width, xp
+			}
+			if left < 0 || right > 100 {
+				findings = append(findings, styleFinding{"text-overflow", i + 1,
+					fmt.Sprintf("%q (%.0f%% wide at x=%g) runs past the edge", truncateText(longest, 30), width, xp)})
+			}
+		}
+	}
+	return findings
+}
+
+func checkWordsPerSlide(deck *styledDeck, cfg styleConfig) []styleFinding {
+	var findings []styleFinding
+	for i, slide := range deck.slides {
+		words := 0
+		for _, el := range slide {
+			if el.tag == "text" || el.tag == "list" {
+				words += len(strings.Fields(el.text))
+			}
+		}
+		if words > cfg.maxWords() {
+			findings = append(findings, styleFinding{"words-per-slide", i + 1, fmt.Sprintf("%d words (max %d)", words, cfg.maxWords())})
+		}
+	}
+	return findings
+}
+
+func checkFonts(deck *styledDeck, cfg styleConfig) []styleFinding {
+	var fonts []string
+	for _, slide := range deck.slides {
+		for _, el := range slide {
+			if font := el.attrs["font"]; font != "" && !slices.Contains(fonts, font) {
+				fonts = append(fonts, font)
+			}
+		}
+	}
+	if len(fonts) <= cfg.maxFonts() {
+		return nil
+	}
+	slices.Sort(fonts)
+	return []styleFinding{{"fonts", 0, fmt.Sprintf("%d fonts (max %d): %s", len(fonts), cfg.maxFonts(), strings.Join(fonts, ", "))}}
+}
+
+// checkImageStretch compares an image's drawn size (width x height at
+// scale percent) with the pixel size of its file.
+func checkImageStretch(deck *styledDeck, cfg styleConfig) []styleFinding {
+	var findings []styleFinding
+	for i, slide := range deck.slides {
+		for _, el := range slide {
+			name := el.attrs["name"]
+			if el.tag != "image" || name == "" || strings.Contains(name, "://") {
+				continue
+			}
+			w, okW := el.float("width")
+			h, okH := el.float("height")
+			if !okW || !okH || w <= 0 || h <= 0 {
+				continue
+			}
+			if scale, ok := el.float("scale"); ok && scale > 0 {
+				w, h = w*scale/100, h*scale/100
+			}
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(deck.dir, name)
+			}
+			native, err := imageSize(name)
+			if err != nil {
+				continue // missing images are a property check failure
+			}
+			nw, nh := float64(native.X), float64(native.Y)
+			switch {
+			case w > nw*1.05 || h > nh*1.05:
+				findings = append(findings, styleFinding{"image-stretch", i + 1,
+					fmt.Sprintf("%s drawn at %.0fx%.0f, native %dx%d", el.attrs["name"], w, h, native.X, native.Y)})
+			case math.Abs(w/h-nw/nh)/(nw/nh) > 0.05:
+				findings = append(findings, styleFinding{"image-stretch", i + 1,
+					fmt.Sprintf("%s drawn at %.0fx%.0f distorts its %dx%d aspect ratio", el.attrs["name"], w, h, native.X, native.Y)})
+			}
+		}
+	}
+	return findings
+}
+
+func imageSize(path string) (image.Point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Point{}, err
+	}
+	defer f.Close()
+	conf, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return image.Point{}, err
+	}
+	return image.Point{X: conf.Width, Y: conf.Height}, nil
+}
+
+func truncateText(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/deck-test/pkg/render"
+)
+
+// Run suites: named sets of examples with the options to render them
+// (output formats, decksh variables, environment) and the outcome each is
+// expected to have, kept in decktool.json so CI jobs and demos are
+// versioned with the project (decktool run --suite smoke).
+
+type suitesConfig []runSuite
+
+type runSuite struct {
+	Name     string            `json:"name"`
+	Examples []string          `json:"examples"`
+	Formats  []string          `json:"formats"` // converted outputs per example (default: XML only)
+	Vars     map[string]string `json:"vars"`    // decksh variables, e.g. {"title": "\"Q3\""}
+	Env      map[string]string `json:"env"`     // environment for the tools
+	Fail     []string          `json:"fail"`    // examples expected to fail
+}
+
+var deckVarRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (c suitesConfig) validate() error {
+	var names []string
+	for i, s := range c {
+		if s.Name == "" {
+			return fmt.Errorf("suites[%d]: name is required", i)
+		}
+		if slices.Contains(names, s.Name) {
+			return fmt.Errorf("suites: duplicate name %q", s.Name)
+		}
+		names = append(names, s.Name)
+		if len(s.Examples) == 0 {
+			return fmt.Errorf("suites.%s: examples is required", s.Name)
+		}
+		for _, format := range s.Formats {
+			if _, ok := render.Converters[format]; !ok {
+				return fmt.Errorf("suites.%s: unknown format %q", s.Name, format)
+			}
+		}
+		for name := range s.Vars {
+			if !deckVarRE.MatchString(name) {
+				return fmt.Errorf("suites.%s: %q is not a decksh variable name", s.Name, name)
+			}
+		}
+		for _, ex := range s.Fail {
+			if !slices.Contains(s.Examples, ex) {
+				return fmt.Errorf("suites.%s: fail lists %q, which is not one of its examples", s.Name, ex)
+			}
+		}
+	}
+	return nil
+}
+
+func (cfg *config) runSuite(name string) (runSuite, error) {
+	var names []string
+	for _, s := range cfg.file.Suites {
+		if s.Name == name {
+			return s, nil
+		}
+		names = append(names, s.Name)
+	}
+	if len(names) == 0 {
+		return runSuite{}, fmt.Errorf("no suite %q: %s defines no suites", name, configFile)
+	}
+	return runSuite{}, fmt.Errorf("no suite %q (suites: %s)", name, strings.Join(names, ", "))
+}
+
+// runSuiteExamples renders every example of a suite and compares each
+// outcome with the expected one. An example failing as expected passes the
+// suite; one that was expected to fail but rendered does not.
+func (cfg *config) runSuiteExamples(ctx context.Context, s runSuite) error {
+	for _, k := range slices.Sorted(maps.Keys(s.Env)) {
+		cfg.toolEnv = append(cfg.toolEnv, k+"="+s.Env[k])
+	}
+	cfg.deckVars = s.Vars
+	defer func() { cfg.deckVars, cfg.toolEnv = nil, nil }()
+
+	fmt.Printf("=== suite %s: %d example(s) ===\n", s.Name, len(s.Examples))
+	var examples, unexpected []string
+	for _, raw := range s.Examples {
+		examples = append(examples, cfg.normalizeExampleName(raw))
+	}
+	cfg.pipelineOutputs(ctx, examples, s.Formats, func(ctx context.Context, i int, outputs []string, err error) {
+		example := examples[i]
+		wantFail := slices.Contains(s.Fail, s.Examples[i])
+		out, _ := toolOutput(ctx)
+		switch {
+		case err != nil && wantFail:
+			fmt.Fprintf(out, "✓ %s failed as expected: %v\n", example, err)
+		case err != nil:
+			fmt.Fprintf(out, "✗ %s: %v\n", example, err)
+			unexpected = append(unexpected, example)
+		case wantFail:
+			fmt.Fprintf(out, "✗ %s rendered but is expected to fail\n", example)
+			unexpected = append(unexpected, example)
+		default:
+			fmt.Fprintf(out, "✓ %s", example)
+			if len(outputs) > 0 {
+				fmt.Fprintf(out, ": %d file(s)", len(outputs))
+			}
+			fmt.Fprintln(out)
+		}
+	})
+	if len(unexpected) > 0 {
+		return fmt.Errorf("suite %s: %d of %d example(s) did not turn out as expected: %s", s.Name, len(unexpected), len(s.Examples), strings.Join(unexpected, ", "))
+	}
+	fmt.Printf("✓ suite %s passed\n", s.Name)
+	return nil
+}
+
+// applyDeckVars sets decksh variables in a script: an assignment to one in
+// the script is replaced, otherwise it is defined before the first line.
+func applyDeckVars(script []byte, vars map[string]string) []byte {
+	lines := strings.SplitAfter(string(script), "\n")
+	var names []string
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var prefix strings.Builder
+	for _, name := range names {
+		assign := regexp.MustCompile(`^(\s*)` + regexp.QuoteMeta(name) + `\s*=`)
+		found := false
+		for i, line := range lines {
+			if m := assign.FindStringSubmatch(line); m != nil {
+				lines[i] = m[1] + name + "=" + vars[name] + "\n"
+				found = true
+			}
+		}
+		if !found {
+			fmt.Fprintf(&prefix, "%s=%s\n", name, vars[name])
+		}
+	}
+	return []byte(prefix.String() + strings.Join(lines, ""))
+}
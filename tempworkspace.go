@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Isolated temp workspaces for stdin, remote and API renders
+
+const (
+	maxDeckSourceBytes = 10 << 20 // largest .dsh accepted from stdin, URLs or the API
+	remoteFetchTimeout = 30 * time.Second
+)
+
+// renderWorkspace is a private directory for one render's inputs and outputs,
+// so concurrent renders never share files.
+type renderWorkspace struct {
+	dir  string
+	keep bool
+}
+
+func (cfg *config) newRenderWorkspace(kind string) (*renderWorkspace, error) {
+	dir, err := os.MkdirTemp("", "decktool-"+kind+"-")
+	if err != nil {
+		return nil, fmt.Errorf("create temp workspace: %w", err)
+	}
+	return &renderWorkspace{dir: dir, keep: cfg.keepTemp}, nil
+}
+
+// cleanup removes the workspace unless --keep-temp was given.
+func (ws *renderWorkspace) cleanup() {
+	if ws.keep {
+		fmt.Printf("Kept temp workspace %s\n", ws.dir)
+		return
+	}
+	os.RemoveAll(ws.dir)
+}
+
+// render writes source as name.dsh, lints and renders it, and returns the XML path.
+func (cfg *config) renderInWorkspace(ctx context.Context, ws *renderWorkspace, name string, source []byte) (string, error) {
+	if err := os.WriteFile(filepath.Join(ws.dir, name+".dsh"), source, 0o644); err != nil {
+		return "", err
+	}
+	if err := cfg.lintDeck(ctx, ws.dir, name+".dsh"); err != nil {
+		return "", fmt.Errorf("lint: %w", err)
+	}
+	xmlPath := cfg.getExampleXmlPath(ws.dir, name)
+	if err := cfg.renderDeck(ctx, ws.dir, name+".dsh", xmlPath); err != nil {
+		return "", fmt.Errorf("render: %w", err)
+	}
+	return xmlPath, nil
+}
+
+func isAdHocDeck(raw string) bool {
+	return raw == "-" || strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://")
+}
+
+// renderAdHoc renders a deck read from stdin ("-") or a URL in a temp
+// workspace and copies the XML into the output directory (run --out) or
+// the current directory.
+func (cfg *config) renderAdHoc(ctx context.Context, raw string) (string, error) {
+	name, kind := adHocName(raw), "stdin"
+	var source []byte
+	var err error
+	if raw == "-" {
+		source, err = readDeckSource(os.Stdin)
+	} else {
+		kind = "remote"
+		source, err = fetchDeckSource(ctx, raw)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", raw, err)
+	}
+
+	ws, err := cfg.newRenderWorkspace(kind)
+	if err != nil {
+		return "", err
+	}
+	defer ws.cleanup()
+
+	xmlPath, err := cfg.renderInWorkspace(ctx, ws, name, source)
+	if err != nil {
+		return "", err
+	}
+	out, err := filepath.Abs(cfg.outputPath(cfg.outDir, raw))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return "", err
+	}
+	return out, copyFile(xmlPath, out)
+}
+
+func fetchDeckSource(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return readDeckSource(resp.Body)
+}
+
+// readDeckSource reads a whole deck source, refusing one over the size
+// limit rather than rendering it cut short.
+func readDeckSource(r io.Reader) ([]byte, error) {
+	source, err := io.ReadAll(io.LimitReader(r, maxDeckSourceBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(source) > maxDeckSourceBytes {
+		return nil, fmt.Errorf("deck source exceeds %d MB", maxDeckSourceBytes>>20)
+	}
+	return source, nil
+}
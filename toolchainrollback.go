@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Rolling back to a kept toolchain set, and releasing the hold it places
+
+// rollbackToolchain restores a kept set into dist and holds it there, so
+// ensure does not reinstall the newer release. With no tag it picks the
+// newest set installed before the current one.
+func (cfg *config) rollbackToolchain(tag string) error {
+	sets, err := cfg.listToolchainSets()
+	if err != nil {
+		return err
+	}
+	state := cfg.loadToolchainState()
+	var target *toolchainSet
+	for i, set := range sets {
+		if tag == "" && set.Tag == state.Current {
+			if i+1 < len(sets) {
+				target = &sets[i+1]
+			}
+			break
+		}
+		if set.Tag == tag || (tag == "" && state.Current == "") {
+			target = &sets[i]
+			break
+		}
+	}
+	if target == nil {
+		if tag == "" {
+			return errors.New("no earlier toolchain kept (see decktool rollback --list)")
+		}
+		return fmt.Errorf("toolchain %s is not kept (see decktool rollback --list)", tag)
+	}
+	for _, filename := range target.Files {
+		dest := filepath.Join(cfg.distDir, filename)
+		if err := copyFile(filepath.Join(cfg.getToolchainSetDir(target.Tag), filename), dest); err != nil {
+			return fmt.Errorf("restore %s: %w", filename, err)
+		}
+		if err := os.Chmod(dest, 0o755); err != nil {
+			return err
+		}
+		// Date the binaries as installed, so once the hold is released
+		// ensure sees newer releases as newer
+		if err := os.Chtimes(dest, target.Installed, target.Installed); err != nil {
+			return err
+		}
+	}
+	if err := cfg.saveToolchainState(toolchainState{Current: target.Tag, Held: true}); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Rolled back to %s (%d binaries, installed %s)\n", target.Tag, len(target.Files), target.Installed.Format("2006-01-02 15:04"))
+	fmt.Println("ensure keeps this toolchain until: decktool rollback --release")
+	return nil
+}
+
+// releaseToolchainHold lets ensure install new releases again.
+func (cfg *config) releaseToolchainHold() error {
+	state := cfg.loadToolchainState()
+	if !state.Held {
+		fmt.Println("No toolchain hold to release")
+		return nil
+	}
+	state.Held = false
+	if err := cfg.saveToolchainState(state); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Released hold on %s; the next ensure installs the latest release\n", state.Current)
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Toolchain sets: the native binaries of the last few installed releases,
+// kept under .dist/toolchains so a release that breaks rendering can be
+// rolled back without re-downloading.
+//
+// Sets are copies, not links: dev build and downloads rewrite the binaries
+// in .dist in place. Rollback is in toolchainrollback.go.
+
+const defaultToolchainKeep = 3
+
+type toolchainSet struct {
+	Tag       string    `json:"tag"`
+	Installed time.Time `json:"installed"`
+	Files     []string  `json:"files"`
+}
+
+// toolchainState records the set in use and whether a rollback holds it.
+type toolchainState struct {
+	Current string `json:"current"`
+	Held    bool   `json:"held"` // ensure leaves the binaries alone until released
+}
+
+func (cfg *config) loadToolchainState() toolchainState {
+	var state toolchainState
+	if data, err := os.ReadFile(cfg.getToolchainStatePath()); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func (cfg *config) saveToolchainState(state toolchainState) error {
+	if err := os.MkdirAll(cfg.getToolchainsDir(), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.getToolchainStatePath(), append(data, '\n'), 0o644)
+}
+
+// toolchainKeep is how many sets to keep (DECKTOOL_TOOLCHAIN_KEEP).
+func toolchainKeep() int {
+	if n, err := strconv.Atoi(os.Getenv("DECKTOOL_TOOLCHAIN_KEEP")); err == nil && n > 0 {
+		return n
+	}
+	return defaultToolchainKeep
+}
+
+// recordToolchainSet copies the native binaries now in dist into the set
+// for tag, makes it current and prunes the oldest sets.
+func (cfg *config) recordToolchainSet(tag string) error {
+	dir := cfg.getToolchainSetDir(tag)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	set := toolchainSet{Tag: tag, Installed: time.Now().UTC()}
+	for _, spec := range cfg.toolchain {
+		filename := cfg.buildFilename(spec.name, targetNative)
+		src := filepath.Join(cfg.distDir, filename)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyFile(src, filepath.Join(dir, filename)); err != nil {
+			return err
+		}
+		if err := os.Chmod(filepath.Join(dir, filename), 0o755); err != nil {
+			return err
+		}
+		set.Files = append(set.Files, filename)
+	}
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "set.json"), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	if err := cfg.saveToolchainState(toolchainState{Current: tag}); err != nil {
+		return err
+	}
+	return cfg.pruneToolchainSets()
+}
+
+// listToolchainSets returns the kept sets, newest first.
+func (cfg *config) listToolchainSets() ([]toolchainSet, error) {
+	matches, err := filepath.Glob(filepath.Join(cfg.getToolchainsDir(), "*", "set.json"))
+	if err != nil {
+		return nil, err
+	}
+	var sets []toolchainSet
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var set toolchainSet
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Installed.After(sets[j].Installed) })
+	return sets, nil
+}
+
+func (cfg *config) pruneToolchainSets() error {
+	sets, err := cfg.listToolchainSets()
+	if err != nil {
+		return err
+	}
+	current := cfg.loadToolchainState().Current
+	kept := 0
+	for _, set := range sets {
+		if set.Tag == current || kept < toolchainKeep() {
+			kept++
+			continue
+		}
+		if err := os.RemoveAll(cfg.getToolchainSetDir(set.Tag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
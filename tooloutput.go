@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+type toolOutputKey struct{}
+
+// withToolOutput sends the output of child tools (lint, render, convert) to w.
+func withToolOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, toolOutputKey{}, w)
+}
+
+// toolOutput returns where child tools write: a job log, or the terminal.
+func toolOutput(ctx context.Context) (stdout, stderr io.Writer) {
+	if w, ok := ctx.Value(toolOutputKey{}).(io.Writer); ok {
+		return w, w
+	}
+	return os.Stdout, os.Stderr
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenTelemetry tracing: spans exported as OTLP/HTTP JSON when
+// OTEL_EXPORTER_OTLP_ENDPOINT (or ..._TRACES_ENDPOINT) is set.
+
+type span struct {
+	traceID, spanID, parentID string
+	name                      string
+	start                     time.Time
+	attrs                     []string // key, value pairs
+}
+
+type finishedSpan struct {
+	span
+	end time.Time
+	err error
+}
+
+type spanKey struct{}
+
+var tracer = struct {
+	mu       sync.Mutex
+	endpoint string
+	spans    []finishedSpan
+}{endpoint: otlpTracesEndpoint()}
+
+func otlpTracesEndpoint() string {
+	if ep := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); ep != "" {
+		return ep
+	}
+	if ep := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); ep != "" {
+		return strings.TrimSuffix(ep, "/") + "/v1/traces"
+	}
+	return ""
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// startSpan starts a child of the span in ctx (or a new trace). Spans are
+// cheap no-ops when no exporter is configured.
+func startSpan(ctx context.Context, name string, attrs ...string) (context.Context, *span) {
+	if tracer.endpoint == "" {
+		return ctx, nil
+	}
+	s := &span{spanID: randomHex(8), name: name, start: time.Now(), attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		s.traceID = randomHex(16)
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// end records the span; a non-nil err marks it failed.
+func (s *span) end(err error) {
+	if s == nil {
+		return
+	}
+	tracer.mu.Lock()
+	tracer.spans = append(tracer.spans, finishedSpan{span: *s, end: time.Now(), err: err})
+	tracer.mu.Unlock()
+}
+
+type otlpAttr struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+func toOTLPAttrs(kv []string) []otlpAttr {
+	var attrs []otlpAttr
+	for i := 0; i+1 < len(kv); i += 2 {
+		attrs = append(attrs, otlpAttr{Key: kv[i], Value: map[string]string{"stringValue": kv[i+1]}})
+	}
+	return attrs
+}
+
+// flushSpans sends finished spans to the OTLP endpoint.
+func flushSpans(ctx context.Context) error {
+	tracer.mu.Lock()
+	spans := tracer.spans
+	tracer.spans = nil
+	tracer.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	var out []map[string]any
+	for _, s := range spans {
+		status := map[string]any{"code": 1}
+		if s.err != nil {
+			status = map[string]any{"code": 2, "message": s.err.Error()}
+		}
+		out = append(out, map[string]any{
+			"traceId":           s.traceID,
+			"spanId":            s.spanID,
+			"parentSpanId":      s.parentID,
+			"name":              s.name,
+			"kind":              1,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+			"attributes":        toOTLPAttrs(s.attrs),
+			"status":            status,
+		})
+	}
+	body, err := json.Marshal(map[string]any{"resourceSpans": []any{map[string]any{
+		"resource":   map[string]any{"attributes": toOTLPAttrs([]string{"service.name", getenvDefault("OTEL_SERVICE_NAME", "decktool")})},
+		"scopeSpans": []any{map[string]any{"scope": map[string]string{"name": "decktool"}, "spans": out}},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tracer.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, kv := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("OTLP export: HTTP %s", resp.Status)
+	}
+	return nil
+}
+
+// exportSpansPeriodically flushes spans for long-running commands (serve).
+func exportSpansPeriodically(ctx context.Context, interval time.Duration) {
+	if tracer.endpoint == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := flushSpans(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
+	}
+}
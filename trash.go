@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trash: destructive commands (dev clean, cache clean) move directories
+// aside instead of deleting them, so a wiped checkout with unpushed work can
+// be brought back with decktool restore. Each trash holds one directory per
+// removal:
+//
+//	<trash>/<id>/trash.json   original path, when and by what it was removed
+//	<trash>/<id>/content      the removed directory, moved there by rename
+//
+// The project trash is .trash; shared cache entries go to DECKTOOL_CACHE's
+// own trash/ so the move stays on one filesystem. Entries older than the
+// retention period are purged whenever something new is trashed.
+
+const defaultTrashDays = 7
+
+type trashEntry struct {
+	ID      string    `json:"id"`
+	Path    string    `json:"path"`    // where the directory lived
+	Trashed time.Time `json:"trashed"` // when it was moved here
+	Command string    `json:"command"` // what removed it
+
+	dir string // the entry's directory in its trash
+}
+
+// trashDays is how long trashed directories are kept (DECKTOOL_TRASH_DAYS).
+func trashDays() int {
+	if n, err := strconv.Atoi(os.Getenv("DECKTOOL_TRASH_DAYS")); err == nil && n >= 0 {
+		return n
+	}
+	return defaultTrashDays
+}
+
+// trashRoots lists the trashes restore looks in.
+func (cfg *config) trashRoots() []string {
+	roots := []string{cfg.trashDir}
+	if cfg.cacheDir != "" {
+		roots = append(roots, filepath.Join(cfg.cacheDir, "trash"))
+	}
+	return roots
+}
+
+// moveToTrash moves path into the trash at root, recording command as the
+// reason. Expired entries are purged first.
+func moveToTrash(root, path, command string) (trashEntry, error) {
+	if err := purgeTrash(root, time.Duration(trashDays())*24*time.Hour); err != nil {
+		fmt.Printf("⚠ purge trash: %v\n", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return trashEntry{}, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return trashEntry{}, err
+	}
+	entry := trashEntry{Path: abs, Trashed: time.Now().UTC(), Command: command}
+	base := entry.Trashed.Local().Format("20060102-150405") + "-" + strings.TrimLeft(filepath.Base(abs), ".")
+	for n := 1; ; n++ {
+		entry.ID = base
+		if n > 1 {
+			entry.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+		entry.dir = filepath.Join(root, entry.ID)
+		if err := os.Mkdir(entry.dir, 0o755); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrExist) {
+			return trashEntry{}, err
+		}
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(entry.dir, "trash.json"), append(data, '\n'), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(abs, filepath.Join(entry.dir, "content"))
+	}
+	if err != nil {
+		os.RemoveAll(entry.dir)
+		return trashEntry{}, fmt.Errorf("move %s to trash: %w", abs, err)
+	}
+	return entry, nil
+}
+
+// listTrash returns the entries of every trash root, newest first.
+func listTrash(roots []string) ([]trashEntry, error) {
+	var entries []trashEntry
+	for _, root := range roots {
+		dirs, err := os.ReadDir(root)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirs {
+			data, err := os.ReadFile(filepath.Join(root, d.Name(), "trash.json"))
+			if err != nil {
+				continue
+			}
+			var entry trashEntry
+			if json.Unmarshal(data, &entry) != nil {
+				continue
+			}
+			entry.ID, entry.dir = d.Name(), filepath.Join(root, d.Name())
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Trashed.After(entries[j].Trashed) })
+	return entries, nil
+}
+
+// purgeTrash permanently deletes entries trashed more than keep ago.
+func purgeTrash(root string, keep time.Duration) error {
+	entries, err := listTrash([]string{root})
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if time.Since(entry.Trashed) > keep {
+			if err := os.RemoveAll(entry.dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restore moves a trashed directory back to where it was. It refuses to
+// overwrite anything created there since.
+func (e trashEntry) restore() error {
+	if _, err := os.Lstat(e.Path); err == nil {
+		return fmt.Errorf("%s exists; move it aside first", e.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(e.dir, "content"), e.Path); err != nil {
+		return err
+	}
+	return os.RemoveAll(e.dir)
+}
+
+// size returns the bytes held by the entry.
+func (e trashEntry) size() int64 {
+	var total int64
+	filepath.WalkDir(e.dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
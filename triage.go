@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Failure triage: after a test run with corpus failures, step through them
+// at the terminal, each with its error, the tail of the tool output and the
+// .dsh lines the output points at, and act on it: retry, open the deck in
+// an editor, record it as a known failure in the baseline, or write a report
+// (and open a prefilled upstream issue with gh). Reading the tool output is in
+// triageoutput.go, reports in triagereport.go.
+
+const triageOutputLines = 12
+
+type triageItem struct {
+	example string
+	err     error
+	output  string // what lint and render printed
+}
+
+// interactiveTerminal reports whether a person is at the terminal to
+// answer prompts: stdin and stdout are terminals and this is not CI.
+func interactiveTerminal() bool {
+	if os.Getenv("CI") != "" {
+		return false
+	}
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+var stdinLines = bufio.NewScanner(os.Stdin)
+
+// ask prints prompt and reads a lower-cased answer; end of input quits.
+func ask(prompt string) string {
+	fmt.Print(prompt)
+	if !stdinLines.Scan() {
+		return "q"
+	}
+	return strings.ToLower(strings.TrimSpace(stdinLines.Text()))
+}
+
+// confirm asks a yes/no question that defaults to no.
+func confirm(prompt string) bool {
+	answer := ask(prompt)
+	return answer == "y" || answer == "yes"
+}
+
+// offerTriage asks whether to step through the corpus failures of a run.
+func (cfg *config) offerTriage(ctx context.Context, items []triageItem, lock *lockFile) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Println()
+	if !confirm(fmt.Sprintf("Triage the %d corpus failure(s) now? [y/N] ", len(items))) {
+		return
+	}
+	if err := cfg.triageFailures(ctx, items, lock); err != nil {
+		fmt.Printf("⚠ Triage: %v\n", err)
+	}
+}
+
+// triageFailures steps through the failures until each is dealt with or
+// the user quits.
+func (cfg *config) triageFailures(ctx context.Context, items []triageItem, lock *lockFile) error {
+	resolved := 0
+	for i := range items {
+		item := &items[i]
+		source, name := cfg.parseExample(item.example)
+		dir, err := cfg.getExampleDir(source, name)
+		if err != nil {
+			return err
+		}
+		script := filepath.Join(dir, name+".dsh")
+	prompt:
+		for {
+			line := cfg.showFailure(i+1, len(items), item, script)
+			switch ask("[r]etry  [e]dit  e[x]pected-fail  [f]ile report  [n]ext  [q]uit: ") {
+			case "r", "retry":
+				if item.retry(ctx, cfg) {
+					fmt.Printf("✓ %s passes now\n", item.example)
+					resolved++
+					break prompt
+				}
+			case "e", "edit":
+				if err := openEditor(ctx, script, line); err != nil {
+					fmt.Printf("✗ editor: %v\n", err)
+				}
+			case "x", "expected-fail":
+				if !slices.Contains(lock.Baseline.Failing, item.example) {
+					lock.Baseline.Failing = append(lock.Baseline.Failing, item.example)
+				}
+				if err := lock.save(); err != nil {
+					return err
+				}
+				fmt.Printf("⊘ %s recorded as a known failure in %s\n", item.example, lockFileName)
+				resolved++
+				break prompt
+			case "f", "file", "report":
+				path, err := cfg.writeTriageReport(item, script, line)
+				if err != nil {
+					fmt.Printf("✗ report: %v\n", err)
+					continue
+				}
+				fmt.Printf("✓ Report written to %s\n", path)
+				if _, err := exec.LookPath("gh"); err == nil {
+					if confirm("Open a prefilled issue on decksh's upstream in the browser with gh? [y/N] ") {
+						if err := cfg.openIssue(ctx, item, path); err != nil {
+							fmt.Printf("✗ gh: %v\n", err)
+						}
+					}
+				}
+			case "n", "next", "":
+				break prompt
+			case "q", "quit":
+				fmt.Printf("Triage stopped: %d of %d failure(s) dealt with\n", resolved, len(items))
+				return nil
+			}
+		}
+	}
+	fmt.Printf("Triage done: %d of %d failure(s) fixed or marked expected\n", resolved, len(items))
+	return nil
+}
+
+// retry renders the example again, capturing its output; it reports
+// whether it passed lint, render and the property checks.
+func (item *triageItem) retry(ctx context.Context, cfg *config) bool {
+	var buf bytes.Buffer
+	xmlPath, err := cfg.renderExample(captureToolOutput(ctx, &buf), item.example)
+	if err == nil {
+		err = cfg.propertyError(item.example, xmlPath)
+	}
+	item.err, item.output = err, buf.String()
+	return err == nil
+}
+
+// openEditor opens script in $VISUAL or $EDITOR (default vi), at line for
+// editors known to take +<line>.
+func openEditor(ctx context.Context, script string, line int) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = getenvDefault("EDITOR", "vi")
+	}
+	args := strings.Fields(editor)
+	switch filepath.Base(args[0]) {
+	case "vi", "vim", "nvim", "nano", "emacs", "micro", "kak":
+		if line > 0 {
+			args = append(args, "+"+strconv.Itoa(line))
+		}
+	}
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], script)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
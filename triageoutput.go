@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reading failed tool output for triage: the .dsh line it points at and its tail
+
+// captureToolOutput sends tool output to the terminal and to buf.
+func captureToolOutput(ctx context.Context, buf *bytes.Buffer) context.Context {
+	stdout, _ := toolOutput(ctx)
+	return withToolOutput(ctx, io.MultiWriter(stdout, buf))
+}
+
+var dshLocationRE = regexp.MustCompile(`([\w.-]+\.dsh):(\d+)|[Ll]ine (\d+)`)
+
+// failureLine finds the line of script the tool output points at, 0 if it
+// names none.
+func failureLine(output, script string) int {
+	for _, m := range dshLocationRE.FindAllStringSubmatch(output, -1) {
+		if m[1] != "" && m[1] != script {
+			continue
+		}
+		if n, err := strconv.Atoi(m[2] + m[3]); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func outputTail(output string, n int) []string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// showFailure prints a failure and returns the script line it points at.
+func (cfg *config) showFailure(n, total int, item *triageItem, script string) int {
+	fmt.Printf("\n=== Failure %d/%d: %s ===\n%v\n", n, total, item.example, item.err)
+	if strings.TrimSpace(item.output) != "" {
+		fmt.Println("--- tool output ---")
+		for _, l := range outputTail(item.output, triageOutputLines) {
+			fmt.Printf("  %s\n", l)
+		}
+	}
+	line := failureLine(item.err.Error()+"\n"+item.output, filepath.Base(script))
+	if line == 0 {
+		return 0
+	}
+	if data, err := os.ReadFile(script); err == nil {
+		fmt.Printf("--- %s:%d ---\n", filepath.Base(script), line)
+		printSourceContext(os.Stdout, data, line, 3)
+	}
+	return line
+}
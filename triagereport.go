@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Triage reports: a Markdown write-up of a failure and the prefilled upstream issue
+
+// writeTriageReport writes a Markdown report of a failure for filing.
+func (cfg *config) writeTriageReport(item *triageItem, script string, line int) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s fails to render\n\n", item.example)
+	fmt.Fprintf(&b, "- toolchain: %s\n- date: %s\n\n", cfg.toolchainID(), time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "## Error\n\n```\n%v\n```\n\n", item.err)
+	if out := strings.TrimSpace(item.output); out != "" {
+		fmt.Fprintf(&b, "## Tool output\n\n```\n%s\n```\n\n", strings.Join(outputTail(out, 40), "\n"))
+	}
+	if data, err := os.ReadFile(script); err == nil {
+		fmt.Fprintf(&b, "## %s\n\n```\n", filepath.Base(script))
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		from, to := 1, len(lines)
+		if line > 0 {
+			from, to = max(line-10, 1), min(line+10, len(lines))
+		}
+		for i := from; i <= to; i++ {
+			fmt.Fprintf(&b, "%4d  %s\n", i, lines[i-1])
+		}
+		b.WriteString("```\n")
+	}
+	path := cfg.getTriageReportPath(item.example)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// openIssue opens gh's browser form for a new decksh issue, prefilled with
+// the report; nothing is filed until it is submitted there.
+func (cfg *config) openIssue(ctx context.Context, item *triageItem, report string) error {
+	owner, repo, ok := githubRepo(cfg.repos["decksh"].upstream)
+	if !ok {
+		return fmt.Errorf("decksh upstream %s is not on GitHub", cfg.repos["decksh"].upstream)
+	}
+	cmd := exec.CommandContext(ctx, "gh", "issue", "create", "--repo", owner+"/"+repo,
+		"--title", item.example+" fails to render", "--body-file", report, "--web")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Trust store: the release signer pinned per repository on first use
+// (~/.decktool/trust.json), and the checks of a download against it
+
+type trustedSigner struct {
+	Fingerprint string    `json:"fingerprint"`
+	PublicKey   string    `json:"public_key"`
+	FirstTag    string    `json:"first_tag"`
+	FirstSeen   time.Time `json:"first_seen"`
+}
+
+// releaseRepo names the repository gh downloads releases from.
+func releaseRepo(ctx context.Context) string {
+	out, err := exec.CommandContext(ctx, "gh", "repo", "view", "--json", "nameWithOwner", "-q", ".nameWithOwner").Output()
+	if err != nil || strings.TrimSpace(string(out)) == "" {
+		return "default"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func loadTrustStore(path string) (map[string]trustedSigner, error) {
+	store := make(map[string]trustedSigner)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return store, nil
+}
+
+func saveTrustStore(path string, store map[string]trustedSigner) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// refuseUnpinnable returns an error when the release repository has a
+// pinned signer: after that, a release without a signature or provenance is
+// what a takeover would publish. Repos never pinned only get a warning.
+func refuseUnpinnable(ctx context.Context, tag, missing string) error {
+	stdout, _ := toolOutput(ctx)
+	path, err := decktoolHomePath("trust.json")
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(path)
+	if err != nil {
+		return err
+	}
+	repo := releaseRepo(ctx)
+	pinned, ok := store[repo]
+	if !ok {
+		fmt.Fprintf(stdout, "⚠ %s has no %s; its signer cannot be pinned\n", tag, missing)
+		return nil
+	}
+	return fmt.Errorf("%s has no %s, but %s releases are signed by pinned key %s; nothing was installed (remove %q from %s if signing really stopped)",
+		tag, missing, repo, pinned.Fingerprint, repo, path)
+}
+
+// checkReleaseSigner verifies the provenance signature downloaded into dir
+// and compares the signer with the one pinned for this repository.
+func (cfg *config) checkReleaseSigner(ctx context.Context, tag, dir string) error {
+	stdout, _ := toolOutput(ctx)
+	pub, err := verifyProvenanceSignature(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return refuseUnpinnable(ctx, tag, "signature")
+	}
+	if err != nil {
+		return err
+	}
+
+	path, err := decktoolHomePath("trust.json")
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(path)
+	if err != nil {
+		return err
+	}
+	repo := releaseRepo(ctx)
+	fingerprint := keyFingerprint(pub)
+	pinned, ok := store[repo]
+	switch {
+	case ok && pinned.Fingerprint == fingerprint:
+		fmt.Fprintf(stdout, "✓ %s signed by pinned key %s\n", tag, fingerprint)
+		return nil
+	case ok && !cfg.acceptNewSigner:
+		fmt.Fprintf(os.Stderr, `
+████████████████████████████████████████████████████████████████████████
+  WARNING: RELEASE SIGNING KEY CHANGED for %s
+  pinned:  %s (first seen %s in %s)
+  now:     %s (%s)
+  The repository may have been taken over. Nothing was installed.
+  If the maintainers announced a key rotation, re-run with
+  --accept-new-signer to pin the new key.
+████████████████████████████████████████████████████████████████████████
+
+`, repo, pinned.Fingerprint, pinned.FirstSeen.Format(time.DateOnly), pinned.FirstTag, fingerprint, tag)
+		return fmt.Errorf("%s is signed by an untrusted key %s", tag, fingerprint)
+	case ok:
+		fmt.Fprintf(stdout, "⚠ Replacing pinned key %s for %s with %s\n", pinned.Fingerprint, repo, fingerprint)
+	default:
+		fmt.Fprintf(stdout, "✓ Pinned %s signing key %s on first use\n", repo, fingerprint)
+	}
+	store[repo] = trustedSigner{
+		Fingerprint: fingerprint,
+		PublicKey:   base64.StdEncoding.EncodeToString(pub),
+		FirstTag:    tag,
+		FirstSeen:   time.Now().UTC(),
+	}
+	return saveTrustStore(path, store)
+}
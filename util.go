@@ -2,8 +2,8 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -27,34 +27,6 @@ func getenvInt(key string, fallback int) int {
 	return fallback
 }
 
-func resolveGoBin(goCmd string) (string, error) {
-	if bin := strings.TrimSpace(runGoEnv(goCmd, "GOBIN")); bin != "" {
-		return absPath(bin)
-	}
-	gopath := strings.TrimSpace(runGoEnv(goCmd, "GOPATH"))
-	if gopath == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", errors.New("unable to determine GOBIN; set GOBIN or GOPATH")
-		}
-		return filepath.Join(home, "go", "bin"), nil
-	}
-	first := strings.Split(gopath, string(os.PathListSeparator))[0]
-	if first == "" {
-		return "", errors.New("GOPATH is empty; set GOBIN explicitly")
-	}
-	return filepath.Join(first, "bin"), nil
-}
-
-func runGoEnv(goCmd, key string) string {
-	cmd := exec.Command(goCmd, "env", key)
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return string(out)
-}
-
 func absPath(path string) (string, error) {
 	if path == "" {
 		return "", errors.New("path is empty")
@@ -73,8 +45,41 @@ func expandPath(path string) (string, error) {
 	return absPath(path)
 }
 
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseSize parses sizes such as "25MB", "512KB" or "1048576" (1024-based units).
+func parseSize(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	return int64(n * float64(mult)), nil
+}
+
 func (cfg *config) parseExample(raw string) (source, name string) {
-	raw = strings.TrimSpace(raw)
+	raw = cfg.resolveAlias(strings.TrimSpace(raw))
 	if raw == "" {
 		return "deckviz", ""
 	}
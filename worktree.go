@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Worktrees: extra checkouts of upstream code repos at arbitrary refs
+//
+// Each ref of a code repo is checked out as a git worktree of the primary
+// clone under .src/.worktrees/<repo>/<ref>, so several refs of decksh or
+// deck can be built side by side (compare, bisect, PR testing) while the
+// primary checkout stays on its branch and objects are fetched only once.
+
+// parseRepoRef splits "decksh@v1.2.3" into a code repo and a ref.
+func (cfg *config) parseRepoRef(arg string) (*repoConfig, string, error) {
+	name, ref, ok := strings.Cut(arg, "@")
+	if !ok || ref == "" {
+		return nil, "", fmt.Errorf("%q: want <repo>@<ref>, e.g. decksh@pull/42/head", arg)
+	}
+	repo, ok := cfg.repos[name]
+	if !ok || repo.isData {
+		return nil, "", fmt.Errorf("%q is not a code repo (%s)", name, strings.Join(cfg.codeRepoNames(), ", "))
+	}
+	return repo, ref, nil
+}
+
+func (cfg *config) codeRepoNames() []string {
+	var names []string
+	for name, repo := range cfg.repos {
+		if !repo.isData {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func worktreeRoot() string {
+	return filepath.Join(srcDir, ".worktrees")
+}
+
+func refDirName(ref string) string {
+	return strings.NewReplacer("/", "-", ":", "-", "\\", "-").Replace(ref)
+}
+
+func getWorktreeDir(repo *repoConfig, ref string) (string, error) {
+	return absPath(filepath.Join(worktreeRoot(), repo.name, refDirName(ref)))
+}
+
+// ensureWorktree fetches ref into the primary clone and checks it out,
+// detached, in the ref's worktree. It returns the worktree and commit.
+func (cfg *config) ensureWorktree(ctx context.Context, repo *repoConfig, ref string) (string, string, error) {
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+		if err := cfg.gitClone(ctx, repo); err != nil {
+			return "", "", err
+		}
+	}
+	args := []string{"-C", repo.dir, "fetch"}
+	if repo.depth > 0 && !cfg.usesMirror(repo) {
+		args = append(args, fmt.Sprintf("--depth=%d", repo.depth))
+	}
+	args = append(args, "origin", ref)
+	if err := cfg.runGit(ctx, args...); err != nil {
+		return "", "", fmt.Errorf("fetch %s@%s: %w", repo.name, ref, err)
+	}
+	sha, err := cfg.gitOutput(ctx, "-C", repo.dir, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", "", err
+	}
+
+	dir, err := getWorktreeDir(repo, ref)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		err = cfg.runGit(ctx, "-C", dir, "checkout", "--quiet", "--force", "--detach", sha)
+	} else {
+		err = cfg.runGit(ctx, "-C", repo.dir, "worktree", "add", "--force", "--detach", dir, sha)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return dir, sha, nil
+}
+
+type worktreeInfo struct {
+	repo, dir, sha string
+}
+
+// listWorktrees returns the ref worktrees of every code repo.
+func (cfg *config) listWorktrees(ctx context.Context) ([]worktreeInfo, error) {
+	root, err := absPath(worktreeRoot())
+	if err != nil {
+		return nil, err
+	}
+	var out []worktreeInfo
+	for _, name := range cfg.codeRepoNames() {
+		repo := cfg.repos[name]
+		if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+			continue
+		}
+		list, err := cfg.gitOutput(ctx, "-C", repo.dir, "worktree", "list", "--porcelain")
+		if err != nil {
+			return nil, err
+		}
+		var cur worktreeInfo
+		scanner := bufio.NewScanner(strings.NewReader(list + "\n\n"))
+		for scanner.Scan() {
+			key, value, _ := strings.Cut(scanner.Text(), " ")
+			switch key {
+			case "worktree":
+				cur = worktreeInfo{repo: name, dir: value}
+			case "HEAD":
+				cur.sha = value
+			case "":
+				if strings.HasPrefix(cur.dir, root+string(filepath.Separator)) {
+					out = append(out, cur)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func (cfg *config) removeWorktree(ctx context.Context, repo *repoConfig, ref string) error {
+	dir, err := getWorktreeDir(repo, ref)
+	if err != nil {
+		return err
+	}
+	if err := cfg.runGit(ctx, "-C", repo.dir, "worktree", "remove", "--force", dir); err != nil {
+		return err
+	}
+	return cfg.runGit(ctx, "-C", repo.dir, "worktree", "prune")
+}
+
+// refWorkspace writes a go.work that uses the worktree in place of repo's
+// primary checkout, for builds selected with GOWORK.
+func (cfg *config) refWorkspace(repo *repoConfig, ref, worktree string) (string, error) {
+	project, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	content := fmt.Sprintf("go 1.25\n\nuse %q\n", project)
+	for _, name := range cfg.codeRepoNames() {
+		dir := cfg.repos[name].dir
+		if name == repo.name {
+			dir = worktree
+		}
+		content += fmt.Sprintf("use %q\n", dir)
+	}
+	path := worktree + ".go.work"
+	return path, os.WriteFile(path, []byte(content), 0o644)
+}
+
+// buildRef builds the native tools against repo@ref into their own dist
+// directory.
+func (cfg *config) buildRef(ctx context.Context, arg string) error {
+	repo, ref, err := cfg.parseRepoRef(arg)
+	if err != nil {
+		return err
+	}
+	dir, sha, err := cfg.ensureWorktree(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+	if cfg.goWork, err = cfg.refWorkspace(repo, ref, dir); err != nil {
+		return err
+	}
+	outputDir := cfg.getRefDistDir(repo.name, ref)
+	fmt.Printf("Building native tools with %s@%s (%s) into %s\n", repo.name, ref, shortSHA(sha), outputDir)
+	results, err := cfg.buildAll(ctx, []buildTarget{targetNative}, outputDir)
+	if err != nil {
+		return err
+	}
+	if printBuildResults(results) > 0 {
+		return fmt.Errorf("some builds failed")
+	}
+	return nil
+}